@@ -1,6 +1,7 @@
 package contract
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -13,6 +14,8 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/cli/input"
 	"github.com/DigitalLabs-web3/neo-go-evm/cli/options"
 	"github.com/DigitalLabs-web3/neo-go-evm/cli/wallet"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/response/result"
 	corew "github.com/DigitalLabs-web3/neo-go-evm/pkg/wallet"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -29,7 +32,7 @@ func NewCommands() []cli.Command {
 			Subcommands: []cli.Command{
 				{
 					Name:   "call",
-					Usage:  "call [contractAddress] [abiFilePath] [method] [inputs...]",
+					Usage:  "call [contractAddress] [abiFilePath] [method] [inputs...], abiFilePath can be omitted if contract ABI is registered on chain",
 					Action: call,
 					Flags: append(options.RPC, []cli.Flag{
 						wallet.WalletPathFlag,
@@ -45,36 +48,73 @@ func NewCommands() []cli.Command {
 						wallet.FromAddrFlag,
 					}...),
 				},
+				{
+					Name:   "setmetadata",
+					Usage:  "setmetadata [contractAddress] [abiFilePath] [name] [sourceHash]",
+					Action: setMetadata,
+					Flags: append(options.RPC, []cli.Flag{
+						wallet.WalletPathFlag,
+						wallet.FromAddrFlag,
+					}...),
+				},
+				{
+					Name:   "find",
+					Usage:  "find [namePrefix]",
+					Action: findContracts,
+					Flags:  options.RPC,
+				},
 			},
 		},
 	}
 }
 
 func call(ctx *cli.Context) error {
-	if len(ctx.Args()) < 3 {
+	if len(ctx.Args()) < 2 {
 		return cli.NewExitError("parameters not enough", 1)
 	}
 	address := common.HexToAddress(ctx.Args()[0])
 	if address == (common.Address{}) {
 		return cli.NewExitError("invalid contract address", 1)
 	}
-	abiFile := ctx.Args()[1]
-	file, err := os.Open(abiFile)
-	if err != nil {
-		return err
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, cerr := options.GetRPCClient(gctx, ctx)
+	if cerr != nil {
+		return cli.NewExitError(cerr, 1)
+	}
+	var (
+		err         error
+		contractAbi abi.ABI
+		args        = ctx.Args()[1:]
+	)
+	if _, err := os.Stat(args[0]); err == nil {
+		contractAbi, err = readABI(args[0])
+		if err != nil {
+			return err
+		}
+		args = args[1:]
+	} else {
+		cs, err := c.GetContractStateWithMetadata(address.String())
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't get contract state: %w", err), 1)
+		}
+		if cs.Metadata == nil || cs.Metadata.ABI == "" {
+			return cli.NewExitError("contract ABI is not registered on chain, please provide ABI file", 1)
+		}
+		contractAbi, err = abi.JSON(strings.NewReader(cs.Metadata.ABI))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid contract ABI on chain: %w", err), 1)
+		}
 	}
-	defer file.Close()
-	contractAbi, err := abi.JSON(file)
-	if err != nil {
-		return err
+	if len(args) < 1 {
+		return cli.NewExitError("parameters not enough", 1)
 	}
-	method := ctx.Args()[2]
+	method := args[0]
 	var inputs []interface{}
-	if len(ctx.Args()) > 3 {
-		inputs = make([]interface{}, len(ctx.Args())-3)
-		for i := 3; i < len(ctx.Args()); i++ {
-			pstr := ctx.Args()[i]
-			inputs[i-3], err = parseParam(pstr)
+	if len(args) > 1 {
+		inputs = make([]interface{}, len(args)-1)
+		for i := 1; i < len(args); i++ {
+			inputs[i-1], err = parseParam(args[i])
 			if err != nil {
 				return err
 			}
@@ -88,12 +128,6 @@ func call(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	gctx, cancel := options.GetTimeoutContext(ctx)
-	defer cancel()
-	c, err := options.GetRPCClient(gctx, ctx)
-	if err != nil {
-		return cli.NewExitError(err, 1)
-	}
 	code, err := c.Eth_GetCode(address)
 	if err != nil {
 		return err
@@ -175,6 +209,88 @@ func deploy(ctx *cli.Context) error {
 	return wallet.MakeEthTx(ctx, facc, nil, big.NewInt(0), data)
 }
 
+func setMetadata(ctx *cli.Context) error {
+	if len(ctx.Args()) < 3 {
+		return cli.NewExitError("parameters not enough", 1)
+	}
+	address := common.HexToAddress(ctx.Args()[0])
+	if address == (common.Address{}) {
+		return cli.NewExitError("invalid contract address", 1)
+	}
+	abiJSON, err := os.ReadFile(ctx.Args()[1])
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't read ABI: %w", err), 1)
+	}
+	if _, err := abi.JSON(bytes.NewReader(abiJSON)); err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid ABI: %w", err), 1)
+	}
+	name := ctx.Args()[2]
+	var sourceHash []byte
+	if len(ctx.Args()) > 3 {
+		sourceHash, err = hexutil.Decode(ctx.Args()[3])
+		if err != nil || len(sourceHash) != common.HashLength {
+			return cli.NewExitError("invalid source hash", 1)
+		}
+	}
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, cerr := options.GetRPCClient(gctx, ctx)
+	if cerr != nil {
+		return cli.NewExitError(cerr, 1)
+	}
+	natives, err := c.GetNativeContracts()
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("could not get native contracts: %w", err), 1)
+	}
+	var management *state.NativeContract
+	for i := range natives {
+		if natives[i].Name == nativenames.Management {
+			management = &natives[i]
+		}
+	}
+	if management == nil {
+		return cli.NewExitError("can't find Management contract", 1)
+	}
+	data, err := management.Abi.Pack("setMetadata", address, name, string(abiJSON), sourceHash)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't pack parameters: %w", err), 1)
+	}
+	facc, err := handleWalletAndFrom(ctx)
+	if err != nil {
+		return err
+	}
+	return wallet.MakeEthTx(ctx, facc, &management.Address, big.NewInt(0), data)
+}
+
+func findContracts(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return cli.NewExitError("please input name", 1)
+	}
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	css, er := c.FindContracts(ctx.Args().First())
+	if er != nil {
+		return cli.NewExitError(fmt.Errorf("failed to find contracts: %w", er), 1)
+	}
+	for _, cs := range css {
+		fmt.Fprintf(ctx.App.Writer, "%s %s\n", cs.Address, cs.Metadata.Name)
+	}
+	return nil
+}
+
+func readABI(path string) (abi.ABI, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	defer file.Close()
+	return abi.JSON(file)
+}
+
 func parseParam(pstr string) (interface{}, error) {
 	if strings.HasPrefix(pstr, "0x") {
 		str := pstr[2:]
@@ -0,0 +1,101 @@
+package native
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/cli/options"
+	"github.com/DigitalLabs-web3/neo-go-evm/cli/wallet"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/urfave/cli"
+)
+
+func newDesignateCommands() []cli.Command {
+	flags := append(options.RPC, wallet.WalletPathFlag)
+	return []cli.Command{
+		{
+			Name:      "designate",
+			Usage:     "designate nodes as role",
+			ArgsUsage: "<role> <public key> [<public key>...]",
+			Action:    designateAsRole,
+			Flags:     flags,
+		},
+		{
+			Name:      "get",
+			Usage:     "get nodes designated as role",
+			ArgsUsage: "<role> [<index>]",
+			Action:    getDesignatedByRole,
+			Flags:     options.RPC,
+		},
+	}
+}
+
+func designateAsRole(ctx *cli.Context) error {
+	if len(ctx.Args()) < 2 {
+		return cli.NewExitError(fmt.Errorf("please input role and public keys"), 1)
+	}
+	role, err := parseRole(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	pks, err := keys.NewPublicKeysFromStrings(ctx.Args().Tail())
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid public keys: %w", err), 1)
+	}
+	return callNative(ctx, nativenames.Designation, "designateAsRole", uint8(role), pks.Bytes())
+}
+
+func getDesignatedByRole(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return cli.NewExitError(fmt.Errorf("please input role"), 1)
+	}
+	role, err := parseRole(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	var index uint32
+	if len(ctx.Args()) > 1 {
+		i, err := strconv.ParseUint(ctx.Args().Get(1), 10, 32)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid index %s", ctx.Args().Get(1)), 1)
+		}
+		index = uint32(i)
+	} else {
+		count, err := c.GetBlockCount()
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed get block count: %w", err), 1)
+		}
+		index = count
+	}
+	pks, err := c.GetDesignatedByRole(role, index)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed get designated nodes: %w", err), 1)
+	}
+	for _, pk := range pks {
+		fmt.Fprintln(ctx.App.Writer, hex.EncodeToString(pk.Bytes()))
+	}
+	return nil
+}
+
+func parseRole(s string) (noderoles.Role, error) {
+	for _, r := range noderoles.Roles {
+		if strings.EqualFold(s, r.String()) {
+			return r, nil
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || !noderoles.IsValid(noderoles.Role(n)) {
+		return 0, cli.NewExitError(fmt.Errorf("invalid role %s", s), 1)
+	}
+	return noderoles.Role(n), nil
+}
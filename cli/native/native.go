@@ -21,6 +21,11 @@ func NewCommands() []cli.Command {
 				Usage:       "manage policy",
 				Subcommands: newPolicyCommands(),
 			},
+			{
+				Name:        "designate",
+				Usage:       "manage node roles",
+				Subcommands: newDesignateCommands(),
+			},
 		},
 	},
 	}
@@ -35,6 +35,27 @@ func newPolicyCommands() []cli.Command {
 			Action:    isBlocked,
 			Flags:     options.RPC,
 		},
+		{
+			Name:      "adddeployer",
+			Usage:     "allow account to deploy contracts",
+			ArgsUsage: "<address> [quota]",
+			Action:    addDeployer,
+			Flags:     flags,
+		},
+		{
+			Name:      "removedeployer",
+			Usage:     "remove account from deployer allowlist",
+			ArgsUsage: "<address>",
+			Action:    removeDeployer,
+			Flags:     flags,
+		},
+		{
+			Name:      "isdeployallowed",
+			Usage:     "check whether account can deploy contracts",
+			ArgsUsage: "<address>",
+			Action:    isDeployAllowed,
+			Flags:     options.RPC,
+		},
 		{
 			Name:      "set",
 			Usage:     "set crucial parameters",
@@ -54,6 +75,48 @@ func newPolicyCommands() []cli.Command {
 					Action:    setGasPrice,
 					Flags:     flags,
 				},
+				{
+					Name:      "maxblocksize",
+					Usage:     "set MaxBlockSize, 0 restores configured value",
+					ArgsUsage: "<number>",
+					Action:    setMaxBlockSize,
+					Flags:     flags,
+				},
+				{
+					Name:      "maxblockgas",
+					Usage:     "set MaxBlockGas, 0 restores configured value",
+					ArgsUsage: "<number>",
+					Action:    setMaxBlockGas,
+					Flags:     flags,
+				},
+				{
+					Name:      "maxtxperblock",
+					Usage:     "set MaxTransactionsPerBlock, 0 restores configured value",
+					ArgsUsage: "<number>",
+					Action:    setMaxTransactionsPerBlock,
+					Flags:     flags,
+				},
+				{
+					Name:      "maxtxgas",
+					Usage:     "set maximum gas of tx, 0 removes the limit",
+					ArgsUsage: "<number>",
+					Action:    setMaxTxGas,
+					Flags:     flags,
+				},
+				{
+					Name:      "maxcontractsize",
+					Usage:     "set maximum contract code size, 0 restores default",
+					ArgsUsage: "<number>",
+					Action:    setMaxContractSize,
+					Flags:     flags,
+				},
+				{
+					Name:      "deployopen",
+					Usage:     "allow everyone (true) or only allowlisted accounts (false) to deploy contracts",
+					ArgsUsage: "<true|false>",
+					Action:    setDeployOpen,
+					Flags:     flags,
+				},
 			},
 		},
 	}
@@ -79,6 +142,25 @@ func isBlocked(ctx *cli.Context) error {
 	return nil
 }
 
+func isDeployAllowed(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return cli.NewExitError(fmt.Errorf("please input address"), 1)
+	}
+	address := common.HexToAddress(ctx.Args().First())
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	r, er := c.IsDeployAllowed(address)
+	if er != nil {
+		return cli.NewExitError(fmt.Errorf("failed get isDeployAllowed %w", er), 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "%t\n", r)
+	return nil
+}
+
 func blockAccount(ctx *cli.Context) error {
 	address, err := parseAddressInput(ctx)
 	if err != nil {
@@ -111,6 +193,81 @@ func setGasPrice(ctx *cli.Context) error {
 	return callNative(ctx, nativenames.Policy, "setGasPrice", value)
 }
 
+func addDeployer(ctx *cli.Context) error {
+	address, err := parseAddressInput(ctx)
+	if err != nil {
+		return err
+	}
+	var quota uint64
+	if len(ctx.Args()) > 1 {
+		num := ctx.Args().Get(1)
+		quota, err = strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid quota %s", num), 1)
+		}
+	}
+	return callNative(ctx, nativenames.Policy, "addDeployer", address, quota)
+}
+
+func removeDeployer(ctx *cli.Context) error {
+	address, err := parseAddressInput(ctx)
+	if err != nil {
+		return err
+	}
+	return callNative(ctx, nativenames.Policy, "removeDeployer", address)
+}
+
+func setDeployOpen(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return cli.NewExitError(fmt.Errorf("please input true or false"), 1)
+	}
+	open, err := strconv.ParseBool(ctx.Args().First())
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid value %s", ctx.Args().First()), 1)
+	}
+	return callNative(ctx, nativenames.Policy, "setDeployOpen", open)
+}
+
+func setMaxBlockSize(ctx *cli.Context) error {
+	value, err := parseUint32Input(ctx)
+	if err != nil {
+		return err
+	}
+	return callNative(ctx, nativenames.Policy, "setMaxBlockSize", value)
+}
+
+func setMaxBlockGas(ctx *cli.Context) error {
+	value, err := parseUint64Input(ctx)
+	if err != nil {
+		return err
+	}
+	return callNative(ctx, nativenames.Policy, "setMaxBlockGas", value)
+}
+
+func setMaxTransactionsPerBlock(ctx *cli.Context) error {
+	value, err := parseUint32Input(ctx)
+	if err != nil {
+		return err
+	}
+	return callNative(ctx, nativenames.Policy, "setMaxTransactionsPerBlock", value)
+}
+
+func setMaxTxGas(ctx *cli.Context) error {
+	value, err := parseUint64Input(ctx)
+	if err != nil {
+		return err
+	}
+	return callNative(ctx, nativenames.Policy, "setMaxTxGas", value)
+}
+
+func setMaxContractSize(ctx *cli.Context) error {
+	value, err := parseUint32Input(ctx)
+	if err != nil {
+		return err
+	}
+	return callNative(ctx, nativenames.Policy, "setMaxContractSize", value)
+}
+
 func parseAddressInput(ctx *cli.Context) (common.Address, error) {
 	if len(ctx.Args()) < 1 {
 		return common.Address{}, cli.NewExitError(fmt.Errorf("please input address"), 1)
@@ -131,3 +288,15 @@ func parseUint64Input(ctx *cli.Context) (uint64, error) {
 	}
 	return param, nil
 }
+
+func parseUint32Input(ctx *cli.Context) (uint32, error) {
+	if len(ctx.Args()) < 1 {
+		return 0, cli.NewExitError(fmt.Errorf("please input number"), 1)
+	}
+	num := ctx.Args().First()
+	param, err := strconv.ParseUint(num, 10, 32)
+	if err != nil {
+		return 0, cli.NewExitError(fmt.Errorf("invalid number %s", num), 1)
+	}
+	return uint32(param), nil
+}
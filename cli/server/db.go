@@ -0,0 +1,359 @@
+package server
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/cli/input"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao/migrations"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dbbackup"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dbinspect"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dbrewind"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dbverify"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli"
+)
+
+// progressStep is the number of processed items between progress reports.
+const progressStep = 10000
+
+func migrateDB(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	store, err := storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("could not initialize storage: %w", err), 1)
+	}
+	defer store.Close()
+
+	ver, err := dao.NewSimple(store).GetVersion()
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't get database version: %w", err), 1)
+	}
+	plan, err := migrations.Default.Plan(ver.Value, core.StorageVersion)
+	if err != nil {
+		if errors.Is(err, migrations.ErrNoPath) {
+			return cli.NewExitError(fmt.Errorf("%w, resync is required", err), 1)
+		}
+		return cli.NewExitError(err, 1)
+	}
+	if len(plan) == 0 {
+		fmt.Fprintf(ctx.App.Writer, "database version %s is up to date\n", ver.Value)
+		return nil
+	}
+	fmt.Fprintf(ctx.App.Writer, "database version %s, upgrading to %s:\n", ver.Value, core.StorageVersion)
+	for _, m := range plan {
+		fmt.Fprintf(ctx.App.Writer, "  %s -> %s: %s\n", m.From, m.To, m.Description)
+	}
+	if ctx.Bool("dry-run") {
+		return nil
+	}
+	var last int
+	onStep := func(m migrations.Migration) {
+		last = 0
+		fmt.Fprintf(ctx.App.Writer, "applying %s -> %s\n", m.From, m.To)
+	}
+	onProgress := func(processed int) {
+		if processed-last >= progressStep {
+			last = processed
+			fmt.Fprintf(ctx.App.Writer, "  processed %d items\n", processed)
+		}
+	}
+	err = migrations.Default.Run(store, core.StorageVersion, onStep, onProgress)
+	if err != nil {
+		if errors.Is(err, migrations.ErrNoPath) {
+			return cli.NewExitError(fmt.Errorf("%w, resync is required", err), 1)
+		}
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "database is upgraded to %s\n", core.StorageVersion)
+	return nil
+}
+
+func backupDB(ctx *cli.Context) error {
+	out := ctx.String("out")
+	if out == "" {
+		return cli.NewExitError("output path is required", 1)
+	}
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	store, err := storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("could not initialize storage: %w", err), 1)
+	}
+	defer store.Close()
+
+	m, err := dbbackup.Create(store, out)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't create backup: %w", err), 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "backup of version %s at height %d (state root %s) is written to %s: %d keys in %d chunks\n",
+		m.Version, m.Height, m.StateRoot, out, m.Keys, len(m.Chunks))
+	return nil
+}
+
+func restoreBackupDB(ctx *cli.Context) error {
+	in := ctx.String("in")
+	if in == "" {
+		return cli.NewExitError("input path is required", 1)
+	}
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	m, err := dbbackup.ReadManifest(in)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	plan, err := migrations.Default.Plan(m.Version, core.StorageVersion)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("backup can't be used with storage version %s: %w", core.StorageVersion, err), 1)
+	}
+	if m.KeepOnlyLatestState != cfg.ProtocolConfiguration.KeepOnlyLatestState {
+		return cli.NewExitError(fmt.Errorf("KeepOnlyLatestState setting mismatch (backup=%v, config=%v)",
+			m.KeepOnlyLatestState, cfg.ProtocolConfiguration.KeepOnlyLatestState), 1)
+	}
+	store, err := storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("could not initialize storage: %w", err), 1)
+	}
+	defer store.Close()
+
+	fmt.Fprintf(ctx.App.Writer, "restoring backup of version %s at height %d (state root %s)\n", m.Version, m.Height, m.StateRoot)
+	if _, err := dbbackup.Restore(in, store); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't restore backup: %w", err), 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "restored %d keys, manifest is verified\n", m.Keys)
+	if len(plan) != 0 {
+		fmt.Fprintf(ctx.App.Writer, "database version %s is outdated, run 'db migrate' to upgrade it to %s\n", m.Version, core.StorageVersion)
+	}
+	return nil
+}
+
+func inspectDB(ctx *cli.Context) error {
+	var prefix []byte
+	if ctx.IsSet("dump") {
+		var err error
+		prefix, err = hex.DecodeString(strings.TrimPrefix(ctx.String("dump"), "0x"))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid key prefix: %w", err), 1)
+		}
+	}
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	store, err := storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("could not initialize storage: %w", err), 1)
+	}
+	defer store.Close()
+
+	if ctx.IsSet("dump") {
+		decode := ctx.Bool("decode")
+		dbinspect.Dump(store, prefix, ctx.Int("limit"), func(k, v []byte) bool {
+			if decode {
+				fmt.Fprintln(ctx.App.Writer, dbinspect.Decode(k, v))
+			} else {
+				fmt.Fprintf(ctx.App.Writer, "%s %s\n", hex.EncodeToString(k), hex.EncodeToString(v))
+			}
+			return true
+		})
+		return nil
+	}
+
+	r := dbinspect.Inspect(store)
+	w := tabwriter.NewWriter(ctx.App.Writer, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "PREFIX\tKEYS\tKEY SIZE\tVALUE SIZE\tTOTAL SIZE\t")
+	for _, p := range r.Prefixes {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t\n", dbinspect.PrefixName(p.Prefix), p.Keys, p.KeySize, p.ValueSize, p.Size())
+	}
+	fmt.Fprintf(w, "Total\t%d\t%d\t%d\t%d\t\n", r.Total.Keys, r.Total.KeySize, r.Total.ValueSize, r.Total.Size())
+	if len(r.Contracts) != 0 {
+		fmt.Fprintln(w, "\t\t\t\t\t")
+		fmt.Fprintln(w, "CONTRACT\tKEYS\tKEY SIZE\tVALUE SIZE\tTOTAL SIZE\t")
+		for _, c := range r.TopContracts(ctx.Int("top")) {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t\n", c.Address, c.Keys, c.KeySize, c.ValueSize, c.Size())
+		}
+	}
+	return w.Flush()
+}
+
+func verifyDB(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	log, _, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if logCloser != nil {
+		defer func() { _ = logCloser() }()
+	}
+
+	var tmpStore storage.Store = storage.NewMemoryStore()
+	if dir := ctx.String("temp-dir"); dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return cli.NewExitError(err, 1)
+		}
+		if len(entries) != 0 {
+			return cli.NewExitError(fmt.Errorf("temporary directory %s is not empty", dir), 1)
+		}
+		tmpStore, err = storage.NewLevelDBStore(storage.LevelDBOptions{DataDirectoryPath: dir})
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("could not initialize temporary storage: %w", err), 1)
+		}
+	}
+	// Only the latest state of the re-executed chain is needed.
+	tmpCfg := cfg.ProtocolConfiguration
+	tmpCfg.KeepOnlyLatestState = true
+	if snapshot := ctx.String("snapshot"); snapshot != "" {
+		m, err := dbbackup.Restore(snapshot, tmpStore)
+		if err != nil {
+			tmpStore.Close()
+			return cli.NewExitError(fmt.Errorf("can't restore snapshot: %w", err), 1)
+		}
+		tmpCfg.KeepOnlyLatestState = m.KeepOnlyLatestState
+		fmt.Fprintf(ctx.App.Writer, "starting from snapshot at height %d (state root %s)\n", m.Height, m.StateRoot)
+	}
+	tmp, err := core.NewBlockchain(tmpStore, tmpCfg, log)
+	if err != nil {
+		tmpStore.Close()
+		return cli.NewExitError(fmt.Errorf("could not initialize re-executed chain: %w", err), 1)
+	}
+	go tmp.Run()
+	defer tmp.Close()
+
+	chain, err := initBlockChain(cfg, log)
+	if err != nil {
+		return err
+	}
+	go chain.Run()
+	defer chain.Close()
+
+	to := chain.BlockHeight()
+	if ctx.IsSet("height") {
+		to = uint32(ctx.Uint("height"))
+	}
+	from := tmp.BlockHeight()
+	if from == to {
+		fmt.Fprintf(ctx.App.Writer, "nothing to verify, chain height is %d\n", to)
+		return nil
+	}
+	fmt.Fprintf(ctx.App.Writer, "verifying blocks %d..%d\n", from+1, to)
+	d, err := dbverify.Verify(chain, tmp, to, func(h uint32) {
+		if (h-from)%progressStep == 0 {
+			fmt.Fprintf(ctx.App.Writer, "  verified block %d\n", h)
+		}
+	})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if d == nil {
+		fmt.Fprintf(ctx.App.Writer, "blocks %d..%d are verified\n", from+1, to)
+		return nil
+	}
+	fmt.Fprintf(ctx.App.Writer, "divergence at block %d: %s\n", d.Height, d.Reason)
+	if d.StateRoot != d.NewStateRoot {
+		fmt.Fprintf(ctx.App.Writer, "stored state root %s, re-executed %s\n", d.StateRoot, d.NewStateRoot)
+		if d.DiffErr != nil {
+			fmt.Fprintf(ctx.App.Writer, "can't calculate state difference: %v\n", d.DiffErr)
+		}
+		for _, kd := range d.Diff {
+			fmt.Fprintf(ctx.App.Writer, "  %s\n", formatKeyDiff(kd))
+		}
+	}
+	return cli.NewExitError(fmt.Sprintf("database diverges at block %d", d.Height), 1)
+}
+
+// formatKeyDiff returns human-readable representation of the contract
+// storage key difference.
+func formatKeyDiff(kd mpt.KeyDiff) string {
+	value := func(v []byte) string {
+		if v == nil {
+			return "<none>"
+		}
+		return hex.EncodeToString(v)
+	}
+	key := hex.EncodeToString(kd.Key)
+	if len(kd.Key) >= common.AddressLength {
+		key = common.BytesToAddress(kd.Key[:common.AddressLength]).String() + " " + hex.EncodeToString(kd.Key[common.AddressLength:])
+	}
+	return fmt.Sprintf("%s: %s -> %s", key, value(kd.Value), value(kd.NewValue))
+}
+
+func rewindDB(ctx *cli.Context) error {
+	if !ctx.IsSet("height") {
+		return cli.NewExitError("target height is required", 1)
+	}
+	height := uint32(ctx.Uint("height"))
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	store, err := storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("could not initialize storage: %w", err), 1)
+	}
+	defer store.Close()
+
+	bh, hh, err := dbrewind.Heights(store)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "current block height %d, header height %d\n", bh, hh)
+	if height > bh {
+		return cli.NewExitError(fmt.Errorf("target height %d is above the current block height %d", height, bh), 1)
+	}
+	if height == bh && height == hh {
+		fmt.Fprintf(ctx.App.Writer, "nothing to rewind, chain height is %d\n", height)
+		return nil
+	}
+	fmt.Fprintf(ctx.App.Writer, "blocks and headers %d..%d, their transactions and receipts will be removed\n", height+1, hh)
+	if !ctx.Bool("force") && !askForConsent(ctx.App.Writer) {
+		return nil
+	}
+	res, err := dbrewind.Rewind(store, cfg.ProtocolConfiguration, height)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't rewind: %w", err), 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "removed %d blocks, restored %d storage items, chain height is %d (state root %s)\n",
+		res.Blocks, res.StorageItems, res.Height, res.StateRoot)
+	// Journaled transactions may be invalid at the new height.
+	if path := cfg.ApplicationConfiguration.MempoolJournal.Path; path != "" {
+		for _, p := range []string{path, path + ".new"} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return cli.NewExitError(fmt.Errorf("can't remove mempool journal: %w", err), 1)
+			}
+		}
+		fmt.Fprintf(ctx.App.Writer, "mempool journal %s is cleared\n", path)
+	}
+	return nil
+}
+
+func askForConsent(w io.Writer) bool {
+	response, err := input.ReadLine("Are you sure? [y/N]: ")
+	if err == nil {
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response == "y" || response == "yes" {
+			return true
+		}
+	}
+	fmt.Fprintln(w, "Cancelled.")
+	return false
+}
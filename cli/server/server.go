@@ -21,7 +21,11 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/metrics"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/server"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/oracle"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/oracle/broadcaster"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/txjournal"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/util/logging"
 	"github.com/urfave/cli"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -103,6 +107,92 @@ func NewCommands() []cli.Command {
 					Action: restoreDB,
 					Flags:  cfgCountInFlags,
 				},
+				{
+					Name:   "migrate",
+					Usage:  "upgrade the database to the current storage version",
+					Action: migrateDB,
+					Flags: append(cfgFlags, cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "only print migrations to be applied",
+					}),
+				},
+				{
+					Name:   "inspect",
+					Usage:  "print key counts and sizes per key prefix and per contract or dump keys",
+					Action: inspectDB,
+					Flags: append(cfgFlags,
+						cli.IntFlag{
+							Name:  "top",
+							Usage: "number of contracts with the biggest storage to print (0: all)",
+							Value: 10,
+						},
+						cli.StringFlag{
+							Name:  "dump",
+							Usage: "hex-encoded key prefix to dump keys for instead of printing statistics",
+						},
+						cli.IntFlag{
+							Name:  "limit",
+							Usage: "maximum number of keys to dump (0: no limit)",
+							Value: 100,
+						},
+						cli.BoolFlag{
+							Name:  "decode",
+							Usage: "print dumped keys in human-readable form",
+						},
+					),
+				},
+				{
+					Name:   "verify",
+					Usage:  "re-execute blocks and compare state roots and receipts with the stored ones",
+					Action: verifyDB,
+					Flags: append(cfgFlags,
+						cli.UintFlag{
+							Name:  "height",
+							Usage: "height to verify the chain up to (default: current height)",
+						},
+						cli.StringFlag{
+							Name:  "snapshot",
+							Usage: "trusted backup made by 'db backup' to start re-execution from (default: genesis)",
+						},
+						cli.StringFlag{
+							Name:  "temp-dir",
+							Usage: "empty directory for the re-executed chain LevelDB (default: in-memory)",
+						},
+					),
+				},
+				{
+					Name:   "rewind",
+					Usage:  "roll the stopped node database back to the specified height",
+					Action: rewindDB,
+					Flags: append(cfgFlags,
+						cli.UintFlag{
+							Name:  "height",
+							Usage: "height to rewind the chain to",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "do not ask for a confirmation",
+						},
+					),
+				},
+				{
+					Name:   "backup",
+					Usage:  "write a backup of the stopped node database to a directory or .tar file",
+					Action: backupDB,
+					Flags: append(cfgFlags, cli.StringFlag{
+						Name:  "out, o",
+						Usage: "output directory or .tar file",
+					}),
+				},
+				{
+					Name:   "restore-backup",
+					Usage:  "restore the empty database from a backup made by 'db backup' or admin_backup RPC",
+					Action: restoreBackupDB,
+					Flags: append(cfgFlags, cli.StringFlag{
+						Name:  "in, i",
+						Usage: "backup directory or .tar file",
+					}),
+				},
 			},
 		},
 	}
@@ -136,11 +226,12 @@ func getConfigFromContext(ctx *cli.Context) (config.Config, error) {
 // If logPath is configured -- function creates dir and file for logging.
 // If logPath is configured on Windows -- function returns closer to be
 // able to close sink for opened log output file.
-func handleLoggingParams(ctx *cli.Context, cfg config.ApplicationConfiguration) (*zap.Logger, func() error, error) {
+func handleLoggingParams(ctx *cli.Context, cfg config.ApplicationConfiguration) (*zap.Logger, *logging.Levels, func() error, error) {
 	level := zapcore.InfoLevel
 	if ctx.Bool("debug") {
 		level = zapcore.DebugLevel
 	}
+	levels := logging.NewLevels(level)
 
 	cc := zap.NewProductionConfig()
 	cc.DisableCaller = true
@@ -149,12 +240,13 @@ func handleLoggingParams(ctx *cli.Context, cfg config.ApplicationConfiguration)
 	cc.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
 	cc.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	cc.Encoding = "console"
-	cc.Level = zap.NewAtomicLevelAt(level)
+	// Entries are filtered by levels, they can be changed at runtime.
+	cc.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
 	cc.Sampling = nil
 
 	if logPath := cfg.LogPath; logPath != "" {
 		if err := io.MakeDirForFile(logPath, "logger"); err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		if runtime.GOOS == "windows" {
@@ -192,7 +284,7 @@ func handleLoggingParams(ctx *cli.Context, cfg config.ApplicationConfiguration)
 					return f, err
 				})
 				if err != nil {
-					return nil, nil, fmt.Errorf("failed to register windows-specific sinc: %w", err)
+					return nil, nil, nil, fmt.Errorf("failed to register windows-specific sinc: %w", err)
 				}
 				_winfileSinkRegistered = true
 			}
@@ -202,12 +294,12 @@ func handleLoggingParams(ctx *cli.Context, cfg config.ApplicationConfiguration)
 		cc.OutputPaths = []string{logPath}
 	}
 
-	log, err := cc.Build()
-	return log, _winfileSinkCloser, err
+	log, err := cc.Build(zap.WrapCore(levels.WrapCore))
+	return log, levels, _winfileSinkCloser, err
 }
 
 func initBCWithMetrics(cfg config.Config, log *zap.Logger) (*core.Blockchain, *metrics.Service, *metrics.Service, error) {
-	chain, err := initBlockChain(cfg, log)
+	chain, err := initBlockChain(cfg, log.Named("chain"))
 	if err != nil {
 		return nil, nil, nil, cli.NewExitError(err, 1)
 	}
@@ -227,7 +319,7 @@ func dumpDB(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
-	log, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	log, _, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -277,7 +369,7 @@ func restoreDB(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	log, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	log, _, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -406,6 +498,36 @@ func mkConsensus(config network.ServerConfig, chain *core.Blockchain, serv *netw
 	return srv, nil
 }
 
+func mkOracle(config config.OracleConfiguration, chain *core.Blockchain, serv *network.Server, log *zap.Logger) (*oracle.Oracle, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	orcCfg := oracle.Config{
+		Log:             log,
+		MainCfg:         config,
+		Chain:           chain,
+		ResponseHandler: broadcaster.New(config, log),
+		OnTransaction:   serv.RelayTxn,
+	}
+	orc, err := oracle.New(orcCfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't initialize Oracle module: %w", err)
+	}
+	serv.AddService(orc)
+	return orc, nil
+}
+
+func mkMempoolJournal(config config.MempoolJournal, chain *core.Blockchain, serv *network.Server, log *zap.Logger) error {
+	j, err := txjournal.New(config, chain, log)
+	if err != nil {
+		return fmt.Errorf("can't initialize mempool journal: %w", err)
+	}
+	if j != nil {
+		serv.AddService(j)
+	}
+	return nil
+}
+
 func startServer(ctx *cli.Context) error {
 	cfg, err := getConfigFromContext(ctx)
 	if err != nil {
@@ -415,7 +537,7 @@ func startServer(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
-	log, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	log, levels, logCloser, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -438,25 +560,39 @@ func startServer(ctx *cli.Context) error {
 		chain.Close()
 	}()
 
-	serv, err := network.NewServer(serverConfig, chain, log)
+	serv, err := network.NewServer(serverConfig, chain, log.Named("network"))
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("failed to create network server: %w", err), 1)
 	}
 	srMod := chain.GetStateModule().(*corestate.Module) // Take full responsibility here.
-	sr, err := stateroot.New(serverConfig.StateRootCfg, srMod, log, chain, serv.BroadcastExtensible)
+	sr, err := stateroot.New(serverConfig.StateRootCfg, srMod, log.Named("stateroot"), chain, serv.BroadcastExtensible)
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("can't initialize StateRoot service: %w", err), 1)
 	}
 	serv.AddExtensibleService(sr, stateroot.Category, sr.OnPayload)
 
 	if asConsensus {
-		_, err = mkConsensus(serverConfig, chain, serv, log)
+		_, err = mkConsensus(serverConfig, chain, serv, log.Named("consensus"))
 		if err != nil {
 			return cli.NewExitError(err, 1)
 		}
 	}
 
-	rpcServer := server.New(chain, cfg.ApplicationConfiguration.RPC, serv, serverConfig.Wallet, log)
+	oracleSrv, err := mkOracle(cfg.ApplicationConfiguration.Oracle, chain, serv, log.Named("oracle"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	var orc server.OracleHandler
+	if oracleSrv != nil {
+		orc = oracleSrv
+	}
+
+	err = mkMempoolJournal(cfg.ApplicationConfiguration.MempoolJournal, chain, serv, log.Named("txjournal"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	rpcServer := server.New(chain, cfg.ApplicationConfiguration.RPC, serv, orc, serverConfig.Wallet, log.Named("rpc"), levels)
 	errChan := make(chan error)
 
 	go serv.Start(errChan)
@@ -485,7 +621,7 @@ Main:
 					errChan <- fmt.Errorf("error while restarting rpc-server: %w", serverErr)
 					break
 				}
-				rpcServer = server.New(chain, cfg.ApplicationConfiguration.RPC, serv, serverConfig.Wallet, log)
+				rpcServer = server.New(chain, cfg.ApplicationConfiguration.RPC, serv, orc, serverConfig.Wallet, log.Named("rpc"), levels)
 				rpcServer.Start(errChan)
 			}
 		case <-grace.Done():
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// AddressBook is a config for the P2P address book which keeps known peer
+// addresses along with their misbehaviour scores and bans.
+type AddressBook struct {
+	// Path is the address book file, addresses are kept in memory only if
+	// it's empty.
+	Path string `yaml:"Path"`
+	// BanScore is the misbehaviour score peer is banned at.
+	BanScore int `yaml:"BanScore"`
+	// BanDuration is the time misbehaving peer is banned for.
+	BanDuration time.Duration `yaml:"BanDuration"`
+	// ScoreDecay is the time it takes for misbehaviour score to decrease by
+	// one point, so that occasional errors don't lead to a ban.
+	ScoreDecay time.Duration `yaml:"ScoreDecay"`
+}
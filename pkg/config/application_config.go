@@ -26,6 +26,16 @@ type ApplicationConfiguration struct {
 	RPC               rpc.Config              `yaml:"RPC"`
 	UnlockWallet      Wallet                  `yaml:"UnlockWallet"`
 	StateRoot         StateRoot               `yaml:"StateRoot"`
+	Oracle            OracleConfiguration     `yaml:"Oracle"`
+	MempoolJournal    MempoolJournal          `yaml:"MempoolJournal"`
+	AddressBook       AddressBook             `yaml:"AddressBook"`
+	P2PEncryption     P2PEncryption           `yaml:"P2PEncryption"`
+	LightNode         LightNode               `yaml:"LightNode"`
+	RateLimit         RateLimit               `yaml:"RateLimit"`
+	// CompactBlocks enables compact block relay with the peers supporting
+	// it. The capability is skipped by the nodes not knowing it, but nodes
+	// that fail on unknown capabilities can't handshake with this one.
+	CompactBlocks bool `yaml:"CompactBlocks"`
 	// ExtensiblePoolSize is the maximum amount of the extensible payloads from a single sender.
 	ExtensiblePoolSize int `yaml:"ExtensiblePoolSize"`
 }
@@ -45,6 +45,12 @@ func LoadFile(configPath string) (Config, error) {
 	}
 
 	config := Config{
+		ProtocolConfiguration: ProtocolConfiguration{
+			MemPoolQueueLifetime: 240,
+			MemPoolPriceBump:     10,
+			MemPoolMaxPerSender:  128,
+			MemPoolTxLifetime:    5760,
+		},
 		ApplicationConfiguration: ApplicationConfiguration{
 			PingInterval: 30,
 			PingTimeout:  90,
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,3 +15,26 @@ func TestLoadConfig(t *testing.T) {
 	_, err := LoadFile(testConfigPath)
 	require.NoError(t, err)
 }
+
+func TestLoadConfigMemPoolDefaults(t *testing.T) {
+	cfg, err := LoadFile(testConfigPath)
+	require.NoError(t, err)
+	require.Equal(t, uint32(240), cfg.ProtocolConfiguration.MemPoolQueueLifetime)
+	require.Equal(t, uint64(10), cfg.ProtocolConfiguration.MemPoolPriceBump)
+	require.Equal(t, 128, cfg.ProtocolConfiguration.MemPoolMaxPerSender)
+	require.Equal(t, uint32(5760), cfg.ProtocolConfiguration.MemPoolTxLifetime)
+
+	data, err := os.ReadFile(testConfigPath)
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "  MemPoolSize: 50000\n",
+		"  MemPoolSize: 50000\n  MemPoolQueueLifetime: 0\n  MemPoolPriceBump: 0\n"+
+			"  MemPoolMaxPerSender: 0\n  MemPoolTxLifetime: 0\n", 1))
+	path := filepath.Join(t.TempDir(), "protocol.yml")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	cfg, err = LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), cfg.ProtocolConfiguration.MemPoolQueueLifetime)
+	require.Equal(t, uint64(0), cfg.ProtocolConfiguration.MemPoolPriceBump)
+	require.Equal(t, 0, cfg.ProtocolConfiguration.MemPoolMaxPerSender)
+	require.Equal(t, uint32(0), cfg.ProtocolConfiguration.MemPoolTxLifetime)
+}
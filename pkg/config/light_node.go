@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// LightNode is a config for the headers-only node mode. Light node verifies
+// headers and state roots signed by state validators and answers state
+// requests with the MPT proofs fetched from full nodes.
+type LightNode struct {
+	Enabled bool `yaml:"Enabled"`
+	// StateValidators is a list of hex-encoded state validator keys trusted
+	// at the first state root received, later changes are tracked via
+	// verified designation proofs.
+	StateValidators []string `yaml:"StateValidators"`
+	// ProofCacheSize is the number of verified state proofs cached.
+	ProofCacheSize int `yaml:"ProofCacheSize"`
+	// ProofTimeout is the time to wait for the proof from a single peer.
+	ProofTimeout time.Duration `yaml:"ProofTimeout"`
+}
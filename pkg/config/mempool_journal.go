@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// MempoolJournal is a config for the mempool journal which keeps accepted
+// transactions on disk to restore them after node restart.
+type MempoolJournal struct {
+	Enabled bool   `yaml:"Enabled"`
+	Path    string `yaml:"Path"`
+	// RewriteInterval is the period of journal compaction, journal is
+	// rewritten with the current mempool contents.
+	RewriteInterval time.Duration `yaml:"RewriteInterval"`
+}
@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// OracleConfiguration is a config for the oracle module.
+type OracleConfiguration struct {
+	Enabled bool `yaml:"Enabled"`
+	// AllowedHosts is a list of hosts oracle is allowed to fetch data
+	// from, any host is allowed when it's empty.
+	AllowedHosts     []string `yaml:"AllowedHosts"`
+	AllowPrivateHost bool     `yaml:"AllowPrivateHost"`
+	// MaxConcurrentRequests is the maximum number of requests processed
+	// simultaneously.
+	MaxConcurrentRequests int `yaml:"MaxConcurrentRequests"`
+	// MaxResponseSize is the maximum size of fetched content in bytes.
+	MaxResponseSize int64 `yaml:"MaxResponseSize"`
+	// Nodes are RPC addresses of other oracle nodes to send signatures to.
+	Nodes          []string      `yaml:"Nodes"`
+	RequestTimeout time.Duration `yaml:"RequestTimeout"`
+	UnlockWallet   Wallet        `yaml:"UnlockWallet"`
+}
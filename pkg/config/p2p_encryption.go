@@ -0,0 +1,14 @@
+package config
+
+// P2PEncryption is a config for the encrypted P2P transport. Connections are
+// made over TLS 1.3 and authenticated with ed25519 node keys, nodes with it
+// enabled can't connect to nodes without it.
+type P2PEncryption struct {
+	Enabled bool `yaml:"Enabled"`
+	// KeyFile is the node key file, new key is generated if it doesn't
+	// exist.
+	KeyFile string `yaml:"KeyFile"`
+	// AllowedKeys is a list of hex-encoded node public keys that are allowed
+	// to connect, any node can connect if it's empty.
+	AllowedKeys []string `yaml:"AllowedKeys"`
+}
@@ -17,6 +17,25 @@ type (
 
 		ChainID     uint64 `yaml:"ChainID"`
 		MemPoolSize int    `yaml:"MemPoolSize"`
+		// MemPoolQueueSize is the maximum number of transactions with future
+		// nonces kept in the mempool queue.
+		MemPoolQueueSize int `yaml:"MemPoolQueueSize"`
+		// MemPoolQueuePerAccount is the maximum number of queued transactions
+		// per sender.
+		MemPoolQueuePerAccount int `yaml:"MemPoolQueuePerAccount"`
+		// MemPoolQueueLifetime is the number of blocks queued transaction is
+		// kept for before eviction, zero means forever.
+		MemPoolQueueLifetime uint32 `yaml:"MemPoolQueueLifetime"`
+		// MemPoolPriceBump is the minimum gas price increase in percents
+		// required to replace mempool transaction with the same nonce, zero
+		// means any increase.
+		MemPoolPriceBump uint64 `yaml:"MemPoolPriceBump"`
+		// MemPoolMaxPerSender is the maximum number of pending transactions
+		// per sender, zero means no limit.
+		MemPoolMaxPerSender int `yaml:"MemPoolMaxPerSender"`
+		// MemPoolTxLifetime is the number of blocks after which pending
+		// transaction is dropped from the mempool, zero means never.
+		MemPoolTxLifetime uint32 `yaml:"MemPoolTxLifetime"`
 
 		// InitialGASSupply is the amount of GAS generated in the genesis block.
 		InitialGASSupply uint64 `yaml:"InitialGASSupply"`
@@ -26,6 +45,17 @@ type (
 		KeepOnlyLatestState bool `yaml:"KeepOnlyLatestState"`
 		// RemoveUntraceableBlocks specifies if old data should be removed.
 		RemoveUntraceableBlocks bool `yaml:"RemoveUntraceableBlocks"`
+		// P2PStateExchangeExtensions enables state exchange P2P commands, new
+		// nodes use them to synchronise state at the latest state sync point
+		// instead of replaying all blocks from genesis.
+		P2PStateExchangeExtensions bool `yaml:"P2PStateExchangeExtensions"`
+		// StateSyncInterval is the number of blocks between state sync points.
+		StateSyncInterval uint32 `yaml:"StateSyncInterval"`
+		// StateSyncValidators are the state validators trusted to sign state
+		// roots of state sync points, they're required for state exchange
+		// because the synchronised state can't be checked by itself.
+		StateSyncValidators    keys.PublicKeys
+		StateSyncValidatorsStr []string `yaml:"StateSyncValidators"`
 		// MaxBlockSize is the maximum block size in bytes.
 		MaxBlockSize uint32 `yaml:"MaxBlockSize"`
 		// MaxBlockSystemFee is the maximum overall system fee per block.
@@ -44,6 +74,16 @@ type (
 		VerifyBlocks bool `yaml:"VerifyBlocks"`
 		// Whether to verify transactions in received blocks.
 		VerifyTransactions bool `yaml:"VerifyTransactions"`
+		// ParallelExecution enables optimistic parallel execution of block
+		// transactions. Results are the same as for sequential execution.
+		ParallelExecution bool `yaml:"ParallelExecution"`
+		// ParallelExecutionWorkers is the number of transactions executed
+		// concurrently, GOMAXPROCS is used by default.
+		ParallelExecutionWorkers int `yaml:"ParallelExecutionWorkers"`
+		// NativeActivations are the heights native contracts become available
+		// at, keys are contract names. Contracts added after the network launch
+		// are disabled unless listed here, others are available since genesis.
+		NativeActivations map[string]uint32 `yaml:"NativeActivations"`
 
 		MainNetwork                          uint32 `yaml:"MainNetwork"`
 		MainStandbyStateValidatorsScriptHash string `yaml:"MainStandbyStateValidatorsScriptHash"`
@@ -65,6 +105,16 @@ func (p *ProtocolConfiguration) Validate() error {
 	if len(p.StandbyValidators) == 0 {
 		return errors.New("StandbyValidators can't be empty")
 	}
+	stateSyncValidators, err := keys.NewPublicKeysFromStrings(p.StateSyncValidatorsStr)
+	if err != nil {
+		return err
+	}
+	stateSyncValidators = stateSyncValidators.Unique()
+	sort.Sort(stateSyncValidators)
+	p.StateSyncValidators = stateSyncValidators
+	if p.P2PStateExchangeExtensions && len(p.StateSyncValidators) == 0 {
+		return errors.New("StateSyncValidators can't be empty when P2PStateExchangeExtensions are enabled")
+	}
 	return nil
 }
 
@@ -0,0 +1,29 @@
+package config
+
+// RateLimit is a config for per-peer limits of incoming P2P messages. Messages
+// exceeding limits are dropped, exceeding the byte budget or dropping many
+// messages also adds misbehaviour score to the peer.
+type RateLimit struct {
+	Enabled bool `yaml:"Enabled"`
+	// Commands are the limits for specific commands overriding built-in
+	// ones, keys are command names like "CMDGetData", unknown names are
+	// rejected.
+	Commands map[string]CommandRateLimit `yaml:"Commands"`
+	// BytesPerSecond is the rate of incoming bytes allowed for the peer, the
+	// byte budget is not limited if it's zero.
+	BytesPerSecond int `yaml:"BytesPerSecond"`
+	// ByteBurst is the number of bytes the peer can send at once.
+	ByteBurst int `yaml:"ByteBurst"`
+	// MaxInFlight is the number of requests received from the peer and not
+	// handled yet after which its further requests are dropped.
+	MaxInFlight int `yaml:"MaxInFlight"`
+}
+
+// CommandRateLimit is a token bucket limit for a single command.
+type CommandRateLimit struct {
+	// Rate is the number of messages per second, the command is not limited
+	// if it's zero.
+	Rate float64 `yaml:"Rate"`
+	// Burst is the number of messages the peer can send at once.
+	Burst int `yaml:"Burst"`
+}
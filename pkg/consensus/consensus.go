@@ -41,6 +41,9 @@ type Ledger interface {
 	AddBlock(block *coreb.Block) error
 	ApplyPolicyToTxSet([]*transaction.Transaction) []*transaction.Transaction
 	GetConfig() config.ProtocolConfiguration
+	GetMaxBlockGas() uint64
+	GetMaxBlockSize() uint32
+	GetMaxTransactionsPerBlock() uint16
 	GetMemPool() *mempool.Pool
 	GetStateModule() blockchainer.StateRoot
 	GetTransaction(common.Hash) (*transaction.Transaction, *types.Receipt, error)
@@ -434,9 +437,9 @@ func (s *service) verifyBlock(b block.Block) bool {
 	}
 
 	size := coreb.GetExpectedBlockSize()
-	if size > int(s.ProtocolConfiguration.MaxBlockSize) {
-		s.log.Warn("proposed block size exceeds config MaxBlockSize",
-			zap.Uint32("max size allowed", s.ProtocolConfiguration.MaxBlockSize),
+	if maxBlockSize := s.Chain.GetMaxBlockSize(); size > int(maxBlockSize) {
+		s.log.Warn("proposed block size exceeds MaxBlockSize",
+			zap.Uint32("max size allowed", maxBlockSize),
 			zap.Int("block size", size))
 		return false
 	}
@@ -468,9 +471,9 @@ func (s *service) verifyBlock(b block.Block) bool {
 		}
 	}
 
-	maxBlockGas := s.ProtocolConfiguration.MaxBlockGas
+	maxBlockGas := s.Chain.GetMaxBlockGas()
 	if gas > maxBlockGas {
-		s.log.Warn("proposed block system fee exceeds config MaxBlockSystemFee",
+		s.log.Warn("proposed block system fee exceeds MaxBlockSystemFee",
 			zap.Int("max system fee allowed", int(maxBlockGas)),
 			zap.Int("block system fee", int(gas)))
 		return false
@@ -493,8 +496,8 @@ func (s *service) verifyRequest(p payload.ConsensusPayload) error {
 	if req.version != s.dbft.Version {
 		return errInvalidVersion
 	}
-	if len(req.TransactionHashes()) > int(s.ProtocolConfiguration.MaxTransactionsPerBlock) {
-		return fmt.Errorf("%w: max = %d, got %d", errInvalidTransactionsCount, s.ProtocolConfiguration.MaxTransactionsPerBlock, len(req.TransactionHashes()))
+	if maxTx := s.Chain.GetMaxTransactionsPerBlock(); len(req.TransactionHashes()) > int(maxTx) {
+		return fmt.Errorf("%w: max = %d, got %d", errInvalidTransactionsCount, maxTx, len(req.TransactionHashes()))
 	}
 	// Save lastProposal for getVerified().
 	s.lastProposal = req.transactionHashes
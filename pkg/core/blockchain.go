@@ -13,7 +13,10 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/consensus"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/blockchainer"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/blockstm"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao/migrations"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dbbackup"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/filters"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/interop"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
@@ -23,6 +26,7 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/statedb"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/statesync"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
@@ -35,14 +39,20 @@ import (
 	"go.uber.org/zap"
 )
 
+// StorageVersion is the version of the database format used by Blockchain,
+// older databases can be upgraded with migrations.
+const StorageVersion = "0.2.5"
+
 // Tuning parameters.
 const (
 	headerBatchCount = 2000
-	version          = "0.2.5"
+	version          = StorageVersion
 
 	defaultInitialGAS                      = 52000000 //wei
 	defaultGCPeriod                        = 10000
 	defaultMemPoolSize                     = 50000
+	defaultMemPoolQueueSize                = 1024
+	defaultMemPoolQueuePerAccount          = 64
 	defaultP2PNotaryRequestPayloadPoolSize = 1000
 	defaultMaxBlockSize                    = 262144
 	defaultMaxBlockGas                     = 90000000000
@@ -71,6 +81,18 @@ var (
 	// conflicts with other transaction in the chain or pool according to
 	// Conflicts attribute.
 	ErrHasConflicts = errors.New("has conflicts")
+	// ErrReplaceUnderpriced is returned when transaction replacing the pooled
+	// one with the same nonce doesn't bump gas price enough.
+	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrSenderLimit is returned when sender already has the maximum allowed
+	// number of transactions in the memory pool.
+	ErrSenderLimit = errors.New("too many transactions from sender in the memory pool")
+	// ErrQueueFull is returned when transaction with future nonce can't be
+	// queued because of the memory pool queue limits.
+	ErrQueueFull = errors.New("memory pool queue is full")
+	// ErrNonceTooLow is returned when transaction nonce is lower than the
+	// sender's nonce in the chain.
+	ErrNonceTooLow = errors.New("nonce too low")
 )
 var (
 	persistInterval = 1 * time.Second
@@ -151,6 +173,10 @@ type Blockchain struct {
 
 	stateRoot *stateroot.Module
 
+	// stateSync is the module fetching state at the latest state sync point
+	// from peers instead of processing all blocks from genesis.
+	stateSync *statesync.Module
+
 	// Notification subsystem.
 	events  chan bcEvent
 	subCh   chan interface{}
@@ -182,6 +208,14 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		cfg.MemPoolSize = defaultMemPoolSize
 		log.Info("mempool size is not set or wrong, setting default value", zap.Int("MemPoolSize", cfg.MemPoolSize))
 	}
+	if cfg.MemPoolQueueSize <= 0 {
+		cfg.MemPoolQueueSize = defaultMemPoolQueueSize
+		log.Info("mempool queue size is not set or wrong, setting default value", zap.Int("MemPoolQueueSize", cfg.MemPoolQueueSize))
+	}
+	if cfg.MemPoolQueuePerAccount <= 0 {
+		cfg.MemPoolQueuePerAccount = defaultMemPoolQueuePerAccount
+		log.Info("mempool queue per account is not set or wrong, setting default value", zap.Int("MemPoolQueuePerAccount", cfg.MemPoolQueuePerAccount))
+	}
 	if cfg.MaxBlockSize == 0 {
 		cfg.MaxBlockSize = defaultMaxBlockSize
 		log.Info("MaxBlockSize is not set or wrong, setting default value", zap.Uint32("MaxBlockSize", cfg.MaxBlockSize))
@@ -204,6 +238,10 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		cfg.GarbageCollectionPeriod = defaultGCPeriod
 		log.Info("GarbageCollectionPeriod is not set or wrong, using default value", zap.Uint32("GarbageCollectionPeriod", cfg.GarbageCollectionPeriod))
 	}
+	if cfg.P2PStateExchangeExtensions && cfg.StateSyncInterval == 0 {
+		cfg.StateSyncInterval = defaultStateSyncInterval
+		log.Info("StateSyncInterval is not set or wrong, using default value", zap.Uint32("StateSyncInterval", cfg.StateSyncInterval))
+	}
 	bc := &Blockchain{
 		config:      cfg,
 		dao:         dao.NewSimple(s),
@@ -219,7 +257,11 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		contracts:   *native.NewContracts(cfg),
 	}
 
+	bc.memPool.SetQueueLimits(cfg.MemPoolQueueSize, cfg.MemPoolQueuePerAccount, cfg.MemPoolQueueLifetime)
+	bc.memPool.SetAdmissionPolicy(cfg.MemPoolPriceBump, cfg.MemPoolMaxPerSender, cfg.MemPoolTxLifetime)
+	bc.memPool.SetAdmissionCheck(bc.PolicyCheck)
 	bc.stateRoot = stateroot.NewModule(bc.GetConfig(), bc.VerifyWitness, bc.log, bc.dao.Store)
+	bc.stateSync = statesync.NewModule(bc, bc.stateRoot, bc.log, bc.dao, bc.jumpToState)
 
 	if err := bc.init(); err != nil {
 		return nil, err
@@ -253,6 +295,13 @@ func (bc *Blockchain) init() error {
 		return bc.storeBlock(genesisBlock, nil)
 	}
 	if ver.Value != version {
+		_, err := migrations.Default.Plan(ver.Value, version)
+		switch {
+		case err == nil:
+			return fmt.Errorf("storage version %s is outdated (expected=%s), run `db migrate` to upgrade it", ver.Value, version)
+		case errors.Is(err, migrations.ErrNoPath):
+			return fmt.Errorf("storage version %s is outdated (expected=%s) and can't be migrated, resync is required", ver.Value, version)
+		}
 		return fmt.Errorf("storage version mismatch (expected=%s, actual=%s)", version, ver.Value)
 	}
 	if ver.KeepOnlyLatestState != bc.config.KeepOnlyLatestState {
@@ -313,6 +362,23 @@ func (bc *Blockchain) init() error {
 		}
 	}
 
+	// Continue the state jump if it was interrupted.
+	jumpStage, err := bc.dao.Store.Get([]byte{byte(storage.SYSStateJumpStage)})
+	if err == nil {
+		if !bc.config.P2PStateExchangeExtensions {
+			return errors.New("state jump was not completed, but P2PStateExchangeExtensions are disabled, " +
+				"drop the database manually and restart the node")
+		}
+		if len(jumpStage) != 1 {
+			return errors.New("invalid state jump stage format")
+		}
+		sr, err := bc.dao.GetStateSyncRoot()
+		if err != nil {
+			return fmt.Errorf("failed to get state sync root: %w", err)
+		}
+		return bc.jumpToStateInternal(sr, stateJumpStage(jumpStage[0]))
+	}
+
 	bHeight, err := bc.dao.GetCurrentBlockHeight()
 	if err != nil {
 		return fmt.Errorf("failed to retrieve current block height: %w", err)
@@ -619,6 +685,234 @@ func (bc *Blockchain) GetStateModule() blockchainer.StateRoot {
 	return bc.stateRoot
 }
 
+// GetStateSyncModule returns state sync service instance.
+func (bc *Blockchain) GetStateSyncModule() *statesync.Module {
+	return bc.stateSync
+}
+
+// stateJumpStage is the stage of the state jump, it's stored in the DB
+// so that an interrupted jump can be completed on the next start.
+type stateJumpStage byte
+
+const (
+	// stateJumpNone means that the state jump wasn't started yet.
+	stateJumpNone stateJumpStage = iota
+	// stateJumpStarted means that the restored state is checked, but
+	// contract storage items prefix wasn't switched yet.
+	stateJumpStarted
+	// newStorageItemsAdded means that restored contract storage items are
+	// the live ones now.
+	newStorageItemsAdded
+	// oldStorageItemsRemoved means that outdated contract storage items
+	// are removed.
+	oldStorageItemsRemoved
+)
+
+// jumpToState is called by the state sync module once all the state data
+// for the state sync point is fetched, it makes the restored state current.
+func (bc *Blockchain) jumpToState(sr *state.MPTRoot) error {
+	bc.addLock.Lock()
+	defer bc.addLock.Unlock()
+
+	return bc.jumpToStateInternal(sr, stateJumpNone)
+}
+
+// jumpToStateInternal performs the state jump starting from the given stage.
+func (bc *Blockchain) jumpToStateInternal(sr *state.MPTRoot, stage stateJumpStage) error {
+	p := sr.Index
+	if p >= uint32(len(bc.headerHashes)) {
+		return fmt.Errorf("invalid state sync point %d: headerHeight is %d", p, len(bc.headerHashes)-1)
+	}
+	bc.log.Info("jumping to state sync point", zap.Uint32("state sync point", p))
+
+	jumpStageKey := []byte{byte(storage.SYSStateJumpStage)}
+	switch stage {
+	case stateJumpNone:
+		if err := bc.verifyStateSyncRoot(sr); err != nil {
+			return err
+		}
+		bc.dao.Store.Put(jumpStageKey, []byte{byte(stateJumpStarted)})
+		fallthrough
+	case stateJumpStarted:
+		cache := bc.dao.GetPrivate()
+		v := cache.Version
+		v.StoragePrefix = statesync.TemporaryPrefix(v.StoragePrefix)
+		cache.PutVersion(v)
+		cache.Store.Put(jumpStageKey, []byte{byte(newStorageItemsAdded)})
+		if _, err := cache.Persist(); err != nil {
+			return fmt.Errorf("failed to switch storage items prefix: %w", err)
+		}
+		bc.dao.Version = v
+		bc.persistent.Version = v
+		fallthrough
+	case newStorageItemsAdded:
+		cache := bc.dao.GetPrivate()
+		prefix := statesync.TemporaryPrefix(bc.dao.Version.StoragePrefix)
+		bc.dao.Store.Seek(storage.SeekRange{Prefix: []byte{byte(prefix)}}, func(k, _ []byte) bool {
+			// #1468, but don't need to copy here, because it is done by Store.
+			cache.Store.Delete(k)
+			return true
+		})
+		cache.Store.Put(jumpStageKey, []byte{byte(oldStorageItemsRemoved)})
+		if _, err := cache.Persist(); err != nil {
+			return fmt.Errorf("failed to remove outdated storage items: %w", err)
+		}
+	case oldStorageItemsRemoved:
+		// Everything is done except for the in-memory state.
+	default:
+		return fmt.Errorf("unknown state jump stage: %d", stage)
+	}
+
+	header, err := bc.GetHeader(bc.GetHeaderHash(int(p)))
+	if err != nil {
+		return fmt.Errorf("failed to get header %d: %w", p, err)
+	}
+	// Block P itself is never fetched, so only its header is known.
+	blk := &block.Block{Header: *header}
+	bc.dao.StoreAsCurrentBlock(blk)
+	bc.topBlock.Store(blk)
+	bc.topBlockAer.Store(&types.Receipt{
+		BlockHash:   blk.Hash(),
+		BlockNumber: big.NewInt(int64(p)),
+		TxHash:      blk.Hash(),
+		Logs:        []*types.Log{},
+	})
+	atomic.StoreUint32(&bc.blockHeight, p)
+	atomic.StoreUint32(&bc.persistedHeight, p)
+	updateBlockHeightMetric(p)
+
+	bc.stateRoot.JumpToState(sr)
+	bc.dao.Store.Delete(jumpStageKey)
+
+	if err = bc.initializeNativeCache(p, bc.dao); err != nil {
+		return fmt.Errorf("can't init natives cache: %w", err)
+	}
+	nodes, index, err := bc.contracts.Designate.GetDesignatedByRole(bc.dao, noderoles.StateValidator, p)
+	if err != nil {
+		return fmt.Errorf("can't get state validators: %w", err)
+	}
+	bc.stateRoot.UpdateStateValidators(index, nodes)
+	return bc.updateExtensibleWhitelist(p)
+}
+
+// verifyStateSyncRoot checks that the state root restored by the state sync
+// module is signed by state validators designated in the restored state. The
+// root itself is accepted by the module only if it's signed by the trusted
+// state validators from the configuration, so it's a consistency check.
+func (bc *Blockchain) verifyStateSyncRoot(sr *state.MPTRoot) error {
+	d := bc.dao.GetPrivate()
+	d.Version.StoragePrefix = statesync.TemporaryPrefix(bc.dao.Version.StoragePrefix)
+	pubs, _, err := bc.contracts.Designate.GetDesignatedByRoleFromStorage(d, noderoles.StateValidator, sr.Index)
+	if err != nil {
+		return fmt.Errorf("can't get state validators from restored state: %w", err)
+	}
+	script, err := pubs.CreateDefaultMultiSigRedeemScript()
+	if err != nil || hash.Hash160(script) != sr.Witness.Address() {
+		return fmt.Errorf("%w: state root %d is not signed by state validators", statesync.ErrStateMismatch, sr.Index)
+	}
+	return nil
+}
+
+// txExecution is the result of transaction code execution.
+type txExecution struct {
+	netFee   uint64
+	left     uint64
+	refund   uint64
+	address  common.Address
+	coinbase common.Address
+	logs     []*types.Log
+	err      error
+}
+
+// executeTx executes transaction code, fees are paid by payTxFees.
+func (bc *Blockchain) executeTx(sdb *statedb.StateDB, block *block.Block, tx *transaction.Transaction) *txExecution {
+	bc.log.Debug("executing tx", zap.String("hash", tx.Hash().String()))
+	res := &txExecution{netFee: transaction.CalculateNetworkFee(tx, bc.FeePerByte())}
+	gas := tx.Gas() - res.netFee
+	ic, err := interop.NewContext(block, tx, sdb, bc, nil)
+	if err != nil {
+		panic(err)
+	}
+	vm := ic.VM
+	sdb.PrepareAccessList(tx.From(), tx.To(), evm.PrecompiledAddressesBerlin, tx.AccessList())
+	if tx.To() == nil {
+		_, res.address, res.left, res.err = vm.Create(ic, tx.Data(), gas, tx.Value())
+	} else {
+		_, res.left, res.err = vm.Call(ic, *tx.To(), tx.Data(), gas, tx.Value())
+	}
+	if res.err != nil {
+		bc.log.Debug("error when executing tx", zap.Uint32("block_index", block.Index),
+			zap.String("tx_hash", tx.Hash().String()),
+			zap.String("error", res.err.Error()))
+	}
+	res.logs = sdb.GetLogs()
+	res.refund = sdb.GetRefund()
+	res.coinbase = ic.Coinbase()
+	return res
+}
+
+// payTxFees increments sender nonce and pays transaction fees.
+func (bc *Blockchain) payTxFees(sdb *statedb.StateDB, block *block.Block, tx *transaction.Transaction, res *txExecution) {
+	gasPrice := tx.GasPrice()
+	gas := tx.Gas() - res.netFee
+	gasUsed := tx.Gas() - res.left
+	sdb.SetNonce(tx.From(), sdb.GetNonce(tx.From())+1)
+	if block.Index > 0 {
+		sdb.AddBalance(res.coinbase, big.NewInt(0).Mul(big.NewInt(int64(res.netFee)), gasPrice))
+	}
+	if gas > res.left {
+		commitAddress, err := bc.GetConsensusAddress()
+		if err != nil {
+			panic(err)
+		}
+		sdb.AddBalance(commitAddress, big.NewInt(0).Mul(big.NewInt(int64(gas-res.left)), gasPrice))
+	}
+	refund := res.refund
+	maxRefund := gasUsed / params.RefundQuotientEIP3529
+	if refund > maxRefund {
+		refund = maxRefund
+	}
+	sdb.SubBalance(tx.From(), big.NewInt(0).Mul(big.NewInt(int64(gasUsed-refund)), gasPrice))
+}
+
+// executeParallel executes block transactions optimistically in parallel,
+// onTx is called for every transaction in the block order. Results are the
+// same as for sequential execution.
+func (bc *Blockchain) executeParallel(cache *dao.Simple, block *block.Block, onTx func(int, *txExecution)) error {
+	var (
+		results    = make([]*txExecution, len(block.Transactions))
+		newStateDB = func(s storage.Store) (*dao.Simple, *statedb.StateDB) {
+			d := dao.NewSimple(s)
+			d.Version = cache.Version
+			return d, statedb.NewStateDB(d, bc)
+		}
+		persist = func(d *dao.Simple, sdb *statedb.StateDB) error {
+			if err := sdb.Commit(); err != nil {
+				return err
+			}
+			_, err := d.Persist()
+			return err
+		}
+	)
+	exec := func(i int, s storage.Store) error {
+		d, sdb := newStateDB(s)
+		results[i] = bc.executeTx(sdb, block, block.Transactions[i])
+		return persist(d, sdb)
+	}
+	finalize := func(i int, s storage.Store) error {
+		d, sdb := newStateDB(s)
+		bc.payTxFees(sdb, block, block.Transactions[i], results[i])
+		if err := persist(d, sdb); err != nil {
+			return err
+		}
+		onTx(i, results[i])
+		return nil
+	}
+	stats, err := blockstm.Run(cache.Store, len(block.Transactions), bc.config.ParallelExecutionWorkers, exec, finalize)
+	updateReexecutedTxsMetric(stats.Reexecuted)
+	return err
+}
+
 // storeBlock performs chain update using the block given, it executes all
 // transactions with all appropriate side-effects and updates Blockchain state.
 // This is the only way to change Blockchain state.
@@ -678,7 +972,6 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 
 	var (
 		err           error
-		execErr       error
 		logIndex      uint
 		cumulativeGas uint64
 		blockGasUsed  uint64
@@ -687,55 +980,12 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 	if err != nil {
 		return fmt.Errorf("onPersist failed: %w", err)
 	}
-	sdb := statedb.NewStateDB(cache, bc)
-	for i, tx := range block.Transactions {
-		bc.log.Debug("executing tx", zap.String("hash", tx.Hash().String()))
-		gasPrice := tx.GasPrice()
+	onTx := func(i int, res *txExecution) {
+		tx := block.Transactions[i]
 		blockGasUsed += tx.Gas()
-		netFee := transaction.CalculateNetworkFee(tx, bc.FeePerByte())
-		gas := tx.Gas() - netFee
-		ic, err := interop.NewContext(block, tx, sdb, bc, nil)
-		if err != nil {
-			panic(err)
-		}
-		vm := ic.VM
-		var (
-			left    uint64
-			address common.Address
-		)
-		sdb.PrepareAccessList(tx.From(), tx.To(), evm.PrecompiledAddressesBerlin, tx.AccessList())
-		if tx.To() == nil {
-			_, address, left, execErr = vm.Create(ic, tx.Data(), gas, tx.Value())
-		} else {
-			_, left, execErr = vm.Call(ic, *tx.To(), tx.Data(), gas, tx.Value())
-		}
-		if execErr != nil {
-			bc.log.Debug("error when executing tx", zap.Uint32("block_index", block.Index),
-				zap.String("tx_hash", tx.Hash().String()),
-				zap.String("error", execErr.Error()))
-		}
-		gasUsed := tx.Gas() - left
-		logs := sdb.GetLogs()
-		sdb.SetNonce(tx.From(), sdb.GetNonce(tx.From())+1)
-		if block.Index > 0 {
-			sdb.AddBalance(ic.Coinbase(), big.NewInt(0).Mul(big.NewInt(int64(netFee)), gasPrice))
-		}
-		if gas > left {
-			commitAddress, err := bc.GetConsensusAddress()
-			if err != nil {
-				panic(err)
-			}
-			sdb.AddBalance(commitAddress, big.NewInt(0).Mul(big.NewInt(int64(gas-left)), gasPrice))
-		}
-		refund := sdb.GetRefund()
-		maxRefund := gasUsed / params.RefundQuotientEIP3529
-		if refund > maxRefund {
-			refund = maxRefund
-		}
-		sdb.SubBalance(tx.From(), big.NewInt(0).Mul(big.NewInt(int64(gasUsed-refund)), gasPrice))
-		sdb.Commit()
+		gasUsed := tx.Gas() - res.left
 		cumulativeGas += gasUsed
-		for _, log := range logs {
+		for _, log := range res.logs {
 			log.BlockHash = block.Hash()
 			log.TxHash = tx.Hash()
 			log.TxIndex = uint(i)
@@ -748,12 +998,12 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 			TxHash:            tx.Hash(),
 			TransactionIndex:  uint(i),
 			GasUsed:           gasUsed,
-			ContractAddress:   address,
+			ContractAddress:   res.address,
 			CumulativeGasUsed: cumulativeGas,
-			Logs:              logs,
+			Logs:              res.logs,
 		}
 		aer.Bloom = types.BytesToBloom(types.LogsBloom(aer.Logs))
-		if execErr == nil {
+		if res.err == nil {
 			aer.Status = 1
 		}
 		//aer.PostState = []byte{byte(aer.Status)}
@@ -761,6 +1011,23 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 		appExecResults = append(appExecResults, aer)
 		aerchan <- aer
 	}
+	if bc.config.ParallelExecution && len(block.Transactions) > 1 {
+		err = bc.executeParallel(cache, block, onTx)
+		if err != nil {
+			// Release goroutines, don't care about errors, we already have one.
+			close(aerchan)
+			<-aerdone
+			return fmt.Errorf("parallel execution failed: %w", err)
+		}
+	} else {
+		sdb := statedb.NewStateDB(cache, bc)
+		for i, tx := range block.Transactions {
+			res := bc.executeTx(sdb, block, tx)
+			bc.payTxFees(sdb, block, tx, res)
+			sdb.Commit()
+			onTx(i, res)
+		}
+	}
 	err = bc.postPersist(cache, block)
 	if err != nil {
 		return fmt.Errorf("postPersist failed: %w", err)
@@ -826,7 +1093,7 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 	senders := bc.memPool.RemoveStale(func(tx *transaction.Transaction) bool { return bc.IsTxStillRelevant(tx, txpool, false) }, bc)
 	// refresh nonce for deleted items
 	for sender := range senders {
-		bc.memPool.RefreshNonce(sender, bc.GetNonce(sender))
+		bc.memPool.RefreshNonce(sender, bc.GetNonce(sender), bc)
 	}
 
 	for _, f := range bc.postBlock {
@@ -983,7 +1250,8 @@ func (bc *Blockchain) GetBlock(hash common.Hash, full bool) (*block.Block, *type
 	topBlock := bc.topBlock.Load()
 	if topBlock != nil {
 		tb := topBlock.(*block.Block)
-		if tb.Hash() == hash {
+		// Top block has no transactions after the state jump.
+		if tb.Hash() == hash && (len(tb.Transactions) != 0 || tb.MerkleRoot == (common.Hash{})) {
 			return tb, bc.topBlockAer.Load().(*types.Receipt), nil
 		}
 	}
@@ -1095,6 +1363,22 @@ func (bc *Blockchain) GetContractState(hash common.Address) *state.Contract {
 	return contract
 }
 
+// GetContractMetadata returns contract metadata, nil if it's not set.
+func (bc *Blockchain) GetContractMetadata(hash common.Address) *state.ContractMetadata {
+	return bc.contracts.Management.GetMetadata(bc.dao, hash)
+}
+
+// GetContractCreator returns the account which created the contract.
+func (bc *Blockchain) GetContractCreator(hash common.Address) (common.Address, bool) {
+	return bc.contracts.Management.GetCreator(bc.dao, hash)
+}
+
+// FindContracts returns addresses of contracts with metadata names starting
+// with the given prefix.
+func (bc *Blockchain) FindContracts(prefix string) []common.Address {
+	return bc.contracts.Management.FindContracts(bc.dao, prefix)
+}
+
 // GetNativeContractScriptHash returns native contract script hash by its name.
 func (bc *Blockchain) GetNativeContractScriptHash(name string) (common.Address, error) {
 	c := bc.contracts.ByName(name)
@@ -1114,6 +1398,12 @@ func (bc *Blockchain) GetConfig() config.ProtocolConfiguration {
 	return bc.config
 }
 
+// Backup writes a consistent snapshot of the persisted chain state to the
+// specified path, see dbbackup.Create for details.
+func (bc *Blockchain) Backup(path string) (*dbbackup.Manifest, error) {
+	return dbbackup.Create(bc.store, path)
+}
+
 // SubscribeForBlocks adds given channel to new block event broadcasting, so when
 // there is a new block added to the chain you'll receive it via this channel.
 // Make sure it's read from regularly as not reading these events might affect
@@ -1189,13 +1479,13 @@ func (bc *Blockchain) GetMemPool() *mempool.Pool {
 // ApplyPolicyToTxSet applies configured policies to given transaction set. It
 // expects slice to be ordered by fee and returns a subslice of it.
 func (bc *Blockchain) ApplyPolicyToTxSet(txes []*transaction.Transaction) []*transaction.Transaction {
-	maxTx := bc.config.MaxTransactionsPerBlock
+	maxTx := bc.GetMaxTransactionsPerBlock()
 	if maxTx != 0 && len(txes) > int(maxTx) {
 		txes = txes[:maxTx]
 	}
 	validators, _ := bc.contracts.Designate.GetValidators(bc.dao, bc.BlockHeight()+1)
-	maxBlockSize := bc.config.MaxBlockSize
-	maxBlockSysFee := bc.config.MaxBlockGas
+	maxBlockSize := bc.GetMaxBlockSize()
+	maxBlockSysFee := bc.GetMaxBlockGas()
 	oldVC := bc.knownValidatorsCount.Load()
 	defaultWitness := bc.defaultBlockWitness.Load()
 	curVC := len(validators)
@@ -1265,8 +1555,8 @@ func (bc *Blockchain) verifyAndPoolTx(t *transaction.Transaction, pool *mempool.
 	if err != nil {
 		return err
 	}
-	if t.Gas() > bc.config.MaxBlockGas {
-		return fmt.Errorf("gas exceeds block gas limit, limit: %d, actual: %d", bc.config.MaxBlockGas, t.Gas())
+	if maxGas := bc.GetMaxTxGas(); t.Gas() > maxGas {
+		return fmt.Errorf("gas exceeds transaction gas limit, limit: %d, actual: %d", maxGas, t.Gas())
 	}
 	if t.GasPrice().Cmp(bc.GetGasPrice()) < 0 {
 		return fmt.Errorf("gas price too low, expect %s, actual %s", bc.GetGasPrice(), t.GasPrice())
@@ -1307,12 +1597,19 @@ func (bc *Blockchain) verifyAndPoolTx(t *transaction.Transaction, pool *mempool.
 	if pool.GetDBNonce(from) == 0 {
 		pool.SetDBNonce(from, bc.GetNonce(from))
 	}
-	// we should allow user to replace tx with higher gas price, so allow all tx form db nonce to pending nonce
+	// we should allow user to replace tx with higher gas price, so allow all tx form db nonce to pending nonce,
+	// txs with future nonces are queued until the gap is filled
 	if !pool.CheckNonceContinue(t) {
-		return fmt.Errorf("invalid nonce, addr=%s, nonce=%d, pending nonce=%d", t.From(), t.Nonce(), pool.PendingNonce(from))
+		if t.Nonce() < pool.GetDBNonce(from) {
+			return fmt.Errorf("%w: addr=%s, nonce=%d, expected nonce=%d", ErrNonceTooLow, t.From(), t.Nonce(), pool.GetDBNonce(from))
+		}
+		if !pool.IsQueueEnabled() {
+			return fmt.Errorf("invalid nonce, addr=%s, nonce=%d, pending nonce=%d", t.From(), t.Nonce(), pool.PendingNonce(from))
+		}
+		err = pool.Enqueue(t, feer, data...)
+	} else {
+		err = pool.Add(t, feer, data...)
 	}
-
-	err = pool.Add(t, feer, data...)
 	if err != nil {
 		switch {
 		case errors.Is(err, mempool.ErrConflict):
@@ -1323,8 +1620,16 @@ func (bc *Blockchain) verifyAndPoolTx(t *transaction.Transaction, pool *mempool.
 			return ErrInsufficientFunds
 		case errors.Is(err, mempool.ErrOOM):
 			return ErrOOM
+		case errors.Is(err, mempool.ErrReplaceUnderpriced):
+			return fmt.Errorf("mempool: %w", ErrReplaceUnderpriced)
+		case errors.Is(err, mempool.ErrSenderLimit):
+			return fmt.Errorf("mempool: %w", ErrSenderLimit)
 		case errors.Is(err, mempool.ErrConflictsNonce):
 			return fmt.Errorf("mempool: %w: %s", ErrHasConflicts, err)
+		case errors.Is(err, mempool.ErrQueueFull):
+			return fmt.Errorf("mempool: %w", ErrQueueFull)
+		case errors.Is(err, mempool.ErrNonceTooLow):
+			return fmt.Errorf("mempool: %w", ErrNonceTooLow)
 		default:
 			return err
 		}
@@ -1342,6 +1647,9 @@ func (bc *Blockchain) PolicyCheck(t *transaction.Transaction) error {
 		bc.contracts.Policy.IsBlocked(bc.dao, *t.To()) {
 		return native.ErrContractBlocked
 	}
+	if t.To() == nil && !bc.contracts.Policy.IsDeployAllowed(bc.dao, t.From()) {
+		return native.ErrDeployNotAllowed
+	}
 	return nil
 }
 
@@ -1417,10 +1725,21 @@ func (bc *Blockchain) GetCurrentValidators() ([]*keys.PublicKey, error) {
 	return bc.contracts.Designate.GetValidators(bc.dao, bc.BlockHeight()+1)
 }
 
+// GetDesignatedByRole returns nodes designated for the role at the given
+// index along with the height they were designated at.
+func (bc *Blockchain) GetDesignatedByRole(r noderoles.Role, index uint32) (keys.PublicKeys, uint32, error) {
+	return bc.contracts.Designate.GetDesignatedByRole(bc.dao, r, index)
+}
+
 func (bc *Blockchain) IsBlocked(address common.Address) bool {
 	return bc.contracts.Policy.IsBlocked(bc.dao, address)
 }
 
+// IsDeployAllowed returns true if the address can deploy a contract.
+func (bc *Blockchain) IsDeployAllowed(address common.Address) bool {
+	return bc.contracts.Policy.IsDeployAllowed(bc.dao, address)
+}
+
 // GetTestVM returns an interop context with VM set up for a test run.
 func (bc *Blockchain) GetTestVM(tx *transaction.Transaction, b *block.Block, tracer vm.EVMLogger) (*interop.Context, error) {
 	cache := bc.dao.GetPrivate()
@@ -1488,6 +1807,52 @@ func (bc *Blockchain) GetGasPrice() *big.Int {
 	return bc.contracts.Policy.GetGasPrice(bc.dao)
 }
 
+// GetMaxBlockSize returns the maximum block size for the next block, Policy
+// override takes precedence over protocol configuration.
+func (bc *Blockchain) GetMaxBlockSize() uint32 {
+	if size := bc.contracts.Policy.GetMaxBlockSize(bc.dao); size != 0 {
+		return size
+	}
+	return bc.config.MaxBlockSize
+}
+
+// GetMaxBlockGas returns the maximum block gas for the next block, Policy
+// override takes precedence over protocol configuration.
+func (bc *Blockchain) GetMaxBlockGas() uint64 {
+	if gas := bc.contracts.Policy.GetMaxBlockGas(bc.dao); gas != 0 {
+		return gas
+	}
+	return bc.config.MaxBlockGas
+}
+
+// GetMaxTransactionsPerBlock returns the maximum number of transactions in
+// the next block, Policy override takes precedence over protocol
+// configuration.
+func (bc *Blockchain) GetMaxTransactionsPerBlock() uint16 {
+	if count := bc.contracts.Policy.GetMaxTransactionsPerBlock(bc.dao); count != 0 {
+		return count
+	}
+	return bc.config.MaxTransactionsPerBlock
+}
+
+// GetMaxTxGas returns the maximum gas of a single transaction, it never
+// exceeds the maximum block gas.
+func (bc *Blockchain) GetMaxTxGas() uint64 {
+	maxBlockGas := bc.GetMaxBlockGas()
+	if gas := bc.contracts.Policy.GetMaxTxGas(bc.dao); gas != 0 && gas < maxBlockGas {
+		return gas
+	}
+	return maxBlockGas
+}
+
+// GetMaxContractSize returns the maximum size of deployed contract code.
+func (bc *Blockchain) GetMaxContractSize() int {
+	if size := bc.contracts.Policy.GetMaxContractSize(bc.dao); size != 0 {
+		return size
+	}
+	return params.MaxCodeSize
+}
+
 func (bc *Blockchain) Contracts() *native.Contracts {
 	return &bc.contracts
 }
@@ -1546,3 +1911,8 @@ func (bc *Blockchain) GetLogs(filter *filters.LogFilter) ([]*types.Log, error) {
 func (bc *Blockchain) GetMinted(id int64) (common.Hash, error) {
 	return bc.contracts.Bridge.GetMinted(bc.dao, id)
 }
+
+// GetOracleRequests returns all pending oracle requests.
+func (bc *Blockchain) GetOracleRequests() (map[uint64]*state.OracleRequest, error) {
+	return bc.contracts.Oracle.GetRequests(bc.dao)
+}
@@ -5,6 +5,10 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/wallet"
@@ -13,6 +17,8 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestSign1(t *testing.T) {
@@ -80,3 +86,167 @@ func TestSign2(t *testing.T) {
 	assert.NoError(t, err)
 	t.Log(addr)
 }
+
+// Contracts used by parallel execution tests.
+var (
+	// counterCode increments the storage slot 0 on every call.
+	counterCode = mkInitCode(nil, []byte{0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00})
+	// loggerCode stores the block number by the caller address and emits a
+	// log with the caller address topic.
+	loggerCode = mkInitCode(nil, []byte{0x43, 0x33, 0x55, 0x33, 0x60, 0x00, 0x60, 0x00, 0xa1, 0x00})
+	// clearCode sets the storage slot 0 in constructor and clears it on
+	// call producing gas refund.
+	clearCode = mkInitCode([]byte{0x60, 0x01, 0x60, 0x00, 0x55}, []byte{0x60, 0x00, 0x60, 0x00, 0x55, 0x00})
+	// revertCode always reverts.
+	revertCode = mkInitCode(nil, []byte{0x60, 0x00, 0x60, 0x00, 0xfd})
+)
+
+// mkInitCode returns contract init code executing constructor and returning
+// the runtime code.
+func mkInitCode(constructor []byte, runtime []byte) []byte {
+	offset := len(constructor) + 12
+	code := append([]byte{}, constructor...)
+	code = append(code, 0x60, byte(len(runtime)), 0x60, byte(offset), 0x60, 0x00, 0x39,
+		0x60, byte(len(runtime)), 0x60, 0x00, 0xf3)
+	return append(code, runtime...)
+}
+
+func newTestChain(t *testing.T, parallel bool, key *keys.PrivateKey) *Blockchain {
+	cfg := config.ProtocolConfiguration{
+		ChainID:                  253,
+		InitialGASSupply:         100000000,
+		MaxBlockGas:              250000000,
+		MaxTraceableBlocks:       200000,
+		MaxTransactionsPerBlock:  512,
+		StandbyValidatorsStr:     []string{hex.EncodeToString(key.PublicKey().Bytes())},
+		ParallelExecution:        parallel,
+		ParallelExecutionWorkers: 4,
+	}
+	require.NoError(t, cfg.Validate())
+	bc, err := NewBlockchain(storage.NewMemoryStore(), cfg, zap.NewNop())
+	require.NoError(t, err)
+	go bc.Run()
+	t.Cleanup(bc.Close)
+	return bc
+}
+
+type testTxBuilder struct {
+	nonces map[common.Address]uint64
+}
+
+func (b *testTxBuilder) tx(from common.Address, to *common.Address, value *big.Int, data []byte) *transaction.Transaction {
+	nonce := b.nonces[from]
+	b.nonces[from]++
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return transaction.NewTx(&transaction.NeoTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(int64(native.DefaultGasPrice)),
+		Gas:      2000000,
+		From:     from,
+		To:       to,
+		Value:    value,
+		Data:     data,
+		Witness: transaction.Witness{
+			InvocationScript:   []byte{0},
+			VerificationScript: []byte{0},
+		},
+	})
+}
+
+func TestParallelExecutionDeterminism(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	chains := []*Blockchain{newTestChain(t, false, key), newTestChain(t, true, key)}
+
+	var (
+		owner    = key.PublicKey().Address()
+		accounts = make([]common.Address, 32)
+		gas      = big.NewInt(0).Exp(big.NewInt(10), big.NewInt(18), nil)
+		b        = &testTxBuilder{nonces: make(map[common.Address]uint64)}
+	)
+	for i := range accounts {
+		accounts[i] = common.BytesToAddress([]byte{0xaa, byte(i)})
+	}
+	var (
+		counter   = crypto.CreateAddress(owner, 0)
+		logger    = crypto.CreateAddress(owner, 1)
+		clear     = crypto.CreateAddress(owner, 2)
+		reverting = crypto.CreateAddress(owner, 3)
+	)
+	blocks := [][]*transaction.Transaction{{
+		b.tx(owner, nil, nil, counterCode),
+		b.tx(owner, nil, nil, loggerCode),
+		b.tx(owner, nil, nil, clearCode),
+		b.tx(owner, nil, nil, revertCode),
+	}}
+	var funding []*transaction.Transaction
+	for i := range accounts {
+		funding = append(funding, b.tx(owner, &accounts[i], gas, nil))
+	}
+	blocks = append(blocks, funding)
+	for round := 0; round < 3; round++ {
+		var txs []*transaction.Transaction
+		for i, acc := range accounts {
+			next := accounts[(i+round+1)%len(accounts)]
+			switch (i + round) % 7 {
+			case 0:
+				txs = append(txs, b.tx(acc, &next, big.NewInt(1000), nil))
+			case 1:
+				txs = append(txs, b.tx(acc, &counter, nil, nil))
+			case 2:
+				txs = append(txs, b.tx(acc, &logger, nil, nil))
+			case 3:
+				txs = append(txs, b.tx(acc, &clear, nil, nil))
+			case 4:
+				txs = append(txs, b.tx(acc, &reverting, nil, nil))
+			case 5:
+				txs = append(txs, b.tx(acc, nil, nil, counterCode))
+			case 6:
+				// Not enough funds.
+				txs = append(txs, b.tx(acc, &next, big.NewInt(0).Mul(gas, big.NewInt(2)), nil))
+			}
+			// Independent transfers.
+			txs = append(txs, b.tx(acc, &common.Address{0xbb, byte(i)}, big.NewInt(1), nil))
+		}
+		blocks = append(blocks, txs)
+	}
+
+	var statuses = make(map[uint64]int)
+	for _, txs := range blocks {
+		prev := chains[0].GetHeaderHash(int(chains[0].BlockHeight()))
+		blk := &block.Block{
+			Header: block.Header{
+				PrevHash:  prev,
+				Timestamp: uint64(chains[0].BlockHeight()) + 1,
+				Index:     chains[0].BlockHeight() + 1,
+			},
+			Transactions: txs,
+		}
+		blk.RebuildMerkleRoot()
+		for _, bc := range chains {
+			require.NoError(t, bc.AddBlock(blk))
+		}
+		require.Equal(t, chains[0].GetStateModule().CurrentLocalStateRoot(), chains[1].GetStateModule().CurrentLocalStateRoot())
+		_, aer, err := chains[0].GetBlock(blk.Hash(), false)
+		require.NoError(t, err)
+		_, paer, err := chains[1].GetBlock(blk.Hash(), false)
+		require.NoError(t, err)
+		require.Equal(t, aer, paer)
+		for _, tx := range txs {
+			_, r, err := chains[0].GetTransaction(tx.Hash())
+			require.NoError(t, err)
+			_, pr, err := chains[1].GetTransaction(tx.Hash())
+			require.NoError(t, err)
+			require.Equal(t, r, pr)
+			statuses[r.Status]++
+		}
+	}
+	// Both successful and failed transactions are executed.
+	require.NotZero(t, statuses[0])
+	require.NotZero(t, statuses[1])
+	cnt := chains[0].dao.GetStorageItem(counter, common.Hash{}.Bytes())
+	require.NotNil(t, cnt)
+	require.Equal(t, cnt, chains[1].dao.GetStorageItem(counter, common.Hash{}.Bytes()))
+}
@@ -5,10 +5,12 @@ import (
 
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dbbackup"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/filters"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/interop"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
@@ -25,6 +27,7 @@ type Blockchainer interface {
 	AddBlock(block *block.Block) error
 	AddHeaders(...*block.Header) error
 	BlockHeight() uint32
+	Backup(path string) (*dbbackup.Manifest, error)
 	GetConfig() config.ProtocolConfiguration
 	Close()
 	Contracts() *native.Contracts
@@ -33,7 +36,11 @@ type Blockchainer interface {
 	GetBlock(hash common.Hash, full bool) (*block.Block, *types.Receipt, error)
 	GetConsensusAddress() (common.Address, error)
 	GetContractState(hash common.Address) *state.Contract
+	GetContractMetadata(hash common.Address) *state.ContractMetadata
+	GetContractCreator(hash common.Address) (common.Address, bool)
+	FindContracts(prefix string) []common.Address
 	IsBlocked(common.Address) bool
+	IsDeployAllowed(common.Address) bool
 	GetHeaderHash(int) common.Hash
 	GetHeader(hash common.Hash) (*block.Header, error)
 	CurrentHeaderHash() common.Hash
@@ -45,6 +52,7 @@ type Blockchainer interface {
 	GetNatives() []state.NativeContract
 	GetValidators(uint32) ([]*keys.PublicKey, error)
 	GetCurrentValidators() ([]*keys.PublicKey, error)
+	GetDesignatedByRole(r noderoles.Role, index uint32) (keys.PublicKeys, uint32, error)
 	GetStateModule() StateRoot
 	GetStorageItem(hash common.Address, key []byte) state.StorageItem
 	GetStorageItems(hash common.Address) ([]state.StorageItemWithKey, error)
@@ -66,6 +74,8 @@ type Blockchainer interface {
 	UnsubscribeFromNotifications(ch chan<- *types.Log)
 	UnsubscribeFromTransactions(ch chan<- *transaction.Transaction)
 	GetFeePerByte() uint64
+	GetMaxBlockGas() uint64
+	GetMaxTransactionsPerBlock() uint16
 	GetGasPrice() *big.Int
 	GetNonce(addr common.Address) uint64
 	GetPendingNonce(addr common.Address) uint64
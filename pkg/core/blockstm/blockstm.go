@@ -0,0 +1,172 @@
+/*
+Package blockstm implements optimistic parallel execution of block
+transactions in the style of Block-STM.
+
+Transactions are executed speculatively in parallel against the committed
+state, every execution records the keys it has read and the changes it has
+made. Executions are then validated and committed in the block order: if any
+key read by a transaction was changed by the preceding transactions, the
+transaction is re-executed against the current state. This way the resulting
+state and transaction results are the same as for sequential execution.
+*/
+package blockstm
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"go.uber.org/atomic"
+)
+
+// Func executes transaction i against store s. It's called concurrently for
+// different transactions and it can be called several times for the same
+// transaction, so it must not have side effects other than changes made to s
+// and the results of transaction i.
+type Func func(i int, s storage.Store) error
+
+// Stats contains execution statistics.
+type Stats struct {
+	// Reexecuted is the number of transactions re-executed because of
+	// conflicts with the preceding transactions.
+	Reexecuted int
+}
+
+// executor keeps the committed state of the block being executed.
+type executor struct {
+	// state contains changes of all committed transactions, it's safe for
+	// concurrent use.
+	state *storage.MemCachedStore
+	// version is the number of committed transactions.
+	version atomic.Int32
+	// written contains all keys changed by the committed transactions and
+	// writes contains keys changed by every committed transaction. They're
+	// only accessed by the committer.
+	written map[string]struct{}
+	writes  [][]string
+}
+
+// Run executes n transactions using exec with the given number of workers
+// (GOMAXPROCS if not positive) and commits their changes to the store in
+// order. finalize is called by the committer for every transaction right
+// after its execution is committed, it's never executed speculatively and
+// its changes are committed along with the transaction ones. The store is
+// only modified if no error is returned.
+func Run(store storage.Store, n, workers int, exec, finalize Func) (Stats, error) {
+	var (
+		stats   Stats
+		e       = newExecutor(store, n)
+		results = make([]chan *overlay, n)
+		next    atomic.Int32
+		stop    atomic.Bool
+		wg      sync.WaitGroup
+	)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	for i := range results {
+		results[i] = make(chan *overlay, 1)
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				i := int(next.Inc()) - 1
+				if i >= n {
+					return
+				}
+				results[i] <- e.speculate(i, exec)
+			}
+		}()
+	}
+	defer func() {
+		stop.Store(true)
+		wg.Wait()
+	}()
+
+	for i := 0; i < n; i++ {
+		o := <-results[i]
+		if o == nil || !e.validate(o) {
+			stats.Reexecuted++
+			o = e.newOverlay()
+			if err := exec(i, o); err != nil {
+				return stats, err
+			}
+		}
+		keys := e.apply(o, nil)
+		o = e.newOverlay()
+		if err := finalize(i, o); err != nil {
+			return stats, err
+		}
+		e.writes = append(e.writes, e.apply(o, keys))
+		e.version.Inc()
+	}
+	_, err := e.state.Persist()
+	return stats, err
+}
+
+func newExecutor(store storage.Store, n int) *executor {
+	return &executor{
+		state:   storage.NewMemCachedStore(store),
+		written: make(map[string]struct{}),
+		writes:  make([][]string, 0, n),
+	}
+}
+
+// speculate executes transaction i against the current state. It returns nil
+// if the execution has failed, such transactions are re-executed by the
+// committer. Panics are caught here too since speculative execution can
+// observe an inconsistent state, real problems are reported on re-execution.
+func (e *executor) speculate(i int, exec Func) (o *overlay) {
+	defer func() {
+		if r := recover(); r != nil {
+			o = nil
+		}
+	}()
+	o = e.newOverlay()
+	if err := exec(i, o); err != nil {
+		return nil
+	}
+	return o
+}
+
+// validate checks that the execution has read the same values as it would
+// read if it was executed after all the committed transactions.
+func (e *executor) validate(o *overlay) bool {
+	for k, r := range o.reads {
+		if _, ok := e.written[k]; !ok {
+			continue
+		}
+		v, err := e.state.Get([]byte(k))
+		if exists := err == nil; exists != r.exists || exists && string(v) != string(r.value) {
+			return false
+		}
+	}
+	for _, s := range o.seeks {
+		for _, keys := range e.writes[s.version:] {
+			for _, k := range keys {
+				if inRange(s.rng, k) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// apply commits changes made by the execution to the state. Changed keys are
+// appended to keys and the result is returned.
+func (e *executor) apply(o *overlay, keys []string) []string {
+	for _, m := range []map[string][]byte{o.puts, o.stor} {
+		for k := range m {
+			e.written[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	_ = e.state.PutChangeSet(o.puts, o.stor)
+	return keys
+}
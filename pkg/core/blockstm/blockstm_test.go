@@ -0,0 +1,186 @@
+package blockstm
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccounts = 16
+
+var (
+	counterKey  = []byte{byte(storage.STStorage), 0xff}
+	coinbaseKey = []byte{byte(storage.STStorage), 0xfe}
+)
+
+func accountKey(a byte) []byte {
+	return []byte{byte(storage.STStorage), 0, a}
+}
+
+func getUint(s storage.Store, key []byte) uint64 {
+	v, err := s.Get(key)
+	if err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+func putUint(s storage.Store, key []byte, n uint64) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, n)
+	_ = s.Put(key, v)
+}
+
+// testTx is a synthetic transaction, kind defines the way it accesses the
+// state.
+type testTx struct {
+	kind     int
+	from, to byte
+}
+
+// testBlock returns exec and finalize functions for the set of random
+// transactions, transaction results are stored into res.
+func testBlock(r *rand.Rand, n int, res []uint64) (Func, Func) {
+	txs := make([]testTx, n)
+	for i := range txs {
+		txs[i] = testTx{
+			kind: r.Intn(5),
+			from: byte(r.Intn(testAccounts)),
+			to:   byte(r.Intn(testAccounts)),
+		}
+	}
+	exec := func(i int, s storage.Store) error {
+		tx := txs[i]
+		c := storage.NewMemCachedStore(s)
+		switch tx.kind {
+		case 0: // Transfer.
+			from := getUint(c, accountKey(tx.from))
+			if from == 0 {
+				res[i] = 0
+				break
+			}
+			putUint(c, accountKey(tx.from), from-1)
+			putUint(c, accountKey(tx.to), getUint(c, accountKey(tx.to))+1)
+			res[i] = from - 1
+		case 1: // Shared counter.
+			res[i] = getUint(c, counterKey) + 1
+			putUint(c, counterKey, res[i])
+		case 2: // Sum of all balances.
+			var sum uint64
+			c.Seek(storage.SeekRange{Prefix: accountKey(0)[:2]}, func(k, v []byte) bool {
+				sum += binary.BigEndian.Uint64(v)
+				return true
+			})
+			res[i] = sum
+			putUint(c, []byte{byte(storage.DataMPT), byte(i)}, sum)
+		case 3: // Read-only.
+			res[i] = getUint(c, accountKey(tx.from)) + getUint(c, coinbaseKey)
+		case 4: // Account removal.
+			res[i] = getUint(c, accountKey(tx.from))
+			c.Delete(accountKey(tx.from))
+			putUint(c, coinbaseKey, getUint(c, coinbaseKey)+res[i])
+		}
+		_, err := c.Persist()
+		return err
+	}
+	finalize := func(i int, s storage.Store) error {
+		c := storage.NewMemCachedStore(s)
+		putUint(c, coinbaseKey, getUint(c, coinbaseKey)+1)
+		_, err := c.Persist()
+		return err
+	}
+	return exec, finalize
+}
+
+func newTestState() *storage.MemCachedStore {
+	s := storage.NewPrivateMemCachedStore(storage.NewMemoryStore())
+	for a := byte(0); a < testAccounts; a++ {
+		putUint(s, accountKey(a), 3)
+	}
+	return s
+}
+
+func dump(s storage.Store) map[string]string {
+	res := make(map[string]string)
+	for _, p := range []storage.KeyPrefix{storage.STStorage, storage.DataMPT} {
+		s.Seek(storage.SeekRange{Prefix: []byte{byte(p)}}, func(k, v []byte) bool {
+			res[string(k)] = string(v)
+			return true
+		})
+	}
+	return res
+}
+
+func TestRunDeterminism(t *testing.T) {
+	const n = 200
+	var reexecuted int
+	for seed := int64(0); seed < 10; seed++ {
+		for _, workers := range []int{1, 2, 8, 0} {
+			expected, seqRes := newTestState(), make([]uint64, n)
+			exec, finalize := testBlock(rand.New(rand.NewSource(seed)), n, seqRes)
+			for i := 0; i < n; i++ {
+				require.NoError(t, exec(i, expected))
+				require.NoError(t, finalize(i, expected))
+			}
+
+			actual, res := newTestState(), make([]uint64, n)
+			exec, finalize = testBlock(rand.New(rand.NewSource(seed)), n, res)
+			stats, err := Run(actual, n, workers, exec, finalize)
+			require.NoError(t, err)
+			require.True(t, stats.Reexecuted <= n)
+			reexecuted += stats.Reexecuted
+			require.Equal(t, seqRes, res, "seed %d, workers %d", seed, workers)
+			require.Equal(t, dump(expected), dump(actual), "seed %d, workers %d", seed, workers)
+			require.Equal(t, expected.GetStorageChanges(), actual.GetStorageChanges())
+		}
+	}
+	// Conflicting transactions are re-executed, the others are committed
+	// after speculative execution.
+	require.NotZero(t, reexecuted)
+	require.Less(t, reexecuted, 10*4*n)
+}
+
+func TestRunReexecution(t *testing.T) {
+	var (
+		s        = newTestState()
+		attempts = make([]int, 4)
+		testErr  = errors.New("failed")
+	)
+	exec := func(i int, s storage.Store) error {
+		attempts[i]++
+		switch {
+		case i == 1 && attempts[i] == 1:
+			panic("speculative panic")
+		case i == 2 && attempts[i] == 1:
+			return testErr
+		}
+		putUint(s, accountKey(byte(i)), uint64(i))
+		return nil
+	}
+	finalize := func(i int, s storage.Store) error { return nil }
+	stats, err := Run(s, 4, 2, exec, finalize)
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.Reexecuted)
+	require.Equal(t, []int{1, 2, 2, 1}, attempts)
+	for i := byte(0); i < 4; i++ {
+		require.Equal(t, uint64(i), getUint(s, accountKey(i)))
+	}
+
+	// Errors of re-execution are returned and the store is not changed.
+	s = newTestState()
+	before := dump(s)
+	exec = func(i int, s storage.Store) error {
+		putUint(s, accountKey(byte(i)), 100)
+		if i == 2 {
+			return testErr
+		}
+		return nil
+	}
+	_, err = Run(s, 4, 2, exec, finalize)
+	require.ErrorIs(t, err, testErr)
+	require.Equal(t, before, dump(s))
+}
@@ -0,0 +1,147 @@
+package blockstm
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/util/slice"
+)
+
+// errSeekGC is returned by overlay.SeekGC.
+var errSeekGC = errors.New("SeekGC is not supported by transaction overlay")
+
+type (
+	// overlay is a Store used for a single transaction execution. It reads
+	// the committed state and records the read set, changes persisted into
+	// overlay are kept as the write set and are not visible to other
+	// transactions until committed.
+	overlay struct {
+		e *executor
+
+		lock  sync.Mutex
+		reads map[string]readValue
+		seeks []seekRead
+		puts  map[string][]byte
+		stor  map[string][]byte
+	}
+
+	// readValue is the value of the key read from the committed state.
+	readValue struct {
+		value  []byte
+		exists bool
+	}
+
+	// seekRead is a range of keys read from the committed state after
+	// version transactions were committed.
+	seekRead struct {
+		rng     storage.SeekRange
+		version int32
+	}
+)
+
+func (e *executor) newOverlay() *overlay {
+	return &overlay{
+		e:     e,
+		reads: make(map[string]readValue),
+		puts:  make(map[string][]byte),
+		stor:  make(map[string][]byte),
+	}
+}
+
+// Get implements the Store interface. Every key is read from the committed
+// state only once, so the execution observes the same value of the key even
+// if it's changed by the committer.
+func (o *overlay) Get(key []byte) ([]byte, error) {
+	o.lock.Lock()
+	r, ok := o.reads[string(key)]
+	o.lock.Unlock()
+	if !ok {
+		v, err := o.e.state.Get(key)
+		r = readValue{value: v, exists: err == nil}
+		if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, err
+		}
+		o.lock.Lock()
+		if prev, ok := o.reads[string(key)]; ok {
+			r = prev
+		} else {
+			o.reads[string(key)] = r
+		}
+		o.lock.Unlock()
+	}
+	if !r.exists {
+		return nil, storage.ErrKeyNotFound
+	}
+	return r.value, nil
+}
+
+// Put implements the Store interface.
+func (o *overlay) Put(key, value []byte) error {
+	o.lock.Lock()
+	o.chooseMap(key)[string(key)] = slice.Copy(value)
+	o.lock.Unlock()
+	return nil
+}
+
+// PutChangeSet implements the Store interface, changes are added to the
+// write set.
+func (o *overlay) PutChangeSet(puts map[string][]byte, stor map[string][]byte) error {
+	o.lock.Lock()
+	for k, v := range puts {
+		o.puts[k] = v
+	}
+	for k, v := range stor {
+		o.stor[k] = v
+	}
+	o.lock.Unlock()
+	return nil
+}
+
+// Seek implements the Store interface. The whole range is recorded as read
+// irrespective of the number of items actually consumed by f.
+func (o *overlay) Seek(rng storage.SeekRange, f func(k, v []byte) bool) {
+	rng.Prefix = slice.Copy(rng.Prefix)
+	rng.Start = slice.Copy(rng.Start)
+	o.lock.Lock()
+	// Version is taken before the seek, so changes committed concurrently
+	// are checked on validation.
+	o.seeks = append(o.seeks, seekRead{rng: rng, version: o.e.version.Load()})
+	o.lock.Unlock()
+	o.e.state.Seek(rng, f)
+}
+
+// SeekGC implements the Store interface, it's not supported.
+func (o *overlay) SeekGC(rng storage.SeekRange, keep func(k, v []byte) bool) error {
+	return errSeekGC
+}
+
+// Close implements the Store interface.
+func (o *overlay) Close() error {
+	return nil
+}
+
+func (o *overlay) chooseMap(key []byte) map[string][]byte {
+	switch storage.KeyPrefix(key[0]) {
+	case storage.STStorage, storage.STTempStorage:
+		return o.stor
+	default:
+		return o.puts
+	}
+}
+
+// inRange returns true if the key matches the seek range.
+func inRange(rng storage.SeekRange, key string) bool {
+	if !strings.HasPrefix(key, string(rng.Prefix)) {
+		return false
+	}
+	if len(rng.Start) == 0 {
+		return true
+	}
+	cmp := strings.Compare(key[len(rng.Prefix):], string(rng.Start))
+	if rng.Backwards {
+		return cmp <= 0
+	}
+	return cmp >= 0
+}
@@ -186,6 +186,20 @@ func (dao *Simple) DeleteBlock(h common.Hash) error {
 	return nil
 }
 
+// PurgeBlock removes block or header with the given hash from dao along
+// with its transactions and their receipts.
+func (dao *Simple) PurgeBlock(h common.Hash) error {
+	b, _, err := dao.getBlock(dao.makeBlockKey(h))
+	if err != nil {
+		return err
+	}
+	dao.Store.Delete(dao.makeBlockKey(h))
+	for _, tx := range b.Transactions {
+		dao.Store.Delete(dao.makeTxKey(tx.Hash()))
+	}
+	return nil
+}
+
 // -- end block
 
 // -- start notification event.
@@ -448,6 +462,32 @@ func (dao *Simple) GetStateSyncPoint() (uint32, error) {
 	return binary.LittleEndian.Uint32(b), nil
 }
 
+// PutStateSyncPoint stores current state synchronisation point P.
+func (dao *Simple) PutStateSyncPoint(p uint32) {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, p)
+	dao.Store.Put(dao.mkKeyPrefix(storage.SYSStateSyncPoint), buf)
+}
+
+// GetStateSyncRoot returns signed state root for the current state
+// synchronisation point.
+func (dao *Simple) GetStateSyncRoot() (*state.MPTRoot, error) {
+	sr := new(state.MPTRoot)
+	err := dao.GetAndDecode(sr, dao.mkKeyPrefix(storage.SYSStateSyncRoot))
+	if err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// PutStateSyncRoot stores signed state root for the current state
+// synchronisation point.
+func (dao *Simple) PutStateSyncRoot(sr *state.MPTRoot) {
+	buf := dao.getDataBuf()
+	sr.EncodeBinary(buf.BinWriter)
+	dao.Store.Put(dao.mkKeyPrefix(storage.SYSStateSyncRoot), buf.Bytes())
+}
+
 // GetHeaderHashes returns a sorted list of header hashes retrieved from
 // the given underlying store.
 func (dao *Simple) GetHeaderHashes() ([]common.Hash, error) {
@@ -519,6 +559,45 @@ func (dao *Simple) StoreHeaderHashes(hashes []common.Hash, height uint32) error
 	return nil
 }
 
+// DeleteHeaderHashes removes batches of header hashes containing hashes of
+// headers above the specified height.
+func (dao *Simple) DeleteHeaderHashes(height uint32) error {
+	var (
+		keys    [][]byte
+		seekErr error
+	)
+	dao.Store.Seek(storage.SeekRange{
+		Prefix: dao.mkKeyPrefix(storage.IXHeaderHashList),
+	}, func(k, v []byte) bool {
+		if len(k) != 5 {
+			return true
+		}
+		count, err := readHashCount(v)
+		if err != nil {
+			seekErr = fmt.Errorf("failed to read batch of header hashes: %w", err)
+			return false
+		}
+		if uint64(binary.BigEndian.Uint32(k[1:]))+count > uint64(height)+1 {
+			keys = append(keys, slice.Copy(k))
+		}
+		return true
+	})
+	if seekErr != nil {
+		return seekErr
+	}
+	for _, k := range keys {
+		dao.Store.Delete(k)
+	}
+	return nil
+}
+
+// readHashCount returns the number of hashes in the batch of header hashes.
+func readHashCount(b []byte) (uint64, error) {
+	br := io.NewBinReaderFromBuf(b)
+	count := br.ReadVarUint()
+	return count, br.Err
+}
+
 // HasTransaction returns nil if the given store does not contain the given
 // Transaction hash. It returns an error in case if transaction is in chain
 // or in the list of conflicting transactions.
@@ -0,0 +1,156 @@
+/*
+Package migrations implements offline upgrades of the node database between
+storage versions stored as dao.Version.
+*/
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+)
+
+type (
+	// Migration is a single step upgrading the database from one storage
+	// version to the next one. Run must be idempotent, it can be restarted
+	// if the previous run was interrupted.
+	Migration struct {
+		From        string
+		To          string
+		Description string
+		Run         func(d *dao.Simple, report ProgressFunc) error
+	}
+
+	// ProgressFunc is used by migration to report the number of processed
+	// items.
+	ProgressFunc func(processed int)
+
+	// Registry is an ordered set of migrations.
+	Registry struct {
+		steps []Migration
+	}
+)
+
+var (
+	// ErrNoPath is returned when there is no chain of migrations between
+	// two storage versions.
+	ErrNoPath = errors.New("no migration path")
+	// ErrNewerVersion is returned when the database is newer than the
+	// target version.
+	ErrNewerVersion = errors.New("database version is newer than supported")
+)
+
+// Default is the registry of node database migrations.
+var Default = new(Registry)
+
+// Register adds migration m to the Default registry.
+func Register(m Migration) {
+	Default.Register(m)
+}
+
+// Register adds migration m to the registry. Migrations must be registered
+// in order, every one of them starting from the version the previous one
+// upgrades to.
+func (r *Registry) Register(m Migration) {
+	if CompareVersions(m.From, m.To) >= 0 {
+		panic(fmt.Sprintf("migration %s -> %s doesn't upgrade", m.From, m.To))
+	}
+	if len(r.steps) != 0 && r.steps[len(r.steps)-1].To != m.From {
+		panic(fmt.Sprintf("migration %s -> %s is out of order", m.From, m.To))
+	}
+	r.steps = append(r.steps, m)
+}
+
+// Plan returns the ordered list of migrations upgrading the database from
+// version from to version to.
+func (r *Registry) Plan(from, to string) ([]Migration, error) {
+	switch cmp := CompareVersions(from, to); {
+	case cmp == 0:
+		return nil, nil
+	case cmp > 0:
+		return nil, fmt.Errorf("%w: %s > %s", ErrNewerVersion, from, to)
+	}
+	var (
+		plan []Migration
+		cur  = from
+	)
+	for _, m := range r.steps {
+		if m.From != cur {
+			continue
+		}
+		plan = append(plan, m)
+		cur = m.To
+		if cur == to {
+			return plan, nil
+		}
+	}
+	return nil, fmt.Errorf("%w from %s to %s", ErrNoPath, from, to)
+}
+
+// Run upgrades the database in store to version to applying migrations one
+// by one. Every migration is persisted along with the new version, so an
+// interrupted upgrade can be continued. onStep is called before each
+// migration is applied.
+func (r *Registry) Run(store storage.Store, to string, onStep func(Migration), report ProgressFunc) error {
+	ver, err := dao.NewSimple(store).GetVersion()
+	if err != nil {
+		return fmt.Errorf("can't get database version: %w", err)
+	}
+	plan, err := r.Plan(ver.Value, to)
+	if err != nil {
+		return err
+	}
+	for _, m := range plan {
+		if onStep != nil {
+			onStep(m)
+		}
+		d := dao.NewSimple(store)
+		d.Version = ver
+		if err := m.Run(d, report); err != nil {
+			return fmt.Errorf("migration %s -> %s failed: %w", m.From, m.To, err)
+		}
+		ver.Value = m.To
+		d.PutVersion(ver)
+		if _, err := d.PersistSync(); err != nil {
+			return fmt.Errorf("can't persist migration %s -> %s: %w", m.From, m.To, err)
+		}
+	}
+	return nil
+}
+
+// CompareVersions compares dot-separated numeric versions, it returns -1 if
+// a < b, 0 if a == b and 1 if a > b. Non-numeric parts are compared as
+// strings.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.ParseUint(x, 10, 64)
+		yn, yerr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
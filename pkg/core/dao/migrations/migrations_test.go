@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	require.Equal(t, 0, CompareVersions("0.2.5", "0.2.5"))
+	require.Equal(t, -1, CompareVersions("0.2.5", "0.2.10"))
+	require.Equal(t, 1, CompareVersions("0.3", "0.2.10"))
+	require.Equal(t, -1, CompareVersions("0.2", "0.2.1"))
+}
+
+func TestRegistry(t *testing.T) {
+	r := new(Registry)
+	key := []byte{0xff, 1}
+	r.Register(Migration{From: "0.1.0", To: "0.1.1", Description: "first", Run: func(d *dao.Simple, report ProgressFunc) error {
+		d.Store.Put(key, []byte{1})
+		report(1)
+		return nil
+	}})
+	fail := true
+	r.Register(Migration{From: "0.1.1", To: "0.2.0", Description: "second", Run: func(d *dao.Simple, _ ProgressFunc) error {
+		if fail {
+			return errors.New("interrupted")
+		}
+		d.Store.Put(key, []byte{2})
+		return nil
+	}})
+	require.Panics(t, func() { r.Register(Migration{From: "0.1.0", To: "0.3.0"}) })
+	require.Panics(t, func() { r.Register(Migration{From: "0.2.0", To: "0.1.0"}) })
+
+	plan, err := r.Plan("0.1.0", "0.2.0")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(plan))
+	plan, err = r.Plan("0.2.0", "0.2.0")
+	require.NoError(t, err)
+	require.Equal(t, 0, len(plan))
+	_, err = r.Plan("0.0.1", "0.2.0")
+	require.ErrorIs(t, err, ErrNoPath)
+	_, err = r.Plan("0.3.0", "0.2.0")
+	require.ErrorIs(t, err, ErrNewerVersion)
+
+	store := storage.NewMemoryStore()
+	d := dao.NewSimple(store)
+	d.PutVersion(dao.Version{StoragePrefix: storage.STStorage, Value: "0.1.0"})
+	_, err = d.Persist()
+	require.NoError(t, err)
+
+	var steps []string
+	onStep := func(m Migration) { steps = append(steps, m.To) }
+	require.Error(t, r.Run(store, "0.2.0", onStep, func(int) {}))
+	ver, err := dao.NewSimple(store).GetVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0.1.1", ver.Value)
+
+	// interrupted migration is continued
+	fail = false
+	require.NoError(t, r.Run(store, "0.2.0", onStep, func(int) {}))
+	require.Equal(t, []string{"0.1.1", "0.2.0", "0.2.0"}, steps)
+	ver, err = dao.NewSimple(store).GetVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0.2.0", ver.Value)
+	require.Equal(t, storage.STStorage, ver.StoragePrefix)
+	v, err := store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, v)
+}
@@ -0,0 +1,441 @@
+/*
+Package dbbackup implements node database backups. Backup is a consistent
+snapshot of the persistent storage written to a directory or a tar file as
+a set of data chunks and a manifest describing them.
+*/
+package dbbackup
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gio "io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type (
+	// Manifest describes the backup contents.
+	Manifest struct {
+		Version             string      `json:"version"`
+		StoragePrefix       byte        `json:"storageprefix"`
+		KeepOnlyLatestState bool        `json:"keeponlylateststate"`
+		Height              uint32      `json:"height"`
+		StateRoot           common.Hash `json:"stateroot"`
+		Keys                uint64      `json:"keys"`
+		Chunks              []Chunk     `json:"chunks"`
+	}
+
+	// Chunk is a single data file of the backup.
+	Chunk struct {
+		Name   string `json:"name"`
+		Size   int64  `json:"size"`
+		SHA256 string `json:"sha256"`
+	}
+
+	// archiveWriter stores backup files.
+	archiveWriter interface {
+		writeFile(name string, data []byte) error
+		Close() error
+	}
+
+	dirWriter struct {
+		dir string
+	}
+
+	tarWriter struct {
+		file *os.File
+		tw   *tar.Writer
+	}
+)
+
+const (
+	// ManifestFile is the name of the backup manifest file.
+	ManifestFile = "manifest.json"
+
+	chunkPrefix = "data-"
+	// chunkSize is the approximate size of a single data chunk.
+	chunkSize = 64 << 20
+	// restoreBatch is the number of keys persisted at once on restore.
+	restoreBatch = 100000
+)
+
+var (
+	// ErrNotEmpty is returned on attempt to restore backup into a non-empty
+	// database.
+	ErrNotEmpty = errors.New("database is not empty")
+	// ErrCorrupted is returned when backup contents don't match the manifest.
+	ErrCorrupted = errors.New("backup is corrupted")
+
+	errStop = errors.New("stop")
+)
+
+// IsTar returns true if the backup at the specified path is a tar file.
+func IsTar(path string) bool {
+	return strings.HasSuffix(path, ".tar")
+}
+
+// Create writes a backup of the store to path, which is either a tar file
+// (if path has .tar extension) or a directory. If the store implements
+// storage.Snapshotter, its snapshot is used, so the backup can be made while
+// the store is being modified. Otherwise the store must not be changed until
+// Create returns.
+func Create(store storage.Store, path string) (*Manifest, error) {
+	if s, ok := store.(storage.Snapshotter); ok {
+		snap, err := s.Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("can't take storage snapshot: %w", err)
+		}
+		defer snap.Close()
+		store = snap
+	}
+	m, err := readState(store)
+	if err != nil {
+		return nil, err
+	}
+	w, err := newArchiveWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	err = writeData(store, w, m)
+	if err == nil {
+		err = writeManifest(w, m)
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readState fills the manifest with the version, height and state root of
+// the database.
+func readState(store storage.Store) (*Manifest, error) {
+	d := dao.NewSimple(store)
+	ver, err := d.GetVersion()
+	if err != nil {
+		return nil, fmt.Errorf("can't get database version: %w", err)
+	}
+	h, err := d.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current block height: %w", err)
+	}
+	sr, err := stateroot.NewModule(config.ProtocolConfiguration{}, nil, zap.NewNop(), storage.NewMemCachedStore(store)).GetStateRoot(h)
+	if err != nil {
+		return nil, fmt.Errorf("can't get state root at %d: %w", h, err)
+	}
+	return &Manifest{
+		Version:             ver.Value,
+		StoragePrefix:       byte(ver.StoragePrefix),
+		KeepOnlyLatestState: ver.KeepOnlyLatestState,
+		Height:              h,
+		StateRoot:           sr.Root,
+	}, nil
+}
+
+func writeData(store storage.Store, w archiveWriter, m *Manifest) error {
+	var (
+		err error
+		buf = io.NewBufBinWriter()
+	)
+	flush := func() error {
+		if buf.Err != nil {
+			return buf.Err
+		}
+		data := buf.Bytes()
+		defer buf.Reset()
+		sum := sha256.Sum256(data)
+		c := Chunk{
+			Name:   fmt.Sprintf("%s%06d.bin", chunkPrefix, len(m.Chunks)),
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		}
+		m.Chunks = append(m.Chunks, c)
+		return w.writeFile(c.Name, data)
+	}
+	// Memory store can't seek with an empty prefix, so keys are iterated
+	// prefix by prefix.
+	for p := 0; p <= 0xff && err == nil; p++ {
+		store.Seek(storage.SeekRange{Prefix: []byte{byte(p)}}, func(k, v []byte) bool {
+			buf.WriteVarBytes(k)
+			buf.WriteVarBytes(v)
+			m.Keys++
+			if buf.Len() >= chunkSize {
+				err = flush()
+			}
+			return err == nil
+		})
+	}
+	if err == nil && buf.Len() != 0 {
+		err = flush()
+	}
+	if err == nil {
+		err = buf.Err
+	}
+	return err
+}
+
+func writeManifest(w archiveWriter, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.writeFile(ManifestFile, data)
+}
+
+// ReadManifest reads the manifest of the backup at the specified path.
+func ReadManifest(path string) (*Manifest, error) {
+	var m *Manifest
+	err := walk(path, func(name string, r gio.Reader) error {
+		if name != ManifestFile {
+			return nil
+		}
+		m = new(Manifest)
+		if err := json.NewDecoder(r).Decode(m); err != nil {
+			return fmt.Errorf("invalid manifest: %w", err)
+		}
+		return errStop
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%w: no manifest", ErrCorrupted)
+	}
+	return m, nil
+}
+
+// Restore writes the backup at the specified path to the empty store and
+// verifies the restored database against the manifest. The database version
+// is written last, so an interrupted restore doesn't leave a usable database.
+func Restore(path string, store storage.Store) (*Manifest, error) {
+	m, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isEmpty(store) {
+		return nil, ErrNotEmpty
+	}
+	chunks := make(map[string]Chunk, len(m.Chunks))
+	for _, c := range m.Chunks {
+		chunks[c.Name] = c
+	}
+	var (
+		keys    uint64
+		restore = storage.NewMemCachedStore(store)
+		pending int
+		version []byte
+		verKey  = []byte{byte(storage.SYSVersion)}
+	)
+	err = walk(path, func(name string, r gio.Reader) error {
+		if name == ManifestFile {
+			return nil
+		}
+		c, ok := chunks[name]
+		if !ok {
+			return fmt.Errorf("%w: unexpected file %s", ErrCorrupted, name)
+		}
+		delete(chunks, name)
+		h := sha256.New()
+		cr := &countingReader{r: gio.TeeReader(r, h)}
+		br := io.NewBinReaderFromIO(cr)
+		for {
+			k := br.ReadVarBytes(chunkSize)
+			if br.Err != nil {
+				if !errors.Is(br.Err, gio.EOF) {
+					return fmt.Errorf("%w: %s: %v", ErrCorrupted, name, br.Err)
+				}
+				break
+			}
+			v := br.ReadVarBytes(chunkSize)
+			if br.Err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrCorrupted, name, br.Err)
+			}
+			keys++
+			if bytes.Equal(k, verKey) {
+				version = v
+				continue
+			}
+			restore.Put(k, v)
+			pending++
+			if pending >= restoreBatch {
+				if _, err := restore.Persist(); err != nil {
+					return err
+				}
+				pending = 0
+			}
+		}
+		if cr.n != c.Size || hex.EncodeToString(h.Sum(nil)) != c.SHA256 {
+			return fmt.Errorf("%w: checksum mismatch for %s", ErrCorrupted, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) != 0 {
+		return nil, fmt.Errorf("%w: %d chunks are missing", ErrCorrupted, len(chunks))
+	}
+	if keys != m.Keys {
+		return nil, fmt.Errorf("%w: expected %d keys, got %d", ErrCorrupted, m.Keys, keys)
+	}
+	if version == nil {
+		return nil, fmt.Errorf("%w: no database version", ErrCorrupted)
+	}
+	restore.Put(verKey, version)
+	s, err := readState(restore)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if s.Version != m.Version || s.Height != m.Height || s.StateRoot != m.StateRoot {
+		return nil, fmt.Errorf("%w: restored version %s, height %d, state root %s don't match the manifest",
+			ErrCorrupted, s.Version, s.Height, s.StateRoot)
+	}
+	if _, err := restore.PersistSync(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func isEmpty(store storage.Store) bool {
+	empty := true
+	for p := 0; p <= 0xff && empty; p++ {
+		store.Seek(storage.SeekRange{Prefix: []byte{byte(p)}}, func(k, v []byte) bool {
+			empty = false
+			return false
+		})
+	}
+	return empty
+}
+
+// walk calls f for every file of the backup, files are processed in the
+// order they're stored in the archive.
+func walk(path string, f func(name string, r gio.Reader) error) error {
+	if IsTar(path) {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		tr := tar.NewReader(file)
+		for {
+			hdr, err := tr.Next()
+			if err != nil {
+				if errors.Is(err, gio.EOF) {
+					return nil
+				}
+				return err
+			}
+			if err := f(hdr.Name, tr); err != nil {
+				return err
+			}
+		}
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	// Manifest is processed last as if it was read from the tar file.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name() != ManifestFile && entries[j].Name() == ManifestFile
+	})
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		file, err := os.Open(filepath.Join(path, e.Name()))
+		if err != nil {
+			return err
+		}
+		err = f(e.Name(), file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newArchiveWriter(path string) (archiveWriter, error) {
+	if IsTar(path) {
+		if err := io.MakeDirForFile(path, "backup"); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &tarWriter{file: f, tw: tar.NewWriter(f)}, nil
+	}
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 0 {
+		return nil, fmt.Errorf("backup directory %s is not empty", path)
+	}
+	return &dirWriter{dir: path}, nil
+}
+
+func (w *dirWriter) writeFile(name string, data []byte) error {
+	return os.WriteFile(filepath.Join(w.dir, name), data, 0644)
+}
+
+func (w *dirWriter) Close() error {
+	return nil
+}
+
+func (w *tarWriter) writeFile(name string, data []byte) error {
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     0644,
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.tw.Write(data)
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	err := w.tw.Close()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// countingReader counts the number of bytes read.
+type countingReader struct {
+	r gio.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
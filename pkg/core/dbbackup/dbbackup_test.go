@@ -0,0 +1,98 @@
+package dbbackup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestStore(t *testing.T) *storage.MemoryStore {
+	store := storage.NewMemoryStore()
+	cache := storage.NewMemCachedStore(store)
+	sr := stateroot.NewModule(config.ProtocolConfiguration{}, nil, zap.NewNop(), cache)
+	require.NoError(t, sr.Init(0))
+	_, _, err := sr.AddMPTBatch(1, mpt.MapToMPTBatch(map[string][]byte{"key": {1, 2, 3}}), cache)
+	require.NoError(t, err)
+
+	d := dao.NewSimple(cache)
+	d.PutVersion(dao.Version{StoragePrefix: storage.STStorage, Value: "0.2.5"})
+	d.StoreAsCurrentBlock(&block.Block{Header: block.Header{Index: 1}})
+	d.Store.Put([]byte{byte(storage.STStorage), 1, 2}, []byte{3})
+	_, err = d.Persist()
+	require.NoError(t, err)
+	_, err = cache.Persist()
+	require.NoError(t, err)
+	return store
+}
+
+func TestBackupRestore(t *testing.T) {
+	for _, name := range []string{"backup", "backup.tar"} {
+		t.Run(name, func(t *testing.T) {
+			store := newTestStore(t)
+			path := filepath.Join(t.TempDir(), name)
+			m, err := Create(store, path)
+			require.NoError(t, err)
+			require.Equal(t, "0.2.5", m.Version)
+			require.Equal(t, uint32(1), m.Height)
+			require.NotEqual(t, [32]byte{}, m.StateRoot)
+			require.Equal(t, 1, len(m.Chunks))
+
+			// backup can't overwrite existing one
+			_, err = Create(store, path)
+			require.Error(t, err)
+
+			rm, err := ReadManifest(path)
+			require.NoError(t, err)
+			require.Equal(t, m, rm)
+
+			restored := storage.NewMemoryStore()
+			_, err = Restore(path, restored)
+			require.NoError(t, err)
+			var n uint64
+			for p := 0; p <= 0xff; p++ {
+				restored.Seek(storage.SeekRange{Prefix: []byte{byte(p)}}, func(k, v []byte) bool {
+					orig, err := store.Get(k)
+					require.NoError(t, err)
+					require.Equal(t, orig, v)
+					n++
+					return true
+				})
+			}
+			require.Equal(t, m.Keys, n)
+
+			_, err = Restore(path, restored)
+			require.ErrorIs(t, err, ErrNotEmpty)
+		})
+	}
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup")
+	m, err := Create(newTestStore(t), path)
+	require.NoError(t, err)
+
+	chunk := filepath.Join(path, m.Chunks[0].Name)
+	data, err := os.ReadFile(chunk)
+	require.NoError(t, err)
+	data[len(data)-1]++
+	require.NoError(t, os.WriteFile(chunk, data, 0644))
+
+	restored := storage.NewMemoryStore()
+	_, err = Restore(path, restored)
+	require.ErrorIs(t, err, ErrCorrupted)
+	_, err = dao.NewSimple(restored).GetVersion()
+	require.Error(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(path, ManifestFile)))
+	_, err = ReadManifest(path)
+	require.ErrorIs(t, err, ErrCorrupted)
+}
@@ -0,0 +1,249 @@
+/*
+Package dbinspect provides node database statistics and human-readable
+representation of the stored keys.
+*/
+package dbinspect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type (
+	// Stats is the number and the size of stored key-value pairs.
+	Stats struct {
+		Keys      uint64 `json:"keys"`
+		KeySize   uint64 `json:"keysize"`
+		ValueSize uint64 `json:"valuesize"`
+	}
+
+	// PrefixStats is Stats for a single storage.KeyPrefix.
+	PrefixStats struct {
+		Prefix storage.KeyPrefix `json:"prefix"`
+		Stats
+	}
+
+	// ContractStats is Stats of a single contract storage.
+	ContractStats struct {
+		Address common.Address `json:"address"`
+		Stats
+	}
+
+	// Report is the result of the database inspection.
+	Report struct {
+		Total     Stats           `json:"total"`
+		Prefixes  []PrefixStats   `json:"prefixes"`
+		Contracts []ContractStats `json:"contracts"`
+	}
+)
+
+var prefixNames = map[storage.KeyPrefix]string{
+	storage.DataTx:                         "DataTx",
+	storage.DataBlock:                      "DataBlock",
+	storage.DataMPT:                        "DataMPT",
+	storage.DataMPTAux:                     "DataMPTAux",
+	storage.STContractID:                   "STContractID",
+	storage.STStorage:                      "STStorage",
+	storage.STTempStorage:                  "STTempStorage",
+	storage.STTokenTransferInfo:            "STTokenTransferInfo",
+	storage.IXHeaderHashList:               "IXHeaderHashList",
+	storage.SYSCurrentBlock:                "SYSCurrentBlock",
+	storage.SYSCurrentHeader:               "SYSCurrentHeader",
+	storage.SYSStateSyncCurrentBlockHeight: "SYSStateSyncCurrentBlockHeight",
+	storage.SYSStateSyncPoint:              "SYSStateSyncPoint",
+	storage.SYSStateJumpStage:              "SYSStateJumpStage",
+	storage.SYSStateSyncRoot:               "SYSStateSyncRoot",
+	storage.SYSVersion:                     "SYSVersion",
+}
+
+// PrefixName returns the name of the key prefix.
+func PrefixName(p storage.KeyPrefix) string {
+	if name, ok := prefixNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", byte(p))
+}
+
+func (s *Stats) add(k, v []byte) {
+	s.Keys++
+	s.KeySize += uint64(len(k))
+	s.ValueSize += uint64(len(v))
+}
+
+// Size returns the total size of keys and values.
+func (s Stats) Size() uint64 {
+	return s.KeySize + s.ValueSize
+}
+
+// isContractStorage returns true if keys with prefix p are contract storage
+// items, i.e. have contract address after the prefix.
+func isContractStorage(p storage.KeyPrefix) bool {
+	return p == storage.STStorage || p == storage.STTempStorage
+}
+
+// Inspect walks the whole store and collects statistics per key prefix and
+// per contract storage. Prefixes are sorted by key prefix, contracts by
+// their storage size in descending order.
+func Inspect(store storage.Store) *Report {
+	var (
+		r         = new(Report)
+		contracts = make(map[common.Address]*Stats)
+	)
+	// Memory store can't seek with an empty prefix, so keys are iterated
+	// prefix by prefix.
+	for i := 0; i <= 0xff; i++ {
+		ps := PrefixStats{Prefix: storage.KeyPrefix(i)}
+		store.Seek(storage.SeekRange{Prefix: []byte{byte(i)}}, func(k, v []byte) bool {
+			ps.add(k, v)
+			if isContractStorage(ps.Prefix) && len(k) > common.AddressLength {
+				addr := common.BytesToAddress(k[1 : 1+common.AddressLength])
+				cs, ok := contracts[addr]
+				if !ok {
+					cs = new(Stats)
+					contracts[addr] = cs
+				}
+				cs.add(k, v)
+			}
+			return true
+		})
+		if ps.Keys == 0 {
+			continue
+		}
+		r.Prefixes = append(r.Prefixes, ps)
+		r.Total.Keys += ps.Keys
+		r.Total.KeySize += ps.KeySize
+		r.Total.ValueSize += ps.ValueSize
+	}
+	for addr, cs := range contracts {
+		r.Contracts = append(r.Contracts, ContractStats{Address: addr, Stats: *cs})
+	}
+	sort.Slice(r.Contracts, func(i, j int) bool {
+		si, sj := r.Contracts[i].Size(), r.Contracts[j].Size()
+		if si != sj {
+			return si > sj
+		}
+		return bytes.Compare(r.Contracts[i].Address[:], r.Contracts[j].Address[:]) < 0
+	})
+	return r
+}
+
+// TopContracts returns at most n contracts with the biggest storage.
+func (r *Report) TopContracts(n int) []ContractStats {
+	if n <= 0 || n > len(r.Contracts) {
+		n = len(r.Contracts)
+	}
+	return r.Contracts[:n]
+}
+
+// Dump calls f for every key-value pair with the specified key prefix until
+// f returns false, at most limit pairs are processed if limit is positive.
+func Dump(store storage.Store, prefix []byte, limit int, f func(k, v []byte) bool) {
+	var (
+		n  int
+		cb = func(k, v []byte) bool {
+			n++
+			return f(k, v) && (limit <= 0 || n < limit)
+		}
+	)
+	if len(prefix) != 0 {
+		store.Seek(storage.SeekRange{Prefix: prefix}, cb)
+		return
+	}
+	for i := 0; i <= 0xff && (limit <= 0 || n < limit); i++ {
+		var stop bool
+		store.Seek(storage.SeekRange{Prefix: []byte{byte(i)}}, func(k, v []byte) bool {
+			if !cb(k, v) {
+				stop = true
+				return false
+			}
+			return true
+		})
+		if stop {
+			return
+		}
+	}
+}
+
+// Decode returns human-readable representation of the key-value pair. Keys
+// of unknown format are returned as hex strings.
+func Decode(k, v []byte) string {
+	if len(k) == 0 {
+		return fmt.Sprintf("<empty> = %s", hex.EncodeToString(v))
+	}
+	p := storage.KeyPrefix(k[0])
+	name := PrefixName(p)
+	switch {
+	case p == storage.SYSVersion:
+		var ver dao.Version
+		if err := ver.FromBytes(v); err == nil {
+			return fmt.Sprintf("%s = %s (storage prefix 0x%02x, KeepOnlyLatestState %t)",
+				name, ver.Value, byte(ver.StoragePrefix), ver.KeepOnlyLatestState)
+		}
+	case (p == storage.SYSCurrentBlock || p == storage.SYSCurrentHeader) && len(v) == common.HashLength+4:
+		return fmt.Sprintf("%s = %d (%s)", name, binary.LittleEndian.Uint32(v[common.HashLength:]), common.BytesToHash(v[:common.HashLength]))
+	case (p == storage.SYSStateSyncCurrentBlockHeight || p == storage.SYSStateSyncPoint) && len(v) == 4:
+		return fmt.Sprintf("%s = %d", name, binary.LittleEndian.Uint32(v))
+	case p == storage.IXHeaderHashList && len(k) == 5:
+		r := io.NewBinReaderFromBuf(v)
+		n := r.ReadVarUint()
+		if r.Err == nil && n != 0 {
+			start := binary.BigEndian.Uint32(k[1:])
+			return fmt.Sprintf("%s %d = %d hashes of headers %d..%d", name, start, n, start, start+uint32(n)-1)
+		}
+	case p == storage.DataMPT && len(k) == 1+common.HashLength:
+		if s, ok := decodeMPTNode(v); ok {
+			return fmt.Sprintf("%s %s = %s", name, common.BytesToHash(k[1:]), s)
+		}
+	case p == 0 && len(k) == 5:
+		// Local state roots are stored by big-endian height which overwrites
+		// DataMPTAux prefix.
+		sr := new(state.MPTRoot)
+		r := io.NewBinReaderFromBuf(v)
+		sr.DecodeBinary(r)
+		if r.Err == nil {
+			return fmt.Sprintf("StateRoot %d = %s", binary.BigEndian.Uint32(k[:4]), sr.Root)
+		}
+	case (p == storage.DataBlock || p == storage.DataTx) && len(k) == 1+common.HashLength:
+		return fmt.Sprintf("%s %s = %d bytes", name, common.BytesToHash(k[1:]), len(v))
+	case isContractStorage(p) && len(k) > common.AddressLength:
+		return fmt.Sprintf("%s %s %s = %s", name, common.BytesToAddress(k[1:1+common.AddressLength]),
+			hex.EncodeToString(k[1+common.AddressLength:]), hex.EncodeToString(v))
+	}
+	return fmt.Sprintf("%s %s = %s", name, hex.EncodeToString(k[1:]), hex.EncodeToString(v))
+}
+
+// decodeMPTNode returns JSON representation of the stored MPT node. Nodes
+// stored with reference counter have 5 additional bytes after the node data.
+func decodeMPTNode(v []byte) (string, bool) {
+	var (
+		n  mpt.NodeObject
+		br = bytes.NewReader(v)
+		r  = io.NewBinReaderFromIO(br)
+	)
+	n.DecodeBinary(r)
+	if r.Err != nil {
+		return "", false
+	}
+	data, err := json.Marshal(n.Node)
+	if err != nil {
+		return "", false
+	}
+	switch rest := br.Len(); {
+	case rest == 5:
+		return fmt.Sprintf("%s (refcount %d)", data, int32(binary.LittleEndian.Uint32(v[len(v)-4:]))), true
+	case rest != 0:
+		return "", false
+	}
+	return string(data), true
+}
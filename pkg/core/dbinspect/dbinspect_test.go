@@ -0,0 +1,56 @@
+package dbinspect
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	store := storage.NewMemoryStore()
+	d := dao.NewSimple(store)
+	d.PutVersion(dao.Version{StoragePrefix: storage.STStorage, Value: "0.2.5"})
+	d.PutCurrentHeader(common.Hash{1}, 5)
+	a, b := common.Address{1}, common.Address{2}
+	d.PutStorageItem(a, []byte{1}, []byte{1, 2, 3})
+	d.PutStorageItem(b, []byte{1}, []byte{1})
+	d.PutStorageItem(b, []byte{2}, []byte{2})
+	_, err := d.Persist()
+	require.NoError(t, err)
+
+	r := Inspect(store)
+	require.Equal(t, uint64(5), r.Total.Keys)
+	require.Equal(t, 3, len(r.Prefixes))
+	require.Equal(t, storage.STStorage, r.Prefixes[0].Prefix)
+	require.Equal(t, Stats{Keys: 3, KeySize: 3 * 22, ValueSize: 5}, r.Prefixes[0].Stats)
+	require.Equal(t, []ContractStats{{Address: b, Stats: Stats{Keys: 2, KeySize: 44, ValueSize: 2}}}, r.TopContracts(1))
+	require.Equal(t, 2, len(r.TopContracts(0)))
+
+	var dumped []string
+	Dump(store, []byte{byte(storage.STStorage), 2}, 0, func(k, v []byte) bool {
+		dumped = append(dumped, Decode(k, v))
+		return true
+	})
+	require.Equal(t, []string{
+		"STStorage 0x0200000000000000000000000000000000000000 01 = 01",
+		"STStorage 0x0200000000000000000000000000000000000000 02 = 02",
+	}, dumped)
+
+	dumped = dumped[:0]
+	Dump(store, nil, 2, func(k, v []byte) bool {
+		dumped = append(dumped, Decode(k, v))
+		return true
+	})
+	require.Equal(t, 2, len(dumped))
+
+	v, err := store.Get([]byte{byte(storage.SYSVersion)})
+	require.NoError(t, err)
+	require.Equal(t, "SYSVersion = 0.2.5 (storage prefix 0x70, KeepOnlyLatestState false)", Decode([]byte{byte(storage.SYSVersion)}, v))
+	v, err = store.Get([]byte{byte(storage.SYSCurrentHeader)})
+	require.NoError(t, err)
+	require.Equal(t, "SYSCurrentHeader = 5 ("+common.Hash{1}.String()+")", Decode([]byte{byte(storage.SYSCurrentHeader)}, v))
+	require.Equal(t, "0xaa 01 = 02", Decode([]byte{0xaa, 1}, []byte{2}))
+}
@@ -0,0 +1,180 @@
+/*
+Package dbrewind implements offline rollback of the node database to some
+previous height.
+*/
+package dbrewind
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao/migrations"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrPruned is returned if the state at the target height is not
+	// available anymore.
+	ErrPruned = errors.New("state is pruned")
+	// ErrNothingToRewind is returned if the chain is already at the target
+	// height.
+	ErrNothingToRewind = errors.New("nothing to rewind")
+)
+
+// Result describes the performed rewind.
+type Result struct {
+	// From is the block height before the rewind.
+	From uint32
+	// Headers is the header height before the rewind, it can be above From.
+	Headers uint32
+	// Height is the current block height after the rewind.
+	Height uint32
+	// StateRoot is the state root at Height.
+	StateRoot common.Hash
+	// Blocks is the number of removed blocks and headers.
+	Blocks int
+	// StorageItems is the number of restored contract storage items.
+	StorageItems int
+}
+
+// Heights returns current block and header heights of the chain stored in
+// the store.
+func Heights(store storage.Store) (uint32, uint32, error) {
+	d := dao.NewSimple(store)
+	bh, err := d.GetCurrentBlockHeight()
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't get current block height: %w", err)
+	}
+	hh, _, err := d.GetCurrentHeaderHeight()
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't get current header height: %w", err)
+	}
+	return bh, hh, nil
+}
+
+// Rewind removes blocks, headers, transactions and their receipts above the
+// specified height from the store of the stopped node and restores contract
+// storage and the local state root at this height. Only the nodes keeping
+// all states (with KeepOnlyLatestState and RemoveUntraceableBlocks disabled)
+// can be rewound. All the changes are written to the store at once, so the
+// store is not modified if an error is returned.
+func Rewind(store storage.Store, cfg config.ProtocolConfiguration, height uint32) (*Result, error) {
+	d := dao.NewSimple(store)
+	ver, err := d.GetVersion()
+	if err != nil {
+		return nil, fmt.Errorf("can't get database version: %w", err)
+	}
+	if ver.Value != core.StorageVersion {
+		_, err := migrations.Default.Plan(ver.Value, core.StorageVersion)
+		switch {
+		case err == nil:
+			return nil, fmt.Errorf("storage version %s is outdated (expected=%s), run 'db migrate' first", ver.Value, core.StorageVersion)
+		case errors.Is(err, migrations.ErrNoPath):
+			return nil, fmt.Errorf("storage version %s is outdated (expected=%s) and can't be migrated, resync is required", ver.Value, core.StorageVersion)
+		}
+		return nil, fmt.Errorf("storage version mismatch (expected=%s, actual=%s)", core.StorageVersion, ver.Value)
+	}
+	if ver.KeepOnlyLatestState || cfg.KeepOnlyLatestState || cfg.RemoveUntraceableBlocks {
+		return nil, fmt.Errorf("%w: old states are not kept with KeepOnlyLatestState or RemoveUntraceableBlocks enabled", ErrPruned)
+	}
+	d.Version = ver
+
+	res := &Result{Height: height}
+	res.From, res.Headers, err = Heights(store)
+	if err != nil {
+		return nil, err
+	}
+	if height > res.From {
+		return nil, fmt.Errorf("target height %d is above the current block height %d", height, res.From)
+	}
+	if height == res.From && height == res.Headers {
+		return nil, ErrNothingToRewind
+	}
+
+	hashes, err := headerHashes(d, height, res.Headers)
+	if err != nil {
+		return nil, err
+	}
+	header, err := d.GetHeader(hashes[0])
+	if err != nil {
+		return nil, fmt.Errorf("can't get header %d: %w", height, err)
+	}
+
+	sr := stateroot.NewModule(cfg, nil, zap.NewNop(), d.Store)
+	if err := sr.Init(res.From); err != nil {
+		return nil, fmt.Errorf("can't init MPT: %w", err)
+	}
+	target, err := sr.GetStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("%w: can't get state root at height %d: %v", ErrPruned, height, err)
+	}
+	res.StateRoot = target.Root
+	diff, err := mpt.Diff(sr.CurrentLocalStateRoot(), d.Store, target.Root, d.Store, math.MaxInt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: state at height %d is not available: %v", ErrPruned, height, err)
+	}
+	for _, kd := range diff {
+		if len(kd.Key) < common.AddressLength {
+			return nil, fmt.Errorf("invalid storage key %x", kd.Key)
+		}
+		addr := common.BytesToAddress(kd.Key[:common.AddressLength])
+		if kd.NewValue == nil {
+			d.DeleteStorageItem(addr, kd.Key[common.AddressLength:])
+		} else {
+			d.PutStorageItem(addr, kd.Key[common.AddressLength:], kd.NewValue)
+		}
+	}
+	res.StorageItems = len(diff)
+
+	for i, h := range hashes[1:] {
+		if err := d.PurgeBlock(h); err != nil {
+			return nil, fmt.Errorf("can't remove block %d: %w", height+uint32(i)+1, err)
+		}
+	}
+	res.Blocks = len(hashes) - 1
+	// The rest of the header hash list is restored from the current header
+	// on node start.
+	if err := d.DeleteHeaderHashes(height); err != nil {
+		return nil, err
+	}
+	d.StoreAsCurrentBlock(&block.Block{Header: *header})
+	d.PutCurrentHeader(hashes[0], height)
+	if err := sr.Rewind(height); err != nil {
+		return nil, err
+	}
+	if _, err := d.PersistSync(); err != nil {
+		return nil, fmt.Errorf("can't persist changes: %w", err)
+	}
+	return res, nil
+}
+
+// headerHashes returns hashes of headers from height up to top by walking
+// back from the current header.
+func headerHashes(d *dao.Simple, height, top uint32) ([]common.Hash, error) {
+	_, hash, err := d.GetCurrentHeaderHeight()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current header: %w", err)
+	}
+	hashes := make([]common.Hash, top-height+1)
+	for i := top; ; i-- {
+		hashes[i-height] = hash
+		if i == height {
+			break
+		}
+		h, err := d.GetHeader(hash)
+		if err != nil {
+			return nil, fmt.Errorf("can't get header %d: %w", i, err)
+		}
+		hash = h.PrevHash
+	}
+	return hashes, nil
+}
@@ -0,0 +1,127 @@
+package dbrewind
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testAddr = common.Address{1, 2, 3}
+
+// newTestStore returns a store with blocks 0..2 and header 3, every block
+// changes a single storage item and block 2 has a transaction.
+func newTestStore(t *testing.T) (*storage.MemoryStore, []*block.Block) {
+	store := storage.NewMemoryStore()
+	cache := storage.NewMemCachedStore(store)
+	sr := stateroot.NewModule(config.ProtocolConfiguration{}, nil, zap.NewNop(), cache)
+	require.NoError(t, sr.Init(0))
+	d := dao.NewSimple(cache)
+	d.PutVersion(dao.Version{StoragePrefix: storage.STStorage, Value: core.StorageVersion})
+
+	var (
+		blocks []*block.Block
+		hashes []common.Hash
+		prev   common.Hash
+	)
+	for i := uint32(0); i <= 3; i++ {
+		b := &block.Block{Header: block.Header{Index: i, PrevHash: prev}, Transactions: []*transaction.Transaction{}}
+		if i == 2 {
+			b.Transactions = append(b.Transactions, transaction.NewTrimmedTX(common.Hash{2}))
+		}
+		blocks = append(blocks, b)
+		hashes = append(hashes, b.Hash())
+		prev = b.Hash()
+		if i == 3 {
+			require.NoError(t, d.StoreAsHeader(&b.Header))
+			break
+		}
+		key := []byte{byte(i)}
+		d.PutStorageItem(testAddr, key, []byte{byte(i)})
+		stKey := append([]byte{byte(storage.STStorage)}, append(testAddr.Bytes(), key...)...)
+		tr, root, err := sr.AddMPTBatch(i, mpt.MapToMPTBatch(map[string][]byte{string(stKey): {byte(i)}}), cache)
+		require.NoError(t, err)
+		sr.UpdateCurrentLocal(tr, root)
+		require.NoError(t, d.StoreAsBlock(b, &types.Receipt{Logs: []*types.Log{}}))
+		for _, tx := range b.Transactions {
+			d.Store.Put(append([]byte{byte(storage.DataTx)}, tx.Hash().Bytes()...), []byte{1, 2, 3, 4})
+		}
+		d.StoreAsCurrentBlock(b)
+	}
+	d.PutCurrentHeader(hashes[3], 3)
+	require.NoError(t, d.StoreHeaderHashes(hashes, 0))
+	_, err := d.Persist()
+	require.NoError(t, err)
+	_, err = cache.Persist()
+	require.NoError(t, err)
+	return store, blocks
+}
+
+func TestRewind(t *testing.T) {
+	store, blocks := newTestStore(t)
+
+	_, err := Rewind(store, config.ProtocolConfiguration{}, 3)
+	require.Error(t, err)
+	_, err = Rewind(store, config.ProtocolConfiguration{KeepOnlyLatestState: true}, 1)
+	require.ErrorIs(t, err, ErrPruned)
+
+	res, err := Rewind(store, config.ProtocolConfiguration{}, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), res.From)
+	require.Equal(t, uint32(3), res.Headers)
+	require.Equal(t, 2, res.Blocks)
+	require.Equal(t, 1, res.StorageItems)
+
+	d := dao.NewSimple(store)
+	d.Version.StoragePrefix = storage.STStorage
+	bh, hh, err := Heights(store)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), bh)
+	require.Equal(t, uint32(1), hh)
+	_, h, err := d.GetCurrentHeaderHeight()
+	require.NoError(t, err)
+	require.Equal(t, blocks[1].Hash(), h)
+
+	require.Equal(t, []byte{1}, []byte(d.GetStorageItem(testAddr, []byte{1})))
+	require.Nil(t, d.GetStorageItem(testAddr, []byte{2}))
+	for _, b := range blocks[2:] {
+		_, err := d.GetHeader(b.Hash())
+		require.Error(t, err)
+	}
+	_, err = store.Get(append([]byte{byte(storage.DataTx)}, common.Hash{2}.Bytes()...))
+	require.ErrorIs(t, err, storage.ErrKeyNotFound)
+	hashes, err := d.GetHeaderHashes()
+	require.NoError(t, err)
+	require.Equal(t, 0, len(hashes))
+
+	sr := stateroot.NewModule(config.ProtocolConfiguration{}, nil, zap.NewNop(), storage.NewMemCachedStore(store))
+	require.NoError(t, sr.Init(1))
+	require.Equal(t, res.StateRoot, sr.CurrentLocalStateRoot())
+	_, err = sr.GetStateRoot(2)
+	require.Error(t, err)
+
+	_, err = Rewind(store, config.ProtocolConfiguration{}, 1)
+	require.ErrorIs(t, err, ErrNothingToRewind)
+}
+
+func TestRewindVersion(t *testing.T) {
+	store, _ := newTestStore(t)
+	d := dao.NewSimple(store)
+	d.PutVersion(dao.Version{StoragePrefix: storage.STStorage, Value: "0.0.1"})
+	_, err := d.Persist()
+	require.NoError(t, err)
+
+	// There are no migrations from this version.
+	_, err = Rewind(store, config.ProtocolConfiguration{}, 1)
+	require.ErrorContains(t, err, "resync is required")
+}
@@ -0,0 +1,159 @@
+/*
+Package dbverify implements node database verification by re-executing
+stored blocks and comparing the results with the stored ones.
+*/
+package dbverify
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Divergence describes the first block for which re-execution results don't
+// match the stored ones.
+type Divergence struct {
+	Height uint32
+	Reason string
+	// StateRoot is the stored state root and NewStateRoot is the one
+	// obtained by re-execution.
+	StateRoot    common.Hash
+	NewStateRoot common.Hash
+	// Diff contains contract storage keys having different values in the
+	// stored and re-executed states. Keys consist of contract address and
+	// storage key.
+	Diff []mpt.KeyDiff
+	// DiffErr is set if state difference can't be calculated, e.g. because
+	// the stored state was pruned.
+	DiffErr error
+}
+
+// maxDiffKeys is the maximum number of keys reported in Divergence.
+const maxDiffKeys = 100
+
+// Verify adds blocks from src up to the specified height to dst using the
+// regular block addition path and checks that state roots and receipts of
+// every block are the same in both chains. dst must contain a prefix of src
+// chain, e.g. only genesis block or a trusted state snapshot. onBlock is
+// called after every successfully verified block. The first divergence
+// found is returned, nil means that all blocks are verified.
+func Verify(src, dst *core.Blockchain, to uint32, onBlock func(uint32)) (*Divergence, error) {
+	if to > src.BlockHeight() {
+		return nil, fmt.Errorf("target height %d is above the current height %d", to, src.BlockHeight())
+	}
+	from := dst.BlockHeight()
+	if from > to {
+		return nil, fmt.Errorf("verified chain height %d is above the target height %d", from, to)
+	}
+	if src.GetHeaderHash(int(from)) != dst.CurrentBlockHash() {
+		return nil, fmt.Errorf("block %d doesn't match the database one", from)
+	}
+	for i := from + 1; i <= to; i++ {
+		b, aer, err := src.GetBlock(src.GetHeaderHash(int(i)), true)
+		if err != nil {
+			return nil, fmt.Errorf("can't get block %d: %w", i, err)
+		}
+		if err := dst.AddBlock(b); err != nil {
+			return &Divergence{Height: i, Reason: fmt.Sprintf("block is rejected: %v", err)}, nil
+		}
+		d, err := compareStateRoots(src, dst, i)
+		if d != nil || err != nil {
+			return d, err
+		}
+		_, newAer, err := dst.GetBlock(b.Hash(), false)
+		if err != nil {
+			return nil, fmt.Errorf("can't get re-executed block %d: %w", i, err)
+		}
+		if reason := compareReceipts(aer, newAer); reason != "" {
+			return &Divergence{Height: i, Reason: "block receipt: " + reason}, nil
+		}
+		for _, tx := range b.Transactions {
+			_, r, err := src.GetTransaction(tx.Hash())
+			if err != nil {
+				return nil, fmt.Errorf("can't get transaction %s: %w", tx.Hash(), err)
+			}
+			_, newR, err := dst.GetTransaction(tx.Hash())
+			if err != nil {
+				return nil, fmt.Errorf("can't get re-executed transaction %s: %w", tx.Hash(), err)
+			}
+			if reason := compareReceipts(r, newR); reason != "" {
+				return &Divergence{Height: i, Reason: fmt.Sprintf("transaction %s receipt: %s", tx.Hash(), reason)}, nil
+			}
+		}
+		if onBlock != nil {
+			onBlock(i)
+		}
+	}
+	return nil, nil
+}
+
+func compareStateRoots(src, dst *core.Blockchain, height uint32) (*Divergence, error) {
+	sr, err := src.GetStateModule().GetStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("can't get state root %d: %w", height, err)
+	}
+	newSr, err := dst.GetStateModule().GetStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("can't get re-executed state root %d: %w", height, err)
+	}
+	if sr.Root == newSr.Root {
+		return nil, nil
+	}
+	d := &Divergence{
+		Height:       height,
+		Reason:       "state root mismatch",
+		StateRoot:    sr.Root,
+		NewStateRoot: newSr.Root,
+	}
+	d.Diff, d.DiffErr = mpt.Diff(sr.Root, stateStore(src), newSr.Root, stateStore(dst), maxDiffKeys)
+	return d, nil
+}
+
+// stateStore returns the store MPT nodes of the chain are kept in, including
+// the ones that are not persisted yet.
+func stateStore(bc *core.Blockchain) *storage.MemCachedStore {
+	return bc.GetStateModule().(*stateroot.Module).Store
+}
+
+// compareReceipts returns the description of the first difference between
+// consensus fields of receipts, empty string means they're the same.
+func compareReceipts(r, newR *types.Receipt) string {
+	switch {
+	case r == nil || newR == nil:
+		if r != newR {
+			return "missing receipt"
+		}
+		return ""
+	case r.Status != newR.Status:
+		return fmt.Sprintf("status %d != %d", r.Status, newR.Status)
+	case r.GasUsed != newR.GasUsed:
+		return fmt.Sprintf("gas used %d != %d", r.GasUsed, newR.GasUsed)
+	case r.CumulativeGasUsed != newR.CumulativeGasUsed:
+		return fmt.Sprintf("cumulative gas used %d != %d", r.CumulativeGasUsed, newR.CumulativeGasUsed)
+	case r.ContractAddress != newR.ContractAddress:
+		return fmt.Sprintf("contract address %s != %s", r.ContractAddress, newR.ContractAddress)
+	case len(r.Logs) != len(newR.Logs):
+		return fmt.Sprintf("%d logs != %d", len(r.Logs), len(newR.Logs))
+	}
+	for i := range r.Logs {
+		l, newL := r.Logs[i], newR.Logs[i]
+		if l.Address != newL.Address || !bytes.Equal(l.Data, newL.Data) || len(l.Topics) != len(newL.Topics) {
+			return fmt.Sprintf("log %d mismatch", i)
+		}
+		for j := range l.Topics {
+			if l.Topics[j] != newL.Topics[j] {
+				return fmt.Sprintf("log %d mismatch", i)
+			}
+		}
+	}
+	if r.Bloom != newR.Bloom {
+		return "bloom mismatch"
+	}
+	return ""
+}
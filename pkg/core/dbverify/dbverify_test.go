@@ -0,0 +1,196 @@
+package dbverify
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// counterCode is the init code of a contract incrementing the storage slot 0
+// on every call.
+var counterCode = []byte{0x60, 0x0a, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x0a, 0x60, 0x00, 0xf3,
+	0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00}
+
+func newTestChain(t *testing.T, key *keys.PrivateKey) *core.Blockchain {
+	cfg := config.ProtocolConfiguration{
+		ChainID:                 253,
+		InitialGASSupply:        100000000,
+		MaxBlockGas:             250000000,
+		MaxTraceableBlocks:      200000,
+		MaxTransactionsPerBlock: 512,
+		StandbyValidatorsStr:    []string{hex.EncodeToString(key.PublicKey().Bytes())},
+	}
+	require.NoError(t, cfg.Validate())
+	bc, err := core.NewBlockchain(storage.NewMemoryStore(), cfg, zap.NewNop())
+	require.NoError(t, err)
+	go bc.Run()
+	t.Cleanup(bc.Close)
+	return bc
+}
+
+// newVerifyChains returns the source chain with 3 blocks deploying and
+// calling the counter contract and the chain with genesis block only.
+func newVerifyChains(t *testing.T) (*core.Blockchain, *core.Blockchain, []*block.Block, common.Address) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	src, dst := newTestChain(t, key), newTestChain(t, key)
+
+	var (
+		owner   = key.PublicKey().Address()
+		counter = crypto.CreateAddress(owner, 0)
+		nonce   uint64
+		blocks  []*block.Block
+	)
+	newTx := func(to *common.Address, data []byte) *transaction.Transaction {
+		tx := transaction.NewTx(&transaction.NeoTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(int64(native.DefaultGasPrice)),
+			Gas:      2000000,
+			From:     owner,
+			To:       to,
+			Value:    big.NewInt(0),
+			Data:     data,
+			Witness: transaction.Witness{
+				InvocationScript:   []byte{0},
+				VerificationScript: []byte{0},
+			},
+		})
+		nonce++
+		return tx
+	}
+	for _, tx := range []*transaction.Transaction{
+		newTx(nil, counterCode),
+		newTx(&counter, nil),
+		newTx(&counter, nil),
+	} {
+		b := &block.Block{
+			Header: block.Header{
+				PrevHash:  src.CurrentBlockHash(),
+				Timestamp: uint64(src.BlockHeight()) + 1,
+				Index:     src.BlockHeight() + 1,
+			},
+			Transactions: []*transaction.Transaction{tx},
+		}
+		b.RebuildMerkleRoot()
+		require.NoError(t, src.AddBlock(b))
+		blocks = append(blocks, b)
+	}
+	return src, dst, blocks, counter
+}
+
+func TestVerify(t *testing.T) {
+	src, dst, _, _ := newVerifyChains(t)
+
+	var verified []uint32
+	d, err := Verify(src, dst, 3, func(h uint32) { verified = append(verified, h) })
+	require.NoError(t, err)
+	require.Nil(t, d)
+	require.Equal(t, []uint32{1, 2, 3}, verified)
+	require.Equal(t, src.GetStateModule().CurrentLocalStateRoot(), dst.GetStateModule().CurrentLocalStateRoot())
+
+	_, err = Verify(src, dst, 4, nil)
+	require.Error(t, err)
+}
+
+func TestVerifyReceiptMismatch(t *testing.T) {
+	src, dst, blocks, _ := newVerifyChains(t)
+
+	tx := blocks[1].Transactions[0]
+	_, r, err := src.GetTransaction(tx.Hash())
+	require.NoError(t, err)
+	r.GasUsed++
+	d := dao.NewSimple(stateStore(src))
+	require.NoError(t, d.StoreAsTransaction(tx, r))
+	_, err = d.Persist()
+	require.NoError(t, err)
+
+	div, err := Verify(src, dst, 3, nil)
+	require.NoError(t, err)
+	require.NotNil(t, div)
+	require.Equal(t, uint32(2), div.Height)
+	require.Contains(t, div.Reason, "transaction "+tx.Hash().String()+" receipt: gas used")
+	require.Equal(t, uint32(2), dst.BlockHeight())
+}
+
+func TestVerifyStateRootMismatch(t *testing.T) {
+	src, dst, _, counter := newVerifyChains(t)
+
+	d, err := Verify(src, dst, 1, nil)
+	require.NoError(t, err)
+	require.Nil(t, d)
+
+	// Corrupt the counter value, so that the next call stores another one.
+	st := dao.NewSimple(stateStore(dst))
+	st.PutStorageItem(counter, common.Hash{}.Bytes(), common.Hash{31: 5}.Bytes())
+	_, err = st.Persist()
+	require.NoError(t, err)
+
+	d, err = Verify(src, dst, 3, nil)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	require.Equal(t, uint32(2), d.Height)
+	require.Equal(t, "state root mismatch", d.Reason)
+	sr, err := src.GetStateModule().GetStateRoot(2)
+	require.NoError(t, err)
+	require.Equal(t, sr.Root, d.StateRoot)
+	require.NotEqual(t, d.StateRoot, d.NewStateRoot)
+	require.NoError(t, d.DiffErr)
+	var found bool
+	for _, kd := range d.Diff {
+		if bytes.Equal(kd.Key, append(counter.Bytes(), common.Hash{}.Bytes()...)) {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestCompareReceipts(t *testing.T) {
+	newReceipt := func() *types.Receipt {
+		return &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			GasUsed:           21000,
+			CumulativeGasUsed: 42000,
+			Logs: []*types.Log{{
+				Address: common.Address{1},
+				Topics:  []common.Hash{{2}},
+				Data:    []byte{3},
+				// Non-consensus fields are ignored.
+				BlockNumber: 5,
+			}},
+		}
+	}
+	r := newReceipt()
+	require.Equal(t, "", compareReceipts(r, newReceipt()))
+	require.Equal(t, "", compareReceipts(nil, nil))
+	require.Equal(t, "missing receipt", compareReceipts(r, nil))
+
+	newR := newReceipt()
+	newR.Logs[0].BlockNumber = 6
+	require.Equal(t, "", compareReceipts(r, newR))
+
+	newR.Status = types.ReceiptStatusFailed
+	require.Equal(t, "status 1 != 0", compareReceipts(r, newR))
+
+	newR = newReceipt()
+	newR.Logs[0].Topics[0] = common.Hash{3}
+	require.Equal(t, "log 0 mismatch", compareReceipts(r, newR))
+
+	newR = newReceipt()
+	newR.Logs = nil
+	require.Equal(t, "1 logs != 0", compareReceipts(r, newR))
+}
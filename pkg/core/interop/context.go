@@ -46,34 +46,49 @@ func NewContext(block *block.Block, tx *transaction.Transaction, sdb *statedb.St
 		caller: tx.From(),
 	}
 	ctx.bctx = newEVMBlockContext(block, chain, chain.GetConfig())
+	ctx.bctx.OnDeploy = ctx.onDeploy
+	ctx.bctx.OnCreated = ctx.onCreated
+	policy := chain.Contracts().Policy
+	if gas := policy.GetMaxBlockGas(ctx.Dao()); gas != 0 {
+		ctx.bctx.GasLimit = gas
+	}
+	ctx.bctx.MaxCodeSize = policy.GetMaxContractSize(ctx.Dao())
 	txContext := vm.TxContext{
 		Origin:   tx.From(),
 		GasPrice: tx.GasPrice(),
 	}
-	ctx.VM = NewEVM(ctx.bctx,
-		txContext, sdb, chain.GetConfig(),
-		map[common.Address]vm.NativeContract{
-			native.DesignationAddress: nativeWrapper{
-				nativeContract: chain.Contracts().Designate,
-				ic:             ctx,
-			},
-			native.PolicyAddress: nativeWrapper{
-				nativeContract: chain.Contracts().Policy,
-				ic:             ctx,
-			},
-			native.GASAddress: nativeWrapper{
-				nativeContract: chain.Contracts().GAS,
-				ic:             ctx,
-			},
-			native.ManagementAddress: nativeWrapper{
-				nativeContract: chain.Contracts().Management,
-				ic:             ctx,
-			},
-			native.BridgeAddress: nativeWrapper{
-				nativeContract: chain.Contracts().Bridge,
-				ic:             ctx,
-			},
-		}, tracer)
+	natives := map[common.Address]vm.NativeContract{
+		native.DesignationAddress: nativeWrapper{
+			nativeContract: chain.Contracts().Designate,
+			ic:             ctx,
+		},
+		native.PolicyAddress: nativeWrapper{
+			nativeContract: chain.Contracts().Policy,
+			ic:             ctx,
+		},
+		native.GASAddress: nativeWrapper{
+			nativeContract: chain.Contracts().GAS,
+			ic:             ctx,
+		},
+		native.ManagementAddress: nativeWrapper{
+			nativeContract: chain.Contracts().Management,
+			ic:             ctx,
+		},
+		native.BridgeAddress: nativeWrapper{
+			nativeContract: chain.Contracts().Bridge,
+			ic:             ctx,
+		},
+		native.OracleAddress: nativeWrapper{
+			nativeContract: chain.Contracts().Oracle,
+			ic:             ctx,
+		},
+	}
+	for _, c := range chain.Contracts().Contracts {
+		if !chain.Contracts().IsActive(c.Name, block.Index) {
+			delete(natives, c.Address)
+		}
+	}
+	ctx.VM = NewEVM(ctx.bctx, txContext, sdb, chain.GetConfig(), natives, tracer)
 	return ctx, nil
 }
 
@@ -139,3 +154,26 @@ func (c Context) Address() common.Address {
 func (c Context) Container() *transaction.Transaction {
 	return c.Tx
 }
+
+// onDeploy checks that the transaction sender can deploy contracts, the
+// immediate deployer can be a factory contract.
+func (c *Context) onDeploy(_ vm.StateDB, _ common.Address, _ common.Address) error {
+	return c.Chain.Contracts().Policy.CheckDeploy(c.Dao(), c.Tx.From())
+}
+
+// onCreated accounts the created contract to the transaction sender and
+// saves its creator.
+func (c *Context) onCreated(_ vm.StateDB, deployer common.Address, address common.Address) error {
+	if err := c.Chain.Contracts().Policy.AddDeployment(c.Dao(), c.Tx.From()); err != nil {
+		return err
+	}
+	c.Chain.Contracts().Management.SetCreator(c.Dao(), address, deployer)
+	return nil
+}
+
+// Call invokes contract from native contract, caller is restored after call.
+func (c *Context) Call(caller common.Address, to common.Address, input []byte, gas uint64) ([]byte, uint64, error) {
+	prev := c.caller
+	defer func() { c.caller = prev }()
+	return c.VM.Call(vm.AccountRef(caller), to, input, gas, big.NewInt(0))
+}
@@ -0,0 +1,84 @@
+package interop
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/statedb"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain struct {
+	*testNativeContracts
+}
+
+func (t testChain) GetConfig() config.ProtocolConfiguration          { return config.ProtocolConfiguration{} }
+func (t testChain) GetCurrentValidators() ([]*keys.PublicKey, error) { return nil, nil }
+
+func TestContextDeployer(t *testing.T) {
+	var (
+		allowed = common.HexToAddress("0x01")
+		other   = common.HexToAddress("0x02")
+		factory = common.HexToAddress("0xff")
+		// create(0, 0, 0), store the result and return it.
+		factoryCode = common.Hex2Bytes("600060006000f060005260206000f3")
+		// revert(0, 0)
+		revertCode = common.Hex2Bytes("60006000fd")
+	)
+	chain := testChain{newTestNativeContracts()}
+	policy := chain.Contracts().Policy
+	d := dao.NewSimple(storage.NewMemCachedStore(storage.NewMemoryStore()))
+	d.PutStorageItem(native.PolicyAddress, []byte{native.PrefixDeployRestricted}, []byte{1})
+	d.PutStorageItem(native.PolicyAddress, append([]byte{native.PrefixAllowedDeployer}, allowed.Bytes()...),
+		[]byte{0, 0, 0, 0, 0, 0, 0, 2})
+	sdb := statedb.NewStateDB(d, chain)
+	sdb.SetCode(factory, factoryCode)
+
+	newContext := func(from common.Address) *Context {
+		tx := transaction.NewTx(&transaction.NeoTx{From: from, GasPrice: big.NewInt(0), Value: big.NewInt(0)})
+		ctx, err := NewContext(&block.Block{}, tx, sdb, chain, nil)
+		require.NoError(t, err)
+		return ctx
+	}
+	// deploy creates a contract via the factory and returns its address.
+	deploy := func(ctx *Context, sender common.Address) common.Address {
+		ret, _, err := ctx.VM.Call(vm.AccountRef(sender), factory, nil, 1000000, big.NewInt(0))
+		require.NoError(t, err)
+		return common.BytesToAddress(ret)
+	}
+	deployed := func() uint64 {
+		_, n, ok := policy.GetDeployer(sdb.CurrentStore().Simple, allowed)
+		require.True(t, ok)
+		return n
+	}
+
+	// The sender of the transaction is the deployer, not the factory.
+	ctx := newContext(other)
+	require.Equal(t, common.Address{}, deploy(ctx, other))
+
+	// Failed deployment doesn't use the quota.
+	ctx = newContext(allowed)
+	_, _, _, err := ctx.VM.Create(vm.AccountRef(allowed), revertCode, 1000000, big.NewInt(0))
+	require.Error(t, err)
+	require.Equal(t, uint64(0), deployed())
+
+	created := deploy(ctx, allowed)
+	require.NotEqual(t, common.Address{}, created)
+	creator, ok := chain.Contracts().Management.GetCreator(sdb.CurrentStore().Simple, created)
+	require.True(t, ok)
+	require.Equal(t, factory, creator)
+	require.Equal(t, uint64(1), deployed())
+	require.NotEqual(t, common.Address{}, deploy(ctx, allowed))
+	require.Equal(t, uint64(2), deployed())
+	require.Equal(t, common.Address{}, deploy(ctx, allowed))
+	require.Equal(t, uint64(2), deployed())
+}
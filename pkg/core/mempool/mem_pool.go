@@ -31,6 +31,15 @@ var (
 	// ErrConflictsAttribute is returned when transaction conflicts with other transactions
 	// due to its (or theirs) nonce.
 	ErrConflictsNonce = errors.New("conflicts with memory pool due to nonce")
+	// ErrReplaceUnderpriced is returned when transaction replacing the one
+	// with the same nonce doesn't bump gas price enough.
+	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrSenderLimit is returned when sender already has the maximum allowed
+	// number of transactions in the pool.
+	ErrSenderLimit = errors.New("too many transactions from sender")
+	// ErrFeeTooLow is returned when transaction gas price or network fee
+	// doesn't fit the current policy.
+	ErrFeeTooLow = errors.New("fee is too low")
 )
 
 // poolItem represents a transaction in the the Memory pool.
@@ -62,6 +71,19 @@ type Pool struct {
 	dbNonces      map[common.Address]uint64
 	senderMap     map[common.Address]map[uint64]*poolItem
 
+	// queued holds transactions with future nonces per sender, they're
+	// promoted to the verified pool once nonce gap is filled.
+	queued          map[common.Address]map[uint64]*poolItem
+	queuedMap       map[common.Hash]*poolItem
+	queueCapacity   int
+	queuePerAccount int
+	queueLifetime   uint32
+
+	// admission limits, see SetAdmissionPolicy
+	priceBump    uint64
+	maxPerSender int
+	txLifetime   uint32
+
 	capacity   int
 	feePerByte uint64
 	gasPrice   *big.Int
@@ -70,6 +92,9 @@ type Pool struct {
 	resendThreshold uint32
 	resendFunc      func(*transaction.Transaction, interface{})
 
+	acceptFunc func(*transaction.Transaction)
+	admitFunc  func(*transaction.Transaction) error
+
 	// subscriptions for mempool events
 	subscriptionsEnabled bool
 	subscriptionsOn      atomic.Bool
@@ -184,6 +209,17 @@ func (mp *Pool) Add(t *transaction.Transaction, fee Feer, data ...interface{}) e
 	}
 	mp.lock.Lock()
 	defer mp.lock.Unlock()
+	if err := mp.add(pItem, fee); err != nil {
+		return err
+	}
+	mp.accepted(t)
+	mp.promoteQueued(t.From(), fee)
+	return nil
+}
+
+// add is an internal unlocked version of Add.
+func (mp *Pool) add(pItem poolItem, fee Feer) error {
+	t := pItem.txn
 	if mp.containsKey(t.Hash()) {
 		return ErrDup
 	}
@@ -191,9 +227,16 @@ func (mp *Pool) Add(t *transaction.Transaction, fee Feer, data ...interface{}) e
 	if err != nil {
 		return err
 	}
+	if conflict == nil && mp.senderFull(t.From()) {
+		return ErrSenderLimit
+	}
 	if conflict != nil {
 		mp.removeInternal(conflict)
 	}
+	// transaction with the same nonce can't be both pending and queued
+	if queued, ok := mp.queued[t.From()][t.Nonce()]; ok {
+		mp.unqueue(queued)
+	}
 	// if pre pending tx of same sender exists, set priority to previous tx
 	pre, ok := mp.senderMap[pItem.txn.From()][pItem.txn.Nonce()-1]
 	if ok && pItem.priority.Cmp(&pre.priority) == 1 {
@@ -339,14 +382,15 @@ func (mp *Pool) removeItemByIndex(num int) {
 }
 
 // refresh Nonce for sender, just use after batch remove items
-// remove transactions not continue
-func (mp *Pool) RefreshNonce(sender common.Address, dbNonce uint64) {
+// move transactions not continue to the queue and promote queued ones
+func (mp *Pool) RefreshNonce(sender common.Address, dbNonce uint64, feer Feer) {
 	mp.lock.Lock()
 	defer mp.lock.Unlock()
 	nonceMap := mp.senderMap[sender]
+	mp.dropQueued(sender, dbNonce)
 
 	// clear nonce map if no tx from sender
-	if len(nonceMap) == 0 {
+	if len(nonceMap) == 0 && len(mp.queued[sender]) == 0 {
 		delete(mp.dbNonces, sender)
 		delete(mp.pendingNonces, sender)
 		return
@@ -363,18 +407,25 @@ func (mp *Pool) RefreshNonce(sender common.Address, dbNonce uint64) {
 		}
 	}
 
-	// remove txs not continue from dbNonce
+	// remove txs not continue from dbNonce, gapped ones are queued if possible
 	for nonce, tx := range nonceMap {
 		if nonce < dbNonce || nonce > mp.pendingNonces[sender] {
 			mp.removeInternal(tx.txn)
+			if nonce >= dbNonce && mp.queueCapacity > 0 && mp.queuePerAccount > 0 {
+				_ = mp.enqueue(tx)
+			}
 		}
 	}
 
-	// clear nonce map if no tx from sender
 	if len(nonceMap) == 0 {
+		delete(mp.pendingNonces, sender)
+	}
+	mp.promoteQueued(sender, feer)
+
+	// clear nonce map if no tx from sender
+	if len(mp.senderMap[sender]) == 0 && len(mp.queued[sender]) == 0 {
 		delete(mp.dbNonces, sender)
 		delete(mp.pendingNonces, sender)
-		return
 	}
 }
 
@@ -395,7 +446,7 @@ func (mp *Pool) RemoveStale(isOK func(*transaction.Transaction) bool, feer Feer)
 	)
 	senderRefershMap := make(map[common.Address]struct{})
 	for _, itm := range mp.verifiedTxes {
-		if isOK(itm.txn) && mp.checkPolicy(itm.txn, policyChanged) && mp.tryAddSendersFee(itm.txn, feer, true) {
+		if !mp.isExpired(itm.blockStamp, height) && isOK(itm.txn) && mp.checkPolicy(itm.txn, policyChanged) && mp.tryAddSendersFee(itm.txn, feer, true) {
 			newVerifiedTxes = append(newVerifiedTxes, itm)
 			if mp.resendThreshold != 0 {
 				// item is resend at resendThreshold, 2*resendThreshold, 4*resendThreshold ...
@@ -420,6 +471,12 @@ func (mp *Pool) RemoveStale(isOK func(*transaction.Transaction) bool, feer Feer)
 		}
 	}
 
+	// senders with queued txs need refresh too, their gap may be filled
+	// by the block
+	for sender := range mp.expireQueued(height) {
+		senderRefershMap[sender] = struct{}{}
+	}
+
 	if len(staleItems) != 0 {
 		go mp.resendStaleItems(staleItems)
 	}
@@ -456,6 +513,8 @@ func New(capacity int, payerIndex int, enableSubscriptions bool) *Pool {
 		senderMap:            make(map[common.Address]map[uint64]*poolItem, capacity/4),
 		pendingNonces:        make(map[common.Address]uint64, capacity/4),
 		dbNonces:             make(map[common.Address]uint64, capacity/4),
+		queued:               make(map[common.Address]map[uint64]*poolItem),
+		queuedMap:            make(map[common.Hash]*poolItem),
 		verifiedTxes:         make([]poolItem, 0, capacity),
 		capacity:             capacity,
 		payerIndex:           payerIndex,
@@ -470,6 +529,46 @@ func New(capacity int, payerIndex int, enableSubscriptions bool) *Pool {
 	return mp
 }
 
+// SetAdmissionPolicy sets the minimum gas price bump (in percents) required
+// to replace transaction with the same nonce, the maximum number of verified
+// transactions per sender (zero means no limit) and the number of blocks
+// after which transactions are dropped from the pool (zero means never).
+func (mp *Pool) SetAdmissionPolicy(priceBump uint64, maxPerSender int, lifetime uint32) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.priceBump = priceBump
+	mp.maxPerSender = maxPerSender
+	mp.txLifetime = lifetime
+}
+
+// checkReplacement checks whether tx can replace old transaction with the
+// same nonce, its gas price must be higher by at least priceBump percents.
+func (mp *Pool) checkReplacement(old, tx *transaction.Transaction) error {
+	if old.GasPrice().Cmp(tx.GasPrice()) >= 0 {
+		return ErrConflictsNonce
+	}
+	if mp.priceBump == 0 {
+		return nil
+	}
+	threshold := new(big.Int).Mul(old.GasPrice(), new(big.Int).SetUint64(100+mp.priceBump))
+	threshold.Div(threshold, big.NewInt(100))
+	if tx.GasPrice().Cmp(threshold) < 0 {
+		return ErrReplaceUnderpriced
+	}
+	return nil
+}
+
+// senderFull returns whether sender can't have more verified transactions.
+func (mp *Pool) senderFull(sender common.Address) bool {
+	return mp.maxPerSender > 0 && len(mp.senderMap[sender]) >= mp.maxPerSender
+}
+
+// isExpired returns whether transaction added at blockStamp height has
+// outlived pool lifetime.
+func (mp *Pool) isExpired(blockStamp uint32, height uint32) bool {
+	return mp.txLifetime != 0 && height-blockStamp >= mp.txLifetime
+}
+
 // SetResendThreshold sets threshold after which transaction will be considered stale
 // and returned for retransmission by `GetStaleTransactions`.
 func (mp *Pool) SetResendThreshold(h uint32, f func(*transaction.Transaction, interface{})) {
@@ -479,6 +578,44 @@ func (mp *Pool) SetResendThreshold(h uint32, f func(*transaction.Transaction, in
 	mp.resendFunc = f
 }
 
+// SetAcceptCallback sets function called for every new transaction accepted
+// into the pool (either verified or queued). It's called with the pool lock
+// held, so it must not use the Pool.
+func (mp *Pool) SetAcceptCallback(f func(*transaction.Transaction)) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.acceptFunc = f
+}
+
+// SetAdmissionCheck sets function checking whether transaction can be added
+// to the pool (e.g. its sender is not blocked). It's used for queued
+// transactions promoted to the verified pool, the checks done before Add or
+// Enqueue may be outdated for them. It's called with the pool lock held, so
+// it must not use the Pool.
+func (mp *Pool) SetAdmissionCheck(f func(*transaction.Transaction) error) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.admitFunc = f
+}
+
+// checkAdmission checks transaction against the current fee policy and
+// admission check.
+func (mp *Pool) checkAdmission(t *transaction.Transaction, fee Feer) error {
+	if t.GasPrice().Cmp(fee.GetGasPrice()) < 0 || t.Gas() < transaction.CalculateNetworkFee(t, fee.FeePerByte()) {
+		return ErrFeeTooLow
+	}
+	if mp.admitFunc != nil {
+		return mp.admitFunc(t)
+	}
+	return nil
+}
+
+func (mp *Pool) accepted(t *transaction.Transaction) {
+	if mp.acceptFunc != nil {
+		mp.acceptFunc(t)
+	}
+}
+
 func (mp *Pool) resendStaleItems(items []poolItem) {
 	for i := range items {
 		mp.resendFunc(items[i].txn, items[i].data)
@@ -546,12 +683,11 @@ func (mp *Pool) checkTxConflicts(tx *transaction.Transaction, fee Feer) (*transa
 	// Check Conflicts attributes.
 	var conflictToBeRemoved *transaction.Transaction
 	if existTx, ok := mp.senderMap[tx.From()][tx.Nonce()]; ok {
-		if existTx.txn.GasPrice().Cmp(tx.GasPrice()) < 0 {
-			conflictToBeRemoved = existTx.txn
-			(&expectedSenderFee.feeSum).Sub(&expectedSenderFee.feeSum, existTx.txn.Cost())
-		} else {
-			return nil, ErrConflictsNonce
+		if err := mp.checkReplacement(existTx.txn, tx); err != nil {
+			return nil, err
 		}
+		conflictToBeRemoved = existTx.txn
+		(&expectedSenderFee.feeSum).Sub(&expectedSenderFee.feeSum, existTx.txn.Cost())
 	}
 	_, err := checkBalance(tx, expectedSenderFee)
 	return conflictToBeRemoved, err
@@ -0,0 +1,203 @@
+package mempool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testChainID = 53
+
+type FeerStub struct {
+	blockHeight uint32
+	balance     int64
+	gasPrice    int64
+}
+
+func (fs *FeerStub) FeePerByte() uint64 {
+	return 0
+}
+
+func (fs *FeerStub) GetGasPrice() *big.Int {
+	if fs.gasPrice != 0 {
+		return big.NewInt(fs.gasPrice)
+	}
+	return big.NewInt(1)
+}
+
+func (fs *FeerStub) BlockHeight() uint32 {
+	return fs.blockHeight
+}
+
+func (fs *FeerStub) GetUtilityTokenBalance(common.Address) *big.Int {
+	return big.NewInt(fs.balance)
+}
+
+func newTestTx(t *testing.T, acc *wallet.Account, nonce uint64, gasPrice int64) *transaction.Transaction {
+	tx := transaction.NewTx(&transaction.EthTx{
+		Transaction: *types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(gasPrice),
+			Gas:      21000,
+			To:       &common.Address{},
+			Value:    big.NewInt(0),
+		}),
+	})
+	require.NoError(t, acc.SignTx(testChainID, tx))
+	require.NoError(t, tx.Verify(testChainID))
+	return tx
+}
+
+func newTestAccount(t *testing.T) *wallet.Account {
+	pk, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	return wallet.NewAccountFromPrivateKey(pk)
+}
+
+func TestQueuedPromotion(t *testing.T) {
+	fs := &FeerStub{balance: 1000000000}
+	mp := New(10, 0, false)
+	mp.SetQueueLimits(10, 2, 5)
+	acc := newTestAccount(t)
+	from := acc.Address
+	mp.SetDBNonce(from, 0)
+
+	tx2 := newTestTx(t, acc, 2, 1)
+	require.False(t, mp.CheckNonceContinue(tx2))
+	require.NoError(t, mp.Enqueue(tx2, fs))
+	require.ErrorIs(t, mp.Enqueue(tx2, fs), ErrDup)
+	tx1 := newTestTx(t, acc, 1, 1)
+	require.NoError(t, mp.Enqueue(tx1, fs))
+	require.Equal(t, 0, mp.Count())
+	require.Equal(t, 2, mp.QueuedCount())
+	pending, queued := mp.GetSenderTransactions(from)
+	require.Equal(t, 0, len(pending))
+	require.Equal(t, []*transaction.Transaction{tx1, tx2}, queued)
+
+	// account queue is full, higher nonce can't be queued
+	require.ErrorIs(t, mp.Enqueue(newTestTx(t, acc, 3, 1), fs), ErrQueueFull)
+
+	// filling the gap promotes queued transactions
+	require.NoError(t, mp.Add(newTestTx(t, acc, 0, 1), fs))
+	require.Equal(t, 3, mp.Count())
+	require.Equal(t, 0, mp.QueuedCount())
+	require.Equal(t, uint64(3), mp.PendingNonce(from))
+	require.True(t, mp.ContainsKey(tx2.Hash()))
+}
+
+func TestQueuedPromotionPolicy(t *testing.T) {
+	fs := &FeerStub{balance: 1000000000}
+	mp := New(10, 0, false)
+	mp.SetQueueLimits(10, 10, 5)
+	errBlocked := errors.New("blocked")
+	blocked := make(map[common.Address]bool)
+	mp.SetAdmissionCheck(func(tx *transaction.Transaction) error {
+		if blocked[tx.From()] {
+			return errBlocked
+		}
+		return nil
+	})
+
+	// Sender blocked after queueing can't get transactions promoted.
+	acc := newTestAccount(t)
+	mp.SetDBNonce(acc.Address, 0)
+	require.NoError(t, mp.Enqueue(newTestTx(t, acc, 1, 1), fs))
+	tx2 := newTestTx(t, acc, 2, 1)
+	require.NoError(t, mp.Enqueue(tx2, fs))
+	blocked[acc.Address] = true
+	tx0 := newTestTx(t, acc, 0, 1)
+	require.NoError(t, mp.Add(tx0, fs))
+	pending, queued := mp.GetSenderTransactions(acc.Address)
+	require.Equal(t, []*transaction.Transaction{tx0}, pending)
+	require.Equal(t, []*transaction.Transaction{tx2}, queued)
+
+	// Transactions queued before gas price increase are not promoted.
+	acc = newTestAccount(t)
+	mp.SetDBNonce(acc.Address, 0)
+	require.NoError(t, mp.Enqueue(newTestTx(t, acc, 1, 1), fs))
+	fs.gasPrice = 2
+	tx0 = newTestTx(t, acc, 0, 2)
+	require.NoError(t, mp.Add(tx0, fs))
+	pending, queued = mp.GetSenderTransactions(acc.Address)
+	require.Equal(t, []*transaction.Transaction{tx0}, pending)
+	require.Empty(t, queued)
+
+	// Transactions fitting the policy are promoted.
+	acc = newTestAccount(t)
+	mp.SetDBNonce(acc.Address, 0)
+	require.NoError(t, mp.Enqueue(newTestTx(t, acc, 1, 2), fs))
+	require.NoError(t, mp.Add(newTestTx(t, acc, 0, 2), fs))
+	pending, queued = mp.GetSenderTransactions(acc.Address)
+	require.Equal(t, 2, len(pending))
+	require.Empty(t, queued)
+}
+
+func TestQueuedRefreshNonce(t *testing.T) {
+	fs := &FeerStub{balance: 1000000000}
+	mp := New(10, 0, false)
+	mp.SetQueueLimits(10, 10, 5)
+	acc := newTestAccount(t)
+	from := acc.Address
+	mp.SetDBNonce(from, 0)
+
+	require.NoError(t, mp.Enqueue(newTestTx(t, acc, 1, 1), fs))
+	require.NoError(t, mp.Enqueue(newTestTx(t, acc, 2, 1), fs))
+
+	// nonce 0 was accepted in a block
+	senders := mp.RemoveStale(func(*transaction.Transaction) bool { return true }, fs)
+	require.Contains(t, senders, from)
+	mp.RefreshNonce(from, 1, fs)
+	require.Equal(t, 2, mp.Count())
+	require.Equal(t, 0, mp.QueuedCount())
+	require.Equal(t, uint64(3), mp.PendingNonce(from))
+
+	// expired queued transactions are evicted
+	require.NoError(t, mp.Enqueue(newTestTx(t, acc, 5, 1), fs))
+	require.Equal(t, 1, mp.QueuedCount())
+	fs.blockHeight = 5
+	mp.RemoveStale(func(*transaction.Transaction) bool { return true }, fs)
+	require.Equal(t, 0, mp.QueuedCount())
+	require.Equal(t, 2, mp.Count())
+}
+
+func TestQueueDisabled(t *testing.T) {
+	fs := &FeerStub{balance: 1000000000}
+	mp := New(10, 0, false)
+	acc := newTestAccount(t)
+	require.False(t, mp.IsQueueEnabled())
+	require.ErrorIs(t, mp.Enqueue(newTestTx(t, acc, 1, 1), fs), ErrQueueDisabled)
+}
+
+func TestAdmissionPolicy(t *testing.T) {
+	fs := &FeerStub{balance: 1000000000}
+	mp := New(10, 0, false)
+	mp.SetAdmissionPolicy(10, 2, 3)
+	acc := newTestAccount(t)
+	from := acc.Address
+	mp.SetDBNonce(from, 0)
+
+	require.NoError(t, mp.Add(newTestTx(t, acc, 0, 100), fs))
+	require.ErrorIs(t, mp.Add(newTestTx(t, acc, 0, 90), fs), ErrConflictsNonce)
+	require.ErrorIs(t, mp.Add(newTestTx(t, acc, 0, 109), fs), ErrReplaceUnderpriced)
+	require.NoError(t, mp.Add(newTestTx(t, acc, 0, 110), fs))
+	require.Equal(t, 1, mp.Count())
+
+	require.NoError(t, mp.Add(newTestTx(t, acc, 1, 100), fs))
+	require.ErrorIs(t, mp.Add(newTestTx(t, acc, 2, 100), fs), ErrSenderLimit)
+	// replacement doesn't need a new slot
+	require.NoError(t, mp.Add(newTestTx(t, acc, 1, 200), fs))
+
+	fs.blockHeight = 2
+	mp.RemoveStale(func(*transaction.Transaction) bool { return true }, fs)
+	require.Equal(t, 2, mp.Count())
+	fs.blockHeight = 3
+	mp.RemoveStale(func(*transaction.Transaction) bool { return true }, fs)
+	require.Equal(t, 0, mp.Count())
+}
@@ -11,14 +11,27 @@ var (
 			Namespace: "neo_go_evm",
 		},
 	)
+	//mempoolQueuedTx prometheus metric.
+	mempoolQueuedTx = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Mempool Queued TXs",
+			Name:      "mempool_queued_tx",
+			Namespace: "neo_go_evm",
+		},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(
 		mempoolUnsortedTx,
+		mempoolQueuedTx,
 	)
 }
 
 func updateMempoolMetrics(unsortedTxnLen int) {
 	mempoolUnsortedTx.Set(float64(unsortedTxnLen))
 }
+
+func updateQueuedMetrics(queuedTxnLen int) {
+	mempoolQueuedTx.Set(float64(queuedTxnLen))
+}
@@ -0,0 +1,230 @@
+package mempool
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrQueueFull is returned when future-nonce transaction can't be queued
+	// because of either global or per-account queue limits.
+	ErrQueueFull = errors.New("queue is full")
+	// ErrQueueDisabled is returned when future-nonce transaction is added to
+	// the pool that doesn't have queued tier enabled.
+	ErrQueueDisabled = errors.New("queue is disabled")
+	// ErrNonceTooLow is returned when transaction nonce is lower than the
+	// sender's nonce stored in the DB.
+	ErrNonceTooLow = errors.New("nonce too low")
+)
+
+// SetQueueLimits enables queued tier of the pool which holds transactions
+// with future (gapped) nonces. capacity limits the overall number of queued
+// transactions, perAccount limits it for every sender and lifetime is the
+// number of blocks queued transaction is kept for (zero means forever).
+func (mp *Pool) SetQueueLimits(capacity int, perAccount int, lifetime uint32) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.queueCapacity = capacity
+	mp.queuePerAccount = perAccount
+	mp.queueLifetime = lifetime
+}
+
+// IsQueueEnabled returns whether future-nonce transactions can be queued.
+func (mp *Pool) IsQueueEnabled() bool {
+	mp.lock.RLock()
+	defer mp.lock.RUnlock()
+	return mp.queueCapacity > 0 && mp.queuePerAccount > 0
+}
+
+// QueuedCount returns the number of queued transactions.
+func (mp *Pool) QueuedCount() int {
+	mp.lock.RLock()
+	defer mp.lock.RUnlock()
+	return len(mp.queuedMap)
+}
+
+// GetQueuedTransactions returns a slice of queued transactions.
+func (mp *Pool) GetQueuedTransactions() []*transaction.Transaction {
+	mp.lock.RLock()
+	defer mp.lock.RUnlock()
+
+	var t = make([]*transaction.Transaction, 0, len(mp.queuedMap))
+	for _, itm := range mp.queuedMap {
+		t = append(t, itm.txn)
+	}
+	return t
+}
+
+// GetSenderTransactions returns pending and queued transactions of the
+// given sender sorted by nonce.
+func (mp *Pool) GetSenderTransactions(sender common.Address) ([]*transaction.Transaction, []*transaction.Transaction) {
+	mp.lock.RLock()
+	defer mp.lock.RUnlock()
+	return sortedByNonce(mp.senderMap[sender]), sortedByNonce(mp.queued[sender])
+}
+
+func sortedByNonce(m map[uint64]*poolItem) []*transaction.Transaction {
+	var t = make([]*transaction.Transaction, 0, len(m))
+	for _, itm := range m {
+		t = append(t, itm.txn)
+	}
+	sort.Slice(t, func(i, j int) bool { return t[i].Nonce() < t[j].Nonce() })
+	return t
+}
+
+// Enqueue tries to add transaction with a future nonce to the queued tier of
+// the Pool. If the nonce gap has already been filled, transaction is added
+// to the verified pool just like with Add.
+func (mp *Pool) Enqueue(t *transaction.Transaction, fee Feer, data ...interface{}) error {
+	var pItem = poolItem{
+		txn:        t,
+		blockStamp: fee.BlockHeight(),
+		priority:   *t.GasPrice(),
+	}
+	if data != nil {
+		pItem.data = data[0]
+	}
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	if mp.containsKey(t.Hash()) {
+		return ErrDup
+	}
+	if mp.checkNonceContinue(t) {
+		if err := mp.add(pItem, fee); err != nil {
+			return err
+		}
+		mp.accepted(t)
+		mp.promoteQueued(t.From(), fee)
+		return nil
+	}
+	if mp.queueCapacity <= 0 || mp.queuePerAccount <= 0 {
+		return ErrQueueDisabled
+	}
+	if t.Nonce() < mp.dbNonces[t.From()] {
+		return ErrNonceTooLow
+	}
+	if _, ok := mp.queuedMap[t.Hash()]; ok {
+		return ErrDup
+	}
+	if fee.GetUtilityTokenBalance(t.From()).Cmp(t.Cost()) < 0 {
+		return ErrInsufficientFunds
+	}
+	if err := mp.enqueue(&pItem); err != nil {
+		return err
+	}
+	mp.accepted(t)
+	return nil
+}
+
+// enqueue is an internal unlocked version of Enqueue. It replaces queued
+// transaction with the same nonce if the new one has higher gas price and
+// evicts the sender's highest nonce if the account queue is full.
+func (mp *Pool) enqueue(itm *poolItem) error {
+	var (
+		sender = itm.txn.From()
+		nonce  = itm.txn.Nonce()
+		q      = mp.queued[sender]
+	)
+	if existing, ok := q[nonce]; ok {
+		if err := mp.checkReplacement(existing.txn, itm.txn); err != nil {
+			return err
+		}
+		mp.unqueue(existing)
+	} else if len(q) >= mp.queuePerAccount {
+		highest := mp.highestQueued(sender)
+		if highest == nil || highest.txn.Nonce() < nonce {
+			return ErrQueueFull
+		}
+		mp.unqueue(highest)
+	} else if len(mp.queuedMap) >= mp.queueCapacity {
+		return ErrQueueFull
+	}
+	if mp.queued[sender] == nil {
+		mp.queued[sender] = make(map[uint64]*poolItem)
+	}
+	mp.queued[sender][nonce] = itm
+	mp.queuedMap[itm.txn.Hash()] = itm
+	updateQueuedMetrics(len(mp.queuedMap))
+	return nil
+}
+
+// unqueue removes given item from the queued tier.
+func (mp *Pool) unqueue(itm *poolItem) {
+	sender := itm.txn.From()
+	delete(mp.queuedMap, itm.txn.Hash())
+	delete(mp.queued[sender], itm.txn.Nonce())
+	if len(mp.queued[sender]) == 0 {
+		delete(mp.queued, sender)
+	}
+	updateQueuedMetrics(len(mp.queuedMap))
+}
+
+// highestQueued returns the sender's queued item with the highest nonce.
+func (mp *Pool) highestQueued(sender common.Address) *poolItem {
+	var res *poolItem
+	for nonce, itm := range mp.queued[sender] {
+		if res == nil || nonce > res.txn.Nonce() {
+			res = itm
+		}
+	}
+	return res
+}
+
+// lowestQueued returns the sender's queued item with the lowest nonce.
+func (mp *Pool) lowestQueued(sender common.Address) *poolItem {
+	var res *poolItem
+	for nonce, itm := range mp.queued[sender] {
+		if res == nil || nonce < res.txn.Nonce() {
+			res = itm
+		}
+	}
+	return res
+}
+
+// promoteQueued moves the sender's queued transactions into the verified
+// pool while their nonces are continuous. They're checked against the current
+// policy just like the ones added directly, transactions that can't be added
+// (policy changed, not enough funds, pool is full) are dropped.
+func (mp *Pool) promoteQueued(sender common.Address, fee Feer) {
+	for {
+		itm := mp.lowestQueued(sender)
+		if itm == nil || !mp.checkNonceContinue(itm.txn) || mp.senderFull(sender) {
+			return
+		}
+		mp.unqueue(itm)
+		if err := mp.checkAdmission(itm.txn, fee); err != nil {
+			return
+		}
+		if err := mp.add(*itm, fee); err != nil {
+			return
+		}
+	}
+}
+
+// dropQueued removes the sender's queued transactions with nonces lower
+// than dbNonce.
+func (mp *Pool) dropQueued(sender common.Address, dbNonce uint64) {
+	for nonce, itm := range mp.queued[sender] {
+		if nonce < dbNonce {
+			mp.unqueue(itm)
+		}
+	}
+}
+
+// expireQueued removes queued transactions that have been kept for more than
+// queue lifetime and returns the set of senders still having queued
+// transactions.
+func (mp *Pool) expireQueued(height uint32) map[common.Address]struct{} {
+	senders := make(map[common.Address]struct{}, len(mp.queued))
+	for _, itm := range mp.queuedMap {
+		if mp.queueLifetime != 0 && height-itm.blockStamp >= mp.queueLifetime {
+			mp.unqueue(itm)
+			continue
+		}
+		senders[itm.txn.From()] = struct{}{}
+	}
+	return senders
+}
@@ -0,0 +1,135 @@
+package mpt
+
+import (
+	"bytes"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KeyDiff is a single key which has different values in two tries. Nil value
+// means that the key is missing from the corresponding trie.
+type KeyDiff struct {
+	Key      []byte
+	Value    []byte
+	NewValue []byte
+}
+
+// cursor points to a node or to the middle of an extension node key.
+type cursor struct {
+	node Node
+	// ext is the remaining part of the extension node key, nil if the whole
+	// node is pointed to.
+	ext []byte
+}
+
+// Diff returns keys having different values in the trie with the specified
+// root stored in s and the trie with root newRoot stored in newS, at most
+// max keys are returned. Subtries having the same hash are not traversed,
+// so the cost depends on the number of changes rather than on the trie size.
+func Diff(root common.Hash, s *storage.MemCachedStore, newRoot common.Hash, newS *storage.MemCachedStore, max int) ([]KeyDiff, error) {
+	var (
+		res []KeyDiff
+		old = NewTrie(nil, ModeAll, s)
+		cur = NewTrie(nil, ModeAll, newS)
+	)
+	mkCursor := func(h common.Hash) *cursor {
+		if h == (common.Hash{}) {
+			return nil
+		}
+		return &cursor{node: NewHashNode(h)}
+	}
+	err := diff(old, cur, mkCursor(root), mkCursor(newRoot), []byte{}, max, &res)
+	return res, err
+}
+
+func diff(old, cur *Trie, a, b *cursor, path []byte, max int, res *[]KeyDiff) error {
+	if len(*res) >= max || sameCursors(a, b) {
+		return nil
+	}
+	aVal, aChildren, err := old.expand(a)
+	if err != nil {
+		return err
+	}
+	bVal, bChildren, err := cur.expand(b)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(aVal, bVal) {
+		*res = append(*res, KeyDiff{Key: fromNibbles(path), Value: aVal, NewValue: bVal})
+	}
+	for i := range aChildren {
+		err := diff(old, cur, aChildren[i], bChildren[i], append(path, byte(i)), max, res)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameCursors returns true if both cursors point to the same subtrie.
+func sameCursors(a, b *cursor) bool {
+	switch {
+	case a == nil || b == nil:
+		return a == b
+	case a.ext == nil && b.ext == nil:
+		return a.node.Hash() == b.node.Hash()
+	case a.ext != nil && b.ext != nil:
+		return bytes.Equal(a.ext, b.ext) &&
+			a.node.(*ExtensionNode).next.Hash() == b.node.(*ExtensionNode).next.Hash()
+	}
+	return false
+}
+
+// expand returns the value stored at the cursor path and cursors for every
+// next nibble of the path.
+func (t *Trie) expand(c *cursor) ([]byte, [16]*cursor, error) {
+	var children [16]*cursor
+	if c == nil {
+		return nil, children, nil
+	}
+	n, err := t.resolve(c.node)
+	if err != nil {
+		return nil, children, err
+	}
+	switch n := n.(type) {
+	case *LeafNode:
+		return n.value, children, nil
+	case *BranchNode:
+		for i := 0; i < lastChild; i++ {
+			if !isEmpty(n.Children[i]) {
+				children[i] = &cursor{node: n.Children[i]}
+			}
+		}
+		var val []byte
+		if !isEmpty(n.Children[lastChild]) {
+			v, err := t.resolve(n.Children[lastChild])
+			if err != nil {
+				return nil, children, err
+			}
+			if leaf, ok := v.(*LeafNode); ok {
+				val = leaf.value
+			}
+		}
+		return val, children, nil
+	case *ExtensionNode:
+		key := c.ext
+		if key == nil {
+			key = n.key
+		}
+		if len(key) == 1 {
+			children[key[0]] = &cursor{node: n.next}
+		} else {
+			children[key[0]] = &cursor{node: n, ext: key[1:]}
+		}
+	}
+	return nil, children, nil
+}
+
+// resolve loads hash node from the store.
+func (t *Trie) resolve(n Node) (Node, error) {
+	if h, ok := n.(*HashNode); ok {
+		return t.getFromStore(h.Hash())
+	}
+	return n, nil
+}
@@ -0,0 +1,57 @@
+package mpt
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiffTestTrie(t *testing.T, m map[string][]byte) (common.Hash, *storage.MemCachedStore) {
+	s := storage.NewMemCachedStore(storage.NewMemoryStore())
+	tr := NewTrie(nil, ModeAll, s)
+	_, err := tr.PutBatch(MapToMPTBatch(m))
+	require.NoError(t, err)
+	tr.Flush(0)
+	return tr.StateRoot(), s
+}
+
+func TestDiff(t *testing.T) {
+	base := map[string][]byte{
+		"\x70\x01\x01":     {1},
+		"\x70\x01\x02":     {2},
+		"\x70\x01\x02\x03": {3},
+		"\x70\x12\x34\x56": {4},
+	}
+	root, s := newDiffTestTrie(t, base)
+
+	changed := make(map[string][]byte, len(base))
+	for k, v := range base {
+		changed[k] = v
+	}
+	changed["\x70\x01\x02"] = []byte{5}
+	delete(changed, "\x70\x12\x34\x56")
+	changed["\x70\x12\x34\x57"] = []byte{6}
+	newRoot, newS := newDiffTestTrie(t, changed)
+
+	d, err := Diff(root, s, root, s, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(d))
+
+	d, err = Diff(root, s, newRoot, newS, 10)
+	require.NoError(t, err)
+	require.Equal(t, []KeyDiff{
+		{Key: []byte{1, 2}, Value: []byte{2}, NewValue: []byte{5}},
+		{Key: []byte{0x12, 0x34, 0x56}, Value: []byte{4}},
+		{Key: []byte{0x12, 0x34, 0x57}, NewValue: []byte{6}},
+	}, d)
+
+	d, err = Diff(root, s, newRoot, newS, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(d))
+
+	d, err = Diff(common.Hash{}, s, root, s, 10)
+	require.NoError(t, err)
+	require.Equal(t, len(base), len(d))
+}
@@ -6,12 +6,15 @@ import (
 
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/util/slice"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 // GetProof returns a proof that key belongs to t.
 // Proof consist of serialized nodes occurring on path from the root to the leaf of key.
+// If key is missing, ErrNotFound is returned along with the proof of absence that
+// can be checked with VerifyAbsenceProof.
 func (t *Trie) GetProof(key []byte) ([][]byte, error) {
 	var proof [][]byte
 	if len(key) > MaxKeyLength {
@@ -29,8 +32,8 @@ func (t *Trie) GetProof(key []byte) ([][]byte, error) {
 func (t *Trie) getProof(curr Node, path []byte, proofs *[][]byte) (Node, error) {
 	switch n := curr.(type) {
 	case *LeafNode:
+		*proofs = append(*proofs, slice.Copy(n.Bytes()))
 		if len(path) == 0 {
-			*proofs = append(*proofs, slice.Copy(n.Bytes()))
 			return n, nil
 		}
 	case *BranchNode:
@@ -43,8 +46,8 @@ func (t *Trie) getProof(curr Node, path []byte, proofs *[][]byte) (Node, error)
 		n.Children[i] = r
 		return n, nil
 	case *ExtensionNode:
+		*proofs = append(*proofs, slice.Copy(n.Bytes()))
 		if bytes.HasPrefix(path, n.key) {
-			*proofs = append(*proofs, slice.Copy(n.Bytes()))
 			r, err := t.getProof(n.next, path[len(n.key):], proofs)
 			if err != nil {
 				return nil, err
@@ -77,3 +80,49 @@ func VerifyProof(rh common.Hash, key []byte, proofs [][]byte) ([]byte, bool) {
 	}
 	return slice.Copy(leaf.(*LeafNode).value), true
 }
+
+// VerifyAbsenceProof verifies that key is missing from the MPT with the specified
+// root hash, proofs are the ones returned by GetProof for the missing key.
+func VerifyAbsenceProof(rh common.Hash, key []byte, proofs [][]byte) bool {
+	if rh == (common.Hash{}) {
+		return true
+	}
+	nodes := make(map[common.Hash]Node, len(proofs))
+	for i := range proofs {
+		var n NodeObject
+		r := io.NewBinReaderFromBuf(proofs[i])
+		n.DecodeBinary(r)
+		if r.Err != nil {
+			return false
+		}
+		nodes[hash.DoubleSha256(proofs[i])] = n.Node
+	}
+	path := toNibbles(key)
+	var curr Node = NewHashNode(rh)
+	for {
+		switch n := curr.(type) {
+		case EmptyNode:
+			return true
+		case *HashNode:
+			next, ok := nodes[n.Hash()]
+			if !ok {
+				return false
+			}
+			curr = next
+		case *LeafNode:
+			return len(path) != 0
+		case *BranchNode:
+			var i byte
+			i, path = splitPath(path)
+			curr = n.Children[i]
+		case *ExtensionNode:
+			if !bytes.HasPrefix(path, n.key) {
+				return true
+			}
+			path = path[len(n.key):]
+			curr = n.next
+		default:
+			return false
+		}
+	}
+}
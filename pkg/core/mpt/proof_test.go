@@ -0,0 +1,41 @@
+package mpt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProofAbsence(t *testing.T) {
+	root, s := newDiffTestTrie(t, map[string][]byte{
+		"\x70\x01\x01":     {1},
+		"\x70\x01\x02":     {2},
+		"\x70\x01\x02\x03": {3},
+		"\x70\x12\x34\x56": {4},
+	})
+	// MapToMPTBatch strips the prefix byte from the keys.
+	tr := NewTrie(NewHashNode(root), ModeAll, s)
+
+	proof, err := tr.GetProof([]byte("\x01\x02"))
+	require.NoError(t, err)
+	val, ok := VerifyProof(root, []byte("\x01\x02"), proof)
+	require.True(t, ok)
+	require.Equal(t, []byte{2}, val)
+	require.False(t, VerifyAbsenceProof(root, []byte("\x01\x02"), proof))
+
+	for _, key := range []string{
+		"\x01\x03",     // empty branch child
+		"\x12\x34\x57", // extension mismatch
+		"\x01\x02\x04", // leaf mismatch
+		"\x01",         // empty branch value
+		"\x02",         // root branch mismatch
+	} {
+		proof, err := tr.GetProof([]byte(key))
+		require.ErrorIs(t, err, ErrNotFound)
+		require.True(t, VerifyAbsenceProof(root, []byte(key), proof), "%x", key)
+		_, ok := VerifyProof(root, []byte(key), proof)
+		require.False(t, ok)
+		// Incomplete proof doesn't prove anything.
+		require.False(t, VerifyAbsenceProof(root, []byte(key), proof[:len(proof)-1]), "%x", key)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,15 @@ func TestAddressType(t *testing.T) {
 	t.Log(ty.Elem().Kind())
 	t.Log(ty)
 }
+
+func TestContractsIsActive(t *testing.T) {
+	cs := NewContracts(config.ProtocolConfiguration{})
+	assert.True(t, cs.IsActive(nativenames.GAS, 0))
+	assert.False(t, cs.IsActive(nativenames.Oracle, 100))
+
+	cs = NewContracts(config.ProtocolConfiguration{
+		NativeActivations: map[string]uint32{nativenames.Oracle: 10},
+	})
+	assert.False(t, cs.IsActive(nativenames.Oracle, 9))
+	assert.True(t, cs.IsActive(nativenames.Oracle, 10))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -29,6 +30,7 @@ var (
 	ErrEmptyNodeList             = errors.New("node list is empty")
 	ErrLargeNodeList             = errors.New("node list is too large")
 	ErrInvalidRole               = errors.New("invalid role")
+	ErrInvalidIndex              = errors.New("invalid index")
 	ErrInvalidSender             = errors.New("sender check failed")
 	ErrNoBlock                   = errors.New("no persisting block in the context")
 	ErrInitialize                = errors.New("initialize should only execute in genesis block")
@@ -36,6 +38,12 @@ var (
 	ErrInvalidContractCallReturn = errors.New("invalid return value in contract call")
 )
 
+// lateNatives are the native contracts added after networks launch, they're
+// only available since the configured heights.
+var lateNatives = map[string]bool{
+	nativenames.Oracle: true,
+}
+
 type Contracts struct {
 	GAS        *GAS
 	Ledger     *Ledger
@@ -43,12 +51,16 @@ type Contracts struct {
 	Management *Management
 	Policy     *Policy
 	Bridge     *Bridge
+	Oracle     *Oracle
 	Contracts  []state.NativeContract
+
+	activations map[string]uint32
 }
 
 func NewContracts(cfg config.ProtocolConfiguration) *Contracts {
 	cs := &Contracts{
-		Contracts: make([]state.NativeContract, 0, 4),
+		Contracts:   make([]state.NativeContract, 0, 4),
+		activations: cfg.NativeActivations,
 	}
 	cs.GAS = NewGAS(cs, cfg.InitialGASSupply)
 	cs.Contracts = append(cs.Contracts, cs.GAS.NativeContract)
@@ -62,9 +74,21 @@ func NewContracts(cfg config.ProtocolConfiguration) *Contracts {
 	cs.Contracts = append(cs.Contracts, cs.Policy.NativeContract)
 	cs.Bridge = NewBridge(cs, cfg)
 	cs.Contracts = append(cs.Contracts, cs.Bridge.NativeContract)
+	cs.Oracle = NewOracle(cs)
+	cs.Contracts = append(cs.Contracts, cs.Oracle.NativeContract)
 	return cs
 }
 
+// IsActive checks whether the native contract with the given name can be
+// called in the block with the given index.
+func (cs *Contracts) IsActive(name string, index uint32) bool {
+	h, ok := cs.activations[name]
+	if !ok {
+		return !lateNatives[name]
+	}
+	return index >= h
+}
+
 func (cs *Contracts) ByName(name string) *state.NativeContract {
 	name = strings.ToLower(name)
 	for _, ctr := range cs.Contracts {
@@ -102,6 +126,8 @@ func convertType(in reflect.Type) (abi.Type, error) {
 		default:
 			return abi.Type{}, fmt.Errorf("invalid array element type: %s", in.Elem().Name())
 		}
+	case reflect.Bool:
+		return abi.NewType("bool", "bool", nil)
 	case reflect.Uint8:
 		return abi.NewType("uint8", "uint8", nil)
 	case reflect.Uint32:
@@ -110,6 +136,8 @@ func convertType(in reflect.Type) (abi.Type, error) {
 		return abi.NewType("uint64", "uint64", nil)
 	case reflect.Int64:
 		return abi.NewType("int64", "int64", nil)
+	case reflect.String:
+		return abi.NewType("string", "string", nil)
 	case reflect.Ptr:
 		if in == reflect.TypeOf(big.NewInt(0)) {
 			return abi.NewType("uint256", "uint256", nil)
@@ -43,6 +43,9 @@ type DesignationCache struct {
 	rolesChangedFlag bool
 	validators       roleData
 	stateVals        roleData
+	oracles          roleData
+	bridgeRelayers   roleData
+	notaries         roleData
 }
 
 func NewDesignate(cfg config.ProtocolConfiguration) *Designate {
@@ -68,11 +71,13 @@ func NewDesignate(cfg config.ProtocolConfiguration) *Designate {
 }
 
 func (d *Designate) UpdateCache(s *dao.Simple) error {
-	err := d.updateCachedRoleData(d.cache, s, noderoles.Validator)
-	if err != nil {
-		return err
+	for _, r := range noderoles.Roles {
+		err := d.updateCachedRoleData(d.cache, s, r)
+		if err != nil {
+			return err
+		}
 	}
-	return d.updateCachedRoleData(d.cache, s, noderoles.StateValidator)
+	return nil
 }
 
 func (d *Designate) PostPersist(s *dao.Simple, _ *block.Block) error {
@@ -82,12 +87,9 @@ func (d *Designate) PostPersist(s *dao.Simple, _ *block.Block) error {
 func (d *Designate) updateCachedRoleData(cache *DesignationCache, s *dao.Simple, r noderoles.Role) error {
 	d.cache.mutex.Lock()
 	defer d.cache.mutex.Unlock()
-	var v *roleData
-	switch r {
-	case noderoles.StateValidator:
-		v = &cache.stateVals
-	case noderoles.Validator:
-		v = &cache.validators
+	v := cache.getRoleData(r)
+	if v == nil {
+		return ErrInvalidRole
 	}
 	nodeKeys, height, err := d.GetDesignatedByRoleFromStorage(s, r, math.MaxUint32)
 	if err != nil {
@@ -104,6 +106,22 @@ func (d *Designate) updateCachedRoleData(cache *DesignationCache, s *dao.Simple,
 	return nil
 }
 
+func (cache *DesignationCache) getRoleData(r noderoles.Role) *roleData {
+	switch r {
+	case noderoles.Validator:
+		return &cache.validators
+	case noderoles.StateValidator:
+		return &cache.stateVals
+	case noderoles.Oracle:
+		return &cache.oracles
+	case noderoles.BridgeRelayer:
+		return &cache.bridgeRelayers
+	case noderoles.P2PNotary:
+		return &cache.notaries
+	}
+	return nil
+}
+
 func addressFromNodes(r noderoles.Role, nodes keys.PublicKeys) (common.Address, error) {
 	if nodes.Len() == 0 {
 		return common.Address{}, nil
@@ -124,23 +142,37 @@ func (d *Designate) ContractCall_initialize(ic InteropContext) error {
 	return nil
 }
 
-// func (d *Designate) ContractCall_designateAsRole(ic InteropContext, role byte, rawPks []byte) error {
-// 	r := noderoles.Role(role)
-// 	pks := new(keys.PublicKeys)
-// 	err := pks.DecodeBytes(rawPks)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	err = d.checkConsensus(ic)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	err = d.designateAsRole(ic, r, *pks)
-// 	if err == nil {
-// 		log(ic, d.Address, rawPks, d.Abi.Events["designateAsRole"].ID, common.BytesToHash([]byte{role}))
-// 	}
-// 	return err
-// }
+func (d *Designate) ContractCall_designateAsRole(ic InteropContext, role byte, rawPks []byte) error {
+	r := noderoles.Role(role)
+	pks := new(keys.PublicKeys)
+	err := pks.DecodeBytes(rawPks)
+	if err != nil {
+		return err
+	}
+	err = d.checkConsensus(ic)
+	if err != nil {
+		return err
+	}
+	err = d.designateAsRole(ic, r, *pks)
+	if err == nil {
+		log(ic, d.Address, rawPks, d.Abi.Events["designateAsRole"].ID, common.BytesToHash([]byte{role}))
+	}
+	return err
+}
+
+func (d *Designate) ContractCall__View_getDesignatedByRole(ic InteropContext, role byte, index uint32) ([]byte, error) {
+	if ic.PersistingBlock() == nil {
+		return nil, ErrNoBlock
+	}
+	if index > ic.PersistingBlock().Index+1 {
+		return nil, ErrInvalidIndex
+	}
+	pks, _, err := d.GetDesignatedByRole(ic.Dao(), noderoles.Role(role), index)
+	if err != nil {
+		return nil, err
+	}
+	return pks.Bytes(), nil
+}
 
 func (d *Designate) checkConsensus(ic InteropContext) error {
 	if ic.PersistingBlock() == nil {
@@ -180,22 +212,23 @@ func createRoleKey(role noderoles.Role, index uint32) []byte {
 	return key
 }
 
+// MakeRoleKey returns the storage key of the nodes designated for the role
+// since the given index.
+func MakeRoleKey(role noderoles.Role, index uint32) []byte {
+	return createRoleKey(role, index)
+}
+
 func (d *Designate) GetDesignatedByRole(s *dao.Simple, r noderoles.Role, index uint32) (keys.PublicKeys, uint32, error) {
 	if !noderoles.IsValid(r) {
 		return nil, 0, ErrInvalidRole
 	}
 	d.cache.mutex.Lock()
 	defer d.cache.mutex.Unlock()
-	var val roleData
-	switch r {
-	case noderoles.Validator:
-		val = d.cache.validators
-	case noderoles.StateValidator:
-		val = d.cache.stateVals
-	default:
+	val := d.cache.getRoleData(r)
+	if val == nil {
 		return nil, 0, ErrInvalidRole
 	}
-	if val.height <= index {
+	if val.nodes != nil && val.height <= index {
 		return val.nodes.Copy(), val.height, nil
 	}
 	return d.GetDesignatedByRoleFromStorage(s, r, index)
@@ -258,7 +291,11 @@ func (d *Designate) GetSysAddresses(s *dao.Simple, index uint32) ([]common.Addre
 	for i, account := range accounts {
 		addrs[i] = account.Address()
 	}
-	script, err := keys.PublicKeys(accounts[:len(d.StandbyValidators)]).CreateDefaultMultiSigRedeemScript()
+	n := len(d.StandbyValidators)
+	if n > len(accounts) {
+		n = len(accounts)
+	}
+	script, err := keys.PublicKeys(accounts[:n]).CreateDefaultMultiSigRedeemScript()
 	if err != nil {
 		return nil, err
 	}
@@ -277,6 +314,10 @@ func (d *Designate) RequiredGas(ic InteropContext, input []byte) uint64 {
 	switch method.Name {
 	case "initialize":
 		return 0
+	case "getDesignatedByRole":
+		return defaultNativeReadFee
+	case "designateAsRole":
+		return defaultNativeWriteFee
 	default:
 		return 0
 	}
@@ -31,6 +31,7 @@ type interopContext struct {
 	Index     uint32
 	Contracts *Contracts
 	L         []*types.Log
+	Callback  func(caller common.Address, to common.Address, input []byte, gas uint64) ([]byte, uint64, error)
 }
 
 func (ic interopContext) Log(l *types.Log) {
@@ -64,6 +65,13 @@ func (ic interopContext) PersistingBlock() *block.Block {
 	}
 }
 
+func (ic interopContext) Call(caller common.Address, to common.Address, input []byte, gas uint64) ([]byte, uint64, error) {
+	if ic.Callback != nil {
+		return ic.Callback(caller, to, input, gas)
+	}
+	return nil, gas, nil
+}
+
 func TestCommitteeRole(t *testing.T) {
 	pubs, _ := keys.NewPublicKeysFromStrings([]string{
 		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
@@ -76,6 +84,7 @@ func TestCommitteeRole(t *testing.T) {
 	assert.NoError(t, err)
 	ic := interopContext{
 		D: dao,
+		L: make([]*types.Log, 1),
 	}
 	err = des.ContractCall_initialize(ic)
 	assert.NoError(t, err)
@@ -154,6 +163,52 @@ func TestDesignateContractCall(t *testing.T) {
 	assert.Equal(t, "0218cbadb9db833a6b7432a920b6bdb6b822eb2df0d59cfc5d9d590d5dfd97fef4", hex.EncodeToString(ks[0].Bytes()))
 }
 
+func TestDesignateOracle(t *testing.T) {
+	pubs, _ := keys.NewPublicKeysFromStrings([]string{
+		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
+	})
+	dao := dao.NewSimple(storage.NewMemoryStore())
+	des := NewDesignate(config.ProtocolConfiguration{
+		StandbyValidators: pubs,
+	})
+	ic := interopContext{
+		D: dao,
+		L: make([]*types.Log, 1),
+	}
+	err := des.ContractCall_initialize(ic)
+	assert.NoError(t, err)
+
+	k, err := keys.NewPublicKeyFromString("0218cbadb9db833a6b7432a920b6bdb6b822eb2df0d59cfc5d9d590d5dfd97fef4")
+	assert.NoError(t, err)
+	ks := keys.PublicKeys{k}
+	input, err := des.Abi.Pack("designateAsRole", uint8(noderoles.Oracle), ks.Bytes())
+	assert.NoError(t, err)
+	_, err = des.Run(ic, input)
+	assert.ErrorIs(t, err, ErrInvalidSender)
+
+	ic.S, _ = des.GetConsensusAddress(dao, 1)
+	_, err = des.Run(ic, input)
+	assert.NoError(t, err)
+	assert.Equal(t, common.BytesToHash([]byte{byte(noderoles.Oracle)}), ic.L[0].Topics[1])
+
+	input, err = des.Abi.Pack("designateAsRole", uint8(noderoles.P2PNotary+1), ks.Bytes())
+	assert.NoError(t, err)
+	_, err = des.Run(ic, input)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	assert.NoError(t, des.UpdateCache(dao))
+	oracles, height, err := des.GetDesignatedByRole(dao, noderoles.Oracle, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), height)
+	assert.Equal(t, ks, oracles)
+	oracles, _, err = des.GetDesignatedByRole(dao, noderoles.Oracle, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, oracles.Len())
+	notaries, _, err := des.GetDesignatedByRole(dao, noderoles.P2PNotary, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, notaries.Len())
+}
+
 func TestMarshalNativeAbi(t *testing.T) {
 	pubs, _ := keys.NewPublicKeysFromStrings([]string{
 		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
@@ -62,6 +62,11 @@ func makeAccountKey(h common.Address) []byte {
 	return makeAddressKey(prefixAccount, h)
 }
 
+// MakeAccountKey returns the storage key of the GAS account.
+func MakeAccountKey(h common.Address) []byte {
+	return makeAccountKey(h)
+}
+
 func (g *GAS) ContractCall_initialize(ic InteropContext) error {
 	if ic.PersistingBlock() == nil || ic.PersistingBlock().Index != 0 {
 		return ErrInitialize
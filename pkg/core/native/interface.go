@@ -14,4 +14,5 @@ type InteropContext interface {
 	Dao() *dao.Simple
 	Container() *transaction.Transaction
 	PersistingBlock() *block.Block
+	Call(caller common.Address, to common.Address, input []byte, gas uint64) ([]byte, uint64, error)
 }
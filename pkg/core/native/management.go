@@ -1,21 +1,39 @@
 package native
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativeids"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 const (
-	prefixContract = 0x01
+	prefixContract     = 0x01
+	prefixMetadata     = 0x02
+	prefixCreator      = 0x03
+	prefixContractName = 0x04
+
+	// MaxContractNameLength is the maximum length of contract name in metadata.
+	MaxContractNameLength = 64
+	// MaxContractABISize is the maximum size of contract ABI in metadata.
+	MaxContractABISize = 0xffff
 )
 
 var ManagementAddress common.Address = common.Address(common.BytesToAddress([]byte{nativeids.Management}))
 
+var (
+	ErrContractNotFound = errors.New("contract not found")
+	ErrInvalidMetadata  = errors.New("invalid contract metadata")
+)
+
 type Management struct {
 	state.NativeContract
 	cs *Contracts
@@ -123,6 +141,99 @@ func (m *Management) Destroy(s *dao.Simple, addr common.Address) bool {
 	return true
 }
 
+// SetCreator saves the account which created the contract.
+func (m *Management) SetCreator(s *dao.Simple, addr common.Address, creator common.Address) {
+	s.PutStorageItem(m.Address, makeAddressKey(prefixCreator, addr), creator.Bytes())
+}
+
+// GetCreator returns the account which created the contract, ok is false if
+// it's not known (native contracts and contracts created before the creator
+// was tracked).
+func (m *Management) GetCreator(s *dao.Simple, addr common.Address) (creator common.Address, ok bool) {
+	item := s.GetStorageItem(m.Address, makeAddressKey(prefixCreator, addr))
+	if item == nil {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(item), true
+}
+
+// ContractCall_setMetadata attaches name, ABI JSON and source hash to the
+// contract. It can be called by the contract creator or by the validators.
+func (m *Management) ContractCall_setMetadata(ic InteropContext, address common.Address, name string, abiJSON string, sourceHash []byte) error {
+	if m.GetContract(ic.Dao(), address) == nil {
+		return ErrContractNotFound
+	}
+	if creator, ok := m.GetCreator(ic.Dao(), address); !ok || creator != ic.Sender() {
+		if err := m.cs.Designate.checkConsensus(ic); err != nil {
+			return err
+		}
+	}
+	// Zero byte separates the name from the address in the name index.
+	if len(name) > MaxContractNameLength || strings.IndexByte(name, 0) >= 0 ||
+		len(abiJSON) > MaxContractABISize ||
+		(len(sourceHash) != 0 && len(sourceHash) != common.HashLength) {
+		return ErrInvalidMetadata
+	}
+	if len(abiJSON) != 0 {
+		if _, err := abi.JSON(strings.NewReader(abiJSON)); err != nil {
+			return ErrInvalidMetadata
+		}
+	}
+	if old := m.GetMetadata(ic.Dao(), address); old != nil && old.Name != "" {
+		ic.Dao().DeleteStorageItem(m.Address, makeNameKey(old.Name, address))
+	}
+	md := &state.ContractMetadata{
+		Name:       name,
+		ABI:        abiJSON,
+		SourceHash: sourceHash,
+	}
+	item, err := io.ToByteArray(md)
+	if err != nil {
+		return err
+	}
+	ic.Dao().PutStorageItem(m.Address, makeAddressKey(prefixMetadata, address), item)
+	if name != "" {
+		ic.Dao().PutStorageItem(m.Address, makeNameKey(name, address), []byte{})
+	}
+	log(ic, m.Address, []byte(name), m.Abi.Events["setMetadata"].ID, address.Hash())
+	return nil
+}
+
+func makeNameKey(name string, address common.Address) []byte {
+	k := make([]byte, 1, 1+len(name)+1+common.AddressLength)
+	k[0] = prefixContractName
+	k = append(k, name...)
+	k = append(k, 0)
+	return append(k, address.Bytes()...)
+}
+
+// GetMetadata returns contract metadata, nil if it's not set.
+func (m *Management) GetMetadata(s *dao.Simple, addr common.Address) *state.ContractMetadata {
+	item := s.GetStorageItem(m.Address, makeAddressKey(prefixMetadata, addr))
+	if item == nil {
+		return nil
+	}
+	md := &state.ContractMetadata{}
+	err := io.FromByteArray(md, item)
+	if err != nil {
+		panic(err)
+	}
+	return md
+}
+
+// FindContracts returns addresses of contracts with names starting with
+// the given prefix, the result is sorted by name.
+func (m *Management) FindContracts(s *dao.Simple, prefix string) []common.Address {
+	var res []common.Address
+	s.Seek(m.Address, storage.SeekRange{Prefix: append([]byte{prefixContractName}, prefix...)}, func(k, _ []byte) bool {
+		if len(k) >= common.AddressLength {
+			res = append(res, common.BytesToAddress(k[len(k)-common.AddressLength:]))
+		}
+		return true
+	})
+	return res
+}
+
 func (m *Management) RequiredGas(ic InteropContext, input []byte) uint64 {
 	if len(input) < 4 {
 		return 0
@@ -134,6 +245,8 @@ func (m *Management) RequiredGas(ic InteropContext, input []byte) uint64 {
 	switch method.Name {
 	case "initialize":
 		return 0
+	case "setMetadata":
+		return defaultNativeWriteFee
 	default:
 		return 0
 	}
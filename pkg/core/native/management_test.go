@@ -0,0 +1,70 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testABI = `[{"type":"function","name":"get","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}]`
+
+func TestContractMetadata(t *testing.T) {
+	pubs, _ := keys.NewPublicKeysFromStrings([]string{
+		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
+	})
+	d := dao.NewSimple(storage.NewMemoryStore())
+	cs := NewContracts(config.ProtocolConfiguration{
+		StandbyValidators: pubs,
+	})
+	ic := interopContext{
+		D: d,
+		L: make([]*types.Log, 1),
+	}
+	assert.NoError(t, cs.Designate.ContractCall_initialize(ic))
+	m := cs.Management
+
+	creator := common.HexToAddress("0x1234")
+	contract := common.HexToAddress("0x5678")
+	ic.S = creator
+	ic.Index = 1
+	err := m.ContractCall_setMetadata(ic, contract, "token", testABI, nil)
+	assert.ErrorIs(t, err, ErrContractNotFound)
+
+	m.SetCode(d, contract, []byte{0x01})
+	err = m.ContractCall_setMetadata(ic, contract, "token", testABI, nil)
+	assert.ErrorIs(t, err, ErrInvalidSender)
+
+	m.SetCreator(d, contract, creator)
+	err = m.ContractCall_setMetadata(ic, contract, "token", "not an abi", nil)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	err = m.ContractCall_setMetadata(ic, contract, "token", testABI, []byte{1, 2, 3})
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	err = m.ContractCall_setMetadata(ic, contract, "tok\x00en", testABI, nil)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	input, err := m.Abi.Pack("setMetadata", contract, "token", testABI, common.Hash{1}.Bytes())
+	assert.NoError(t, err)
+	_, err = m.Run(ic, input)
+	assert.NoError(t, err)
+	assert.Equal(t, m.Abi.Events["setMetadata"].ID, ic.L[0].Topics[0])
+	assert.Equal(t, &state.ContractMetadata{
+		Name:       "token",
+		ABI:        testABI,
+		SourceHash: common.Hash{1}.Bytes(),
+	}, m.GetMetadata(d, contract))
+	assert.Equal(t, []common.Address{contract}, m.FindContracts(d, "tok"))
+	assert.Equal(t, 0, len(m.FindContracts(d, "nft")))
+
+	// Validators can set metadata for any contract, old name is removed from
+	// the index.
+	ic.S, _ = cs.Designate.GetConsensusAddress(d, 1)
+	assert.NoError(t, m.ContractCall_setMetadata(ic, contract, "nft", "", nil))
+	assert.Equal(t, 0, len(m.FindContracts(d, "tok")))
+	assert.Equal(t, []common.Address{contract}, m.FindContracts(d, ""))
+}
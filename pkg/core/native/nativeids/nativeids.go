@@ -7,9 +7,10 @@ const (
 	Ledger      byte = 0xE3
 	Designation byte = 0xE4
 	Bridge      byte = 0xE5
+	Oracle      byte = 0xE6
 )
 
 // IsValid checks that name is a valid native contract's name.
 func IsValid(id byte) bool {
-	return id >= Policy && id <= Designation
+	return id >= Policy && id <= Oracle
 }
@@ -7,6 +7,7 @@ const (
 	Policy      = "PolicyContract"
 	Designation = "RoleManagement"
 	Bridge      = "Bridge"
+	Oracle      = "OracleContract"
 )
 
 // IsValid checks that name is a valid native contract's name.
@@ -16,5 +17,6 @@ func IsValid(name string) bool {
 		name == GAS ||
 		name == Policy ||
 		name == Designation ||
-		name == Bridge
+		name == Bridge ||
+		name == Oracle
 }
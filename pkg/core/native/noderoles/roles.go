@@ -7,8 +7,32 @@ type Role byte
 const (
 	Validator      Role = 0
 	StateValidator Role = 1
+	Oracle         Role = 2
+	BridgeRelayer  Role = 3
+	P2PNotary      Role = 4
 )
 
+// Roles contains all valid roles in ascending order.
+var Roles = []Role{Validator, StateValidator, Oracle, BridgeRelayer, P2PNotary}
+
 func IsValid(r Role) bool {
-	return r == Validator || r == StateValidator
+	return r <= P2PNotary
+}
+
+// String implements fmt.Stringer interface.
+func (r Role) String() string {
+	switch r {
+	case Validator:
+		return "Validator"
+	case StateValidator:
+		return "StateValidator"
+	case Oracle:
+		return "Oracle"
+	case BridgeRelayer:
+		return "BridgeRelayer"
+	case P2PNotary:
+		return "P2PNotary"
+	default:
+		return "Unknown"
+	}
 }
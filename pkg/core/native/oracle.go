@@ -0,0 +1,345 @@
+package native
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativeids"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// DefaultOracleRequestPrice is the default amount of GAS paid to oracle
+	// nodes for every request, 0.05 GAS.
+	DefaultOracleRequestPrice uint64 = 50000000000000000
+	// MinimumResponseGas is the minimum amount of gas a request should
+	// reserve for its callback.
+	MinimumResponseGas uint64 = 50000
+	// MaxOracleResultSize is the maximum allowed oracle result size.
+	MaxOracleResultSize = 0xffff
+
+	maxURLLength      = 256
+	maxFilterLength   = 128
+	maxCallbackLength = 32
+	maxUserDataLength = 512
+
+	prefixOracleRequestPrice byte = 0x05
+	prefixOracleRequest      byte = 0x07
+	prefixOracleRequestID    byte = 0x09
+)
+
+var (
+	OracleAddress common.Address = common.Address(common.BytesToAddress([]byte{nativeids.Oracle}))
+
+	ErrNoOracleNodes       = errors.New("no oracle nodes designated")
+	ErrNotAContract        = errors.New("caller is not a contract")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrRequestNotFound     = errors.New("oracle request not found")
+	ErrInvalidRequest      = errors.New("invalid oracle request")
+	ErrInvalidResponseCode = errors.New("invalid oracle response code")
+	ErrLargeResult         = errors.New("oracle result is too large")
+)
+
+// oracleCallbackArgs are the arguments passed to callback method:
+// (uint64 id, uint8 code, bytes userData, bytes result).
+var oracleCallbackArgs = mustOracleCallbackArgs()
+
+type Oracle struct {
+	state.NativeContract
+	cs *Contracts
+}
+
+func NewOracle(cs *Contracts) *Oracle {
+	o := &Oracle{
+		NativeContract: state.NativeContract{
+			Name: nativenames.Oracle,
+			Contract: state.Contract{
+				Address:  OracleAddress,
+				CodeHash: hash.Keccak256(OracleAddress[:]),
+				Code:     OracleAddress[:],
+			},
+		},
+		cs: cs,
+	}
+	oracleAbi, contractCalls, err := constructAbi(o)
+	if err != nil {
+		panic(err)
+	}
+	o.Abi = *oracleAbi
+	o.ContractCalls = contractCalls
+	return o
+}
+
+func mustOracleCallbackArgs() abi.Arguments {
+	args := abi.Arguments{}
+	for _, t := range []string{"uint64", "uint8", "bytes", "bytes"} {
+		ty, err := abi.NewType(t, t, nil)
+		if err != nil {
+			panic(err)
+		}
+		args = append(args, abi.Argument{Type: ty})
+	}
+	return args
+}
+
+func makeOracleRequestKey(id uint64) []byte {
+	k := make([]byte, 9)
+	k[0] = prefixOracleRequest
+	binary.BigEndian.PutUint64(k[1:], id)
+	return k
+}
+
+// OracleCallbackSelector returns method id used to invoke callback method.
+func OracleCallbackSelector(method string) []byte {
+	h := hash.Keccak256([]byte(method + "(uint64,uint8,bytes,bytes)"))
+	return h[:4]
+}
+
+// OracleResponseGas returns the amount of gas needed to execute response
+// (without network fee) for the request with given gasForResponse.
+func OracleResponseGas(gasForResponse uint64) uint64 {
+	return defaultNativeWriteFee + gasForResponse
+}
+
+// ContractCall_request creates new oracle request. Caller pays request price and
+// gasForResponse multiplied by current gas price to oracle nodes.
+func (o *Oracle) ContractCall_request(ic InteropContext, url string, filter string, callback string, userData []byte, gasForResponse uint64) ([]byte, error) {
+	if ic.PersistingBlock() == nil {
+		return nil, ErrNoBlock
+	}
+	if len(url) > maxURLLength || len(filter) > maxFilterLength ||
+		len(callback) == 0 || len(callback) > maxCallbackLength ||
+		len(userData) > maxUserDataLength || gasForResponse < MinimumResponseGas {
+		return nil, ErrInvalidRequest
+	}
+	d := ic.Dao()
+	requester := ic.Sender()
+	if o.cs.Management.GetCodeSize(d, requester) == 0 {
+		return nil, ErrNotAContract
+	}
+	nodes, _, err := o.cs.Designate.GetDesignatedByRole(d, noderoles.Oracle, ic.PersistingBlock().Index)
+	if err != nil {
+		return nil, err
+	}
+	if nodes.Len() == 0 {
+		return nil, ErrNoOracleNodes
+	}
+	oracleAddr, err := addressFromNodes(noderoles.Oracle, nodes)
+	if err != nil {
+		return nil, err
+	}
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(gasForResponse), o.cs.Policy.GetGasPrice(d))
+	amount.Add(amount, new(big.Int).SetUint64(o.GetPrice(d)))
+	if o.cs.GAS.GetBalance(d, requester).Cmp(amount) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	o.cs.GAS.SubBalance(d, requester, amount)
+	o.cs.GAS.AddBalance(d, oracleAddr, amount)
+
+	id := o.getNextRequestID(d)
+	req := &state.OracleRequest{
+		GasForResponse:   gasForResponse,
+		URL:              url,
+		Filter:           filter,
+		CallbackContract: requester,
+		CallbackMethod:   callback,
+		UserData:         userData,
+	}
+	if tx := ic.Container(); tx != nil {
+		req.OriginalTxID = tx.Hash()
+	}
+	err = o.putRequest(d, id, req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := o.Abi.Events["request"].Inputs.Pack(url, filter, callback, userData, gasForResponse)
+	if err != nil {
+		return nil, err
+	}
+	log(ic, o.Address, data, o.Abi.Events["request"].ID, common.BigToHash(new(big.Int).SetUint64(id)), requester.Hash())
+	return common.BigToHash(new(big.Int).SetUint64(id)).Bytes(), nil
+}
+
+// ContractCall_finish is invoked by oracle nodes multisig account to deliver
+// response, it removes request and calls back requesting contract.
+func (o *Oracle) ContractCall_finish(ic InteropContext, id uint64, code uint8, result []byte) error {
+	err := o.checkOracleNodes(ic)
+	if err != nil {
+		return err
+	}
+	if !state.OracleResponseCode(code).IsValid() {
+		return ErrInvalidResponseCode
+	}
+	if len(result) > MaxOracleResultSize {
+		return ErrLargeResult
+	}
+	if state.OracleResponseCode(code) != state.Success && len(result) != 0 {
+		return ErrInvalidRequest
+	}
+	d := ic.Dao()
+	req, err := o.GetRequest(d, id)
+	if err != nil {
+		return err
+	}
+	d.DeleteStorageItem(o.Address, makeOracleRequestKey(id))
+	data, err := o.Abi.Events["finish"].Inputs.Pack(id, code, result)
+	if err != nil {
+		return err
+	}
+	log(ic, o.Address, data, o.Abi.Events["finish"].ID, common.BigToHash(new(big.Int).SetUint64(id)))
+	args, err := oracleCallbackArgs.Pack(id, code, req.UserData, result)
+	if err != nil {
+		return err
+	}
+	// Callback failure doesn't affect response, request is finished anyway.
+	_, _, _ = ic.Call(o.Address, req.CallbackContract, append(OracleCallbackSelector(req.CallbackMethod), args...), req.GasForResponse)
+	return nil
+}
+
+func (o *Oracle) ContractCall_setPrice(ic InteropContext, price uint64) error {
+	err := o.cs.Designate.checkConsensus(ic)
+	if err != nil {
+		return err
+	}
+	if price == 0 {
+		return ErrInvalidInput
+	}
+	item := make([]byte, 8)
+	binary.BigEndian.PutUint64(item, price)
+	ic.Dao().PutStorageItem(o.Address, []byte{prefixOracleRequestPrice}, item)
+	log(ic, o.Address, item, o.Abi.Events["setPrice"].ID)
+	return nil
+}
+
+func (o *Oracle) ContractCall__View_getPrice(ic InteropContext) ([]byte, error) {
+	return common.BigToHash(new(big.Int).SetUint64(o.GetPrice(ic.Dao()))).Bytes(), nil
+}
+
+func (o *Oracle) checkOracleNodes(ic InteropContext) error {
+	if ic.PersistingBlock() == nil {
+		return ErrNoBlock
+	}
+	nodes, _, err := o.cs.Designate.GetDesignatedByRole(ic.Dao(), noderoles.Oracle, ic.PersistingBlock().Index)
+	if err != nil {
+		return err
+	}
+	if nodes.Len() == 0 {
+		return ErrNoOracleNodes
+	}
+	addr, err := addressFromNodes(noderoles.Oracle, nodes)
+	if err != nil {
+		return err
+	}
+	if ic.Sender() != addr {
+		return ErrInvalidSender
+	}
+	return nil
+}
+
+func (o *Oracle) GetPrice(d *dao.Simple) uint64 {
+	item := d.GetStorageItem(o.Address, []byte{prefixOracleRequestPrice})
+	if item == nil {
+		return DefaultOracleRequestPrice
+	}
+	return binary.BigEndian.Uint64(item)
+}
+
+func (o *Oracle) getNextRequestID(d *dao.Simple) uint64 {
+	var id uint64
+	item := d.GetStorageItem(o.Address, []byte{prefixOracleRequestID})
+	if item != nil {
+		id = binary.BigEndian.Uint64(item)
+	}
+	next := make([]byte, 8)
+	binary.BigEndian.PutUint64(next, id+1)
+	d.PutStorageItem(o.Address, []byte{prefixOracleRequestID}, next)
+	return id
+}
+
+func (o *Oracle) putRequest(d *dao.Simple, id uint64, req *state.OracleRequest) error {
+	b, err := io.ToByteArray(req)
+	if err != nil {
+		return err
+	}
+	d.PutStorageItem(o.Address, makeOracleRequestKey(id), b)
+	return nil
+}
+
+// GetRequest returns pending oracle request with given id.
+func (o *Oracle) GetRequest(d *dao.Simple, id uint64) (*state.OracleRequest, error) {
+	item := d.GetStorageItem(o.Address, makeOracleRequestKey(id))
+	if item == nil {
+		return nil, ErrRequestNotFound
+	}
+	req := new(state.OracleRequest)
+	err := io.FromByteArray(req, item)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetRequests returns all pending oracle requests.
+func (o *Oracle) GetRequests(d *dao.Simple) (map[uint64]*state.OracleRequest, error) {
+	kvs, err := d.GetStorageItemsWithPrefix(o.Address, []byte{prefixOracleRequest})
+	if err != nil {
+		return nil, err
+	}
+	reqs := make(map[uint64]*state.OracleRequest, len(kvs))
+	for _, kv := range kvs {
+		if len(kv.Key) != 8 {
+			continue
+		}
+		req := new(state.OracleRequest)
+		err := io.FromByteArray(req, kv.Item)
+		if err != nil {
+			return nil, err
+		}
+		reqs[binary.BigEndian.Uint64(kv.Key)] = req
+	}
+	return reqs, nil
+}
+
+func (o *Oracle) RequiredGas(ic InteropContext, input []byte) uint64 {
+	if len(input) < 4 {
+		return 0
+	}
+	method, err := o.Abi.MethodById(input[:4])
+	if err != nil {
+		return 0
+	}
+	switch method.Name {
+	case "getPrice":
+		return defaultNativeReadFee
+	case "request", "setPrice":
+		return defaultNativeWriteFee
+	case "finish":
+		args, err := method.Inputs.Unpack(input[4:])
+		if err != nil || len(args) == 0 {
+			return defaultNativeWriteFee
+		}
+		id, ok := args[0].(uint64)
+		if !ok {
+			return defaultNativeWriteFee
+		}
+		req, err := o.GetRequest(ic.Dao(), id)
+		if err != nil {
+			return defaultNativeWriteFee
+		}
+		return OracleResponseGas(req.GasForResponse)
+	default:
+		return 0
+	}
+}
+
+func (o *Oracle) Run(ic InteropContext, input []byte) ([]byte, error) {
+	return contractCall(o, &o.NativeContract, ic, input)
+}
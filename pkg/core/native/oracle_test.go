@@ -0,0 +1,110 @@
+package native
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOracleRequestAndFinish(t *testing.T) {
+	pubs, _ := keys.NewPublicKeysFromStrings([]string{
+		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
+	})
+	d := dao.NewSimple(storage.NewMemoryStore())
+	cs := NewContracts(config.ProtocolConfiguration{
+		StandbyValidators: pubs,
+	})
+	ic := interopContext{
+		D: d,
+		L: make([]*types.Log, 1),
+	}
+	assert.NoError(t, cs.Designate.ContractCall_initialize(ic))
+
+	requester := common.HexToAddress("0x1234")
+	cs.Management.SetCode(d, requester, []byte{0x01})
+	ic.S = requester
+	ic.Index = 1
+	_, err := cs.Oracle.ContractCall_request(ic, "https://example.com", "$.a", "callback", []byte{1}, MinimumResponseGas)
+	assert.ErrorIs(t, err, ErrNoOracleNodes)
+
+	k, err := keys.NewPublicKeyFromString("0218cbadb9db833a6b7432a920b6bdb6b822eb2df0d59cfc5d9d590d5dfd97fef4")
+	assert.NoError(t, err)
+	assert.NoError(t, cs.Designate.designateAsRole(ic, noderoles.Oracle, keys.PublicKeys{k}))
+	oracleAddr, err := addressFromNodes(noderoles.Oracle, keys.PublicKeys{k})
+	assert.NoError(t, err)
+	ic.Index = 3
+
+	_, err = cs.Oracle.ContractCall_request(ic, "https://example.com", "$.a", "callback", []byte{1}, MinimumResponseGas-1)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = cs.Oracle.ContractCall_request(ic, "https://example.com", "$.a", "callback", []byte{1}, MinimumResponseGas)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(MinimumResponseGas), big.NewInt(int64(DefaultGasPrice)))
+	amount.Add(amount, new(big.Int).SetUint64(DefaultOracleRequestPrice))
+	cs.GAS.AddBalance(d, requester, amount)
+	ic.S = common.HexToAddress("0x5678")
+	_, err = cs.Oracle.ContractCall_request(ic, "https://example.com", "$.a", "callback", []byte{1}, MinimumResponseGas)
+	assert.ErrorIs(t, err, ErrNotAContract)
+	ic.S = requester
+	ret, err := cs.Oracle.ContractCall_request(ic, "https://example.com", "$.a", "callback", []byte{1}, MinimumResponseGas)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}.Bytes(), ret)
+	assert.Equal(t, 0, cs.GAS.GetBalance(d, requester).Sign())
+	assert.Equal(t, amount, cs.GAS.GetBalance(d, oracleAddr))
+	assert.Equal(t, cs.Oracle.Abi.Events["request"].ID, ic.L[0].Topics[0])
+	assert.Equal(t, requester.Hash(), ic.L[0].Topics[2])
+
+	reqs, err := cs.Oracle.GetRequests(d)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reqs))
+	assert.Equal(t, &state.OracleRequest{
+		GasForResponse:   MinimumResponseGas,
+		URL:              "https://example.com",
+		Filter:           "$.a",
+		CallbackContract: requester,
+		CallbackMethod:   "callback",
+		UserData:         []byte{1},
+	}, reqs[0])
+
+	input, err := cs.Oracle.Abi.Pack("finish", uint64(0), uint8(state.Success), []byte("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, OracleResponseGas(MinimumResponseGas), cs.Oracle.RequiredGas(ic, input))
+
+	_, err = cs.Oracle.Run(ic, input)
+	assert.ErrorIs(t, err, ErrInvalidSender)
+
+	var called bool
+	ic.S = oracleAddr
+	ic.Callback = func(caller common.Address, to common.Address, input []byte, gas uint64) ([]byte, uint64, error) {
+		called = true
+		assert.Equal(t, OracleAddress, caller)
+		assert.Equal(t, requester, to)
+		assert.Equal(t, OracleCallbackSelector("callback"), input[:4])
+		args, err := oracleCallbackArgs.Unpack(input[4:])
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{uint64(0), uint8(state.Success), []byte{1}, []byte("1")}, args)
+		assert.Equal(t, MinimumResponseGas, gas)
+		return nil, 0, nil
+	}
+	bad, err := cs.Oracle.Abi.Pack("finish", uint64(0), uint8(0x01), []byte{})
+	assert.NoError(t, err)
+	_, err = cs.Oracle.Run(ic, bad)
+	assert.ErrorIs(t, err, ErrInvalidResponseCode)
+	_, err = cs.Oracle.Run(ic, input)
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, cs.Oracle.Abi.Events["finish"].ID, ic.L[0].Topics[0])
+	_, err = cs.Oracle.GetRequest(d, 0)
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+	_, err = cs.Oracle.Run(ic, input)
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+}
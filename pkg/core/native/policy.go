@@ -11,6 +11,7 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativeids"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/nativenames"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -19,17 +20,50 @@ const (
 	DefaultFeePerByte uint64 = 1000
 	DefaultGasPrice   uint64 = 10000000000 //10GWei
 
-	PrefixFeePerByte    byte = 0x01
-	PrefixGasPrice      byte = 0x08
-	PrefixBlockedAcount byte = 0x11
+	PrefixFeePerByte        byte = 0x01
+	PrefixGasPrice          byte = 0x08
+	PrefixBlockedAcount     byte = 0x11
+	PrefixDeployRestricted  byte = 0x13
+	PrefixAllowedDeployer   byte = 0x14
+	PrefixDeploymentCounter byte = 0x15
+
+	PrefixMaxBlockSize            byte = 0x16
+	PrefixMaxBlockGas             byte = 0x17
+	PrefixMaxTransactionsPerBlock byte = 0x18
+	PrefixMaxTxGas                byte = 0x19
+	PrefixMaxContractSize         byte = 0x1a
+)
+
+const (
+	// MinMaxTxGas is the minimal transaction gas limit, it's enough for a
+	// multisignature transaction changing policy with default fees.
+	MinMaxTxGas uint64 = 10000000
+	// MinMaxBlockSize is the minimal block size limit, it fits the largest
+	// transaction along with the block header and witness.
+	MinMaxBlockSize uint32 = 2 * transaction.MaxTransactionSize
 )
 
 var (
 	PolicyAddress      = common.Address(common.BytesToAddress([]byte{nativeids.Policy}))
 	ErrAccountBlocked  = errors.New("account blocked")
 	ErrContractBlocked = errors.New("contract blocked")
+
+	ErrDeployNotAllowed    = errors.New("contract deployment is not allowed")
+	ErrDeployQuotaExceeded = errors.New("contract deployment quota exceeded")
+
+	ErrInvalidLimit = errors.New("invalid limit")
 )
 
+// limitPrefixes are storage prefixes of limits overriding protocol
+// configuration, 0 (or no value) means that configured value is used.
+var limitPrefixes = []byte{
+	PrefixMaxBlockSize,
+	PrefixMaxBlockGas,
+	PrefixMaxTransactionsPerBlock,
+	PrefixMaxTxGas,
+	PrefixMaxContractSize,
+}
+
 type Policy struct {
 	state.NativeContract
 	cs    *Contracts
@@ -39,6 +73,8 @@ type Policy struct {
 type policyCache struct {
 	feePerByte atomic.Value
 	gasPrice   atomic.Value
+	// limits is a map[byte]uint64 of limit overrides by storage prefix.
+	limits atomic.Value
 }
 
 func NewPolicy(cs *Contracts) *Policy {
@@ -70,6 +106,11 @@ func (p *Policy) createBlockKey(address common.Address) []byte {
 func (p *Policy) UpdateCache(d *dao.Simple) error {
 	p.cache.feePerByte.Store(p.GetFeePerByteFromStorage(d))
 	p.cache.gasPrice.Store(p.GetGasPriceFromStorage(d))
+	limits := make(map[byte]uint64, len(limitPrefixes))
+	for _, prefix := range limitPrefixes {
+		limits[prefix] = p.getLimitFromStorage(d, prefix)
+	}
+	p.cache.limits.Store(limits)
 	return nil
 }
 
@@ -155,6 +196,229 @@ func (p *Policy) ContractCall_unblockAccount(ic InteropContext, address common.A
 	return nil
 }
 
+// ContractCall_setDeployOpen switches between open deployment (anyone can
+// deploy contracts) and allowlist-only deployment.
+func (p *Policy) ContractCall_setDeployOpen(ic InteropContext, open bool) error {
+	err := p.cs.Designate.checkConsensus(ic)
+	if err != nil {
+		return err
+	}
+	var data byte
+	if open {
+		data = 1
+		ic.Dao().DeleteStorageItem(p.Address, []byte{PrefixDeployRestricted})
+	} else {
+		ic.Dao().PutStorageItem(p.Address, []byte{PrefixDeployRestricted}, []byte{1})
+	}
+	log(ic, p.Address, []byte{data}, p.Abi.Events["setDeployOpen"].ID)
+	return nil
+}
+
+// ContractCall_addDeployer allows address to deploy contracts when deployment
+// isn't open. Quota limits the total number of contracts the address can
+// deploy, 0 means no limit. Calling it for allowed deployer updates its quota.
+func (p *Policy) ContractCall_addDeployer(ic InteropContext, address common.Address, quota uint64) error {
+	err := p.cs.Designate.checkConsensus(ic)
+	if err != nil {
+		return err
+	}
+	item := make([]byte, 8)
+	binary.BigEndian.PutUint64(item, quota)
+	ic.Dao().PutStorageItem(p.Address, makeAddressKey(PrefixAllowedDeployer, address), item)
+	log(ic, p.Address, item, p.Abi.Events["addDeployer"].ID, common.BytesToHash(address[:]))
+	return nil
+}
+
+func (p *Policy) ContractCall_removeDeployer(ic InteropContext, address common.Address) error {
+	err := p.cs.Designate.checkConsensus(ic)
+	if err != nil {
+		return err
+	}
+	key := makeAddressKey(PrefixAllowedDeployer, address)
+	item := ic.Dao().GetStorageItem(p.Address, key)
+	if item == nil {
+		return errors.New("deployer isn't allowed")
+	}
+	ic.Dao().DeleteStorageItem(p.Address, key)
+	log(ic, p.Address, nil, p.Abi.Events["removeDeployer"].ID, common.BytesToHash(address[:]))
+	return nil
+}
+
+// IsDeployOpen returns true if anyone can deploy contracts.
+func (p *Policy) IsDeployOpen(d *dao.Simple) bool {
+	return d.GetStorageItem(p.Address, []byte{PrefixDeployRestricted}) == nil
+}
+
+// GetDeployer returns deployment quota and the number of contracts deployed
+// by the address. ok is false if address isn't in the deployer allowlist.
+func (p *Policy) GetDeployer(d *dao.Simple, address common.Address) (quota uint64, deployed uint64, ok bool) {
+	item := d.GetStorageItem(p.Address, makeAddressKey(PrefixAllowedDeployer, address))
+	if item == nil {
+		return 0, 0, false
+	}
+	quota = binary.BigEndian.Uint64(item)
+	counter := d.GetStorageItem(p.Address, makeAddressKey(PrefixDeploymentCounter, address))
+	if counter != nil {
+		deployed = binary.BigEndian.Uint64(counter)
+	}
+	return quota, deployed, true
+}
+
+// IsDeployAllowed returns true if address can deploy a contract now.
+func (p *Policy) IsDeployAllowed(d *dao.Simple, address common.Address) bool {
+	if p.IsDeployOpen(d) {
+		return true
+	}
+	quota, deployed, ok := p.GetDeployer(d, address)
+	return ok && (quota == 0 || deployed < quota)
+}
+
+// CheckDeploy checks that deployer can create a contract. The deployer is the
+// sender of the transaction creating the contract directly or via other
+// contracts, so factory contracts can only be used by allowed accounts and
+// the contracts they create are accounted to the sender. It's a no-op when
+// deployment is open.
+func (p *Policy) CheckDeploy(d *dao.Simple, deployer common.Address) error {
+	_, err := p.checkDeploy(d, deployer)
+	return err
+}
+
+// AddDeployment checks that deployer can create a contract and accounts the
+// successfully created one against its quota. It's a no-op when deployment
+// is open.
+func (p *Policy) AddDeployment(d *dao.Simple, deployer common.Address) error {
+	deployed, err := p.checkDeploy(d, deployer)
+	if err != nil || p.IsDeployOpen(d) {
+		return err
+	}
+	item := make([]byte, 8)
+	binary.BigEndian.PutUint64(item, deployed+1)
+	d.PutStorageItem(p.Address, makeAddressKey(PrefixDeploymentCounter, deployer), item)
+	return nil
+}
+
+func (p *Policy) checkDeploy(d *dao.Simple, deployer common.Address) (uint64, error) {
+	if p.IsDeployOpen(d) {
+		return 0, nil
+	}
+	quota, deployed, ok := p.GetDeployer(d, deployer)
+	if !ok {
+		return 0, ErrDeployNotAllowed
+	}
+	if quota != 0 && deployed >= quota {
+		return 0, ErrDeployQuotaExceeded
+	}
+	return deployed, nil
+}
+
+// ContractCall_setMaxBlockSize overrides MaxBlockSize protocol setting,
+// 0 restores configured value. It can't be lower than MinMaxBlockSize.
+func (p *Policy) ContractCall_setMaxBlockSize(ic InteropContext, size uint32) error {
+	if size != 0 && size < MinMaxBlockSize {
+		return ErrInvalidLimit
+	}
+	return p.setLimit(ic, PrefixMaxBlockSize, uint64(size), "setMaxBlockSize")
+}
+
+// ContractCall_setMaxBlockGas overrides MaxBlockGas protocol setting,
+// 0 restores configured value. It can't be lower than MinMaxTxGas and the
+// maximum transaction gas.
+func (p *Policy) ContractCall_setMaxBlockGas(ic InteropContext, gas uint64) error {
+	if gas != 0 && (gas < MinMaxTxGas || gas < p.getLimitFromStorage(ic.Dao(), PrefixMaxTxGas)) {
+		return ErrInvalidLimit
+	}
+	return p.setLimit(ic, PrefixMaxBlockGas, gas, "setMaxBlockGas")
+}
+
+// ContractCall_setMaxTransactionsPerBlock overrides MaxTransactionsPerBlock
+// protocol setting, 0 restores configured value.
+func (p *Policy) ContractCall_setMaxTransactionsPerBlock(ic InteropContext, count uint32) error {
+	if count > block.MaxTransactionsPerBlock {
+		return ErrInvalidLimit
+	}
+	return p.setLimit(ic, PrefixMaxTransactionsPerBlock, uint64(count), "setMaxTransactionsPerBlock")
+}
+
+// ContractCall_setMaxTxGas sets the maximum gas of a single transaction,
+// 0 means that it's limited by the block gas only. It can't be lower than
+// MinMaxTxGas or exceed the maximum block gas override.
+func (p *Policy) ContractCall_setMaxTxGas(ic InteropContext, gas uint64) error {
+	if gas != 0 {
+		blockGas := p.getLimitFromStorage(ic.Dao(), PrefixMaxBlockGas)
+		if gas < MinMaxTxGas || blockGas != 0 && gas > blockGas {
+			return ErrInvalidLimit
+		}
+	}
+	return p.setLimit(ic, PrefixMaxTxGas, gas, "setMaxTxGas")
+}
+
+// ContractCall_setMaxContractSize sets the maximum size of deployed contract
+// code, 0 restores the default EVM limit.
+func (p *Policy) ContractCall_setMaxContractSize(ic InteropContext, size uint32) error {
+	return p.setLimit(ic, PrefixMaxContractSize, uint64(size), "setMaxContractSize")
+}
+
+func (p *Policy) setLimit(ic InteropContext, prefix byte, value uint64, event string) error {
+	err := p.cs.Designate.checkConsensus(ic)
+	if err != nil {
+		return err
+	}
+	item := make([]byte, 8)
+	binary.BigEndian.PutUint64(item, value)
+	if value == 0 {
+		ic.Dao().DeleteStorageItem(p.Address, []byte{prefix})
+	} else {
+		ic.Dao().PutStorageItem(p.Address, []byte{prefix}, item)
+	}
+	log(ic, p.Address, item, p.Abi.Events[event].ID)
+	return nil
+}
+
+func (p *Policy) getLimitFromStorage(d *dao.Simple, prefix byte) uint64 {
+	item := d.GetStorageItem(p.Address, []byte{prefix})
+	if item == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(item)
+}
+
+// getLimit returns limit override as of the last persisted block, 0 if it's
+// not set.
+func (p *Policy) getLimit(d *dao.Simple, prefix byte) uint64 {
+	val := p.cache.limits.Load()
+	if val != nil {
+		return val.(map[byte]uint64)[prefix]
+	}
+	return p.getLimitFromStorage(d, prefix)
+}
+
+// GetMaxBlockSize returns MaxBlockSize override, 0 if it's not set.
+func (p *Policy) GetMaxBlockSize(d *dao.Simple) uint32 {
+	return uint32(p.getLimit(d, PrefixMaxBlockSize))
+}
+
+// GetMaxBlockGas returns MaxBlockGas override, 0 if it's not set.
+func (p *Policy) GetMaxBlockGas(d *dao.Simple) uint64 {
+	return p.getLimit(d, PrefixMaxBlockGas)
+}
+
+// GetMaxTransactionsPerBlock returns MaxTransactionsPerBlock override, 0 if
+// it's not set.
+func (p *Policy) GetMaxTransactionsPerBlock(d *dao.Simple) uint16 {
+	return uint16(p.getLimit(d, PrefixMaxTransactionsPerBlock))
+}
+
+// GetMaxTxGas returns the maximum transaction gas, 0 if it's not set.
+func (p *Policy) GetMaxTxGas(d *dao.Simple) uint64 {
+	return p.getLimit(d, PrefixMaxTxGas)
+}
+
+// GetMaxContractSize returns the maximum contract code size, 0 if it's not
+// set.
+func (p *Policy) GetMaxContractSize(d *dao.Simple) int {
+	return int(p.getLimit(d, PrefixMaxContractSize))
+}
+
 func (p *Policy) GetFeePerByte(s *dao.Simple) uint64 {
 	val := p.cache.feePerByte.Load()
 	if val != nil {
@@ -220,7 +484,8 @@ func (p *Policy) RequiredGas(ic InteropContext, input []byte) uint64 {
 	switch method.Name {
 	case "initialize":
 		return 0
-	case "setFeePerByte", "setGasPrice":
+	case "setFeePerByte", "setGasPrice", "setDeployOpen", "addDeployer", "removeDeployer",
+		"setMaxBlockSize", "setMaxBlockGas", "setMaxTransactionsPerBlock", "setMaxTxGas", "setMaxContractSize":
 		return defaultNativeWriteFee
 	default:
 		return 0
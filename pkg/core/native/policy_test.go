@@ -83,6 +83,177 @@ func TestBlockAccount(t *testing.T) {
 	assert.True(t, r)
 }
 
+func TestDeployerAllowlist(t *testing.T) {
+	pubs, _ := keys.NewPublicKeysFromStrings([]string{
+		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
+	})
+	dao := dao.NewSimple(storage.NewMemoryStore())
+	des := NewDesignate(config.ProtocolConfiguration{
+		StandbyValidators: pubs,
+	})
+	p := NewPolicy(&Contracts{
+		Designate: des,
+	})
+	ic := interopContext{
+		D: dao,
+		L: make([]*types.Log, 1),
+	}
+	err := des.ContractCall_initialize(ic)
+	assert.NoError(t, err)
+	err = p.ContractCall_initialize(ic)
+	assert.NoError(t, err)
+
+	deployer := common.HexToAddress("0x1234")
+	other := common.HexToAddress("0x5678")
+	assert.True(t, p.IsDeployOpen(dao))
+	assert.NoError(t, p.CheckDeploy(dao, other))
+
+	input, err := p.Abi.Pack("setDeployOpen", false)
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.ErrorIs(t, err, ErrInvalidSender)
+	ic.S, _ = des.GetConsensusAddress(dao, 1)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	assert.False(t, p.IsDeployOpen(dao))
+	assert.ErrorIs(t, p.CheckDeploy(dao, deployer), ErrDeployNotAllowed)
+
+	input, err = p.Abi.Pack("addDeployer", deployer, uint64(2))
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Abi.Events["addDeployer"].ID, ic.L[0].Topics[0])
+	assert.True(t, p.IsDeployAllowed(dao, deployer))
+	assert.False(t, p.IsDeployAllowed(dao, other))
+	// Checks don't use the quota, only created contracts do.
+	assert.NoError(t, p.CheckDeploy(dao, deployer))
+	assert.NoError(t, p.CheckDeploy(dao, deployer))
+	assert.NoError(t, p.CheckDeploy(dao, deployer))
+	assert.ErrorIs(t, p.AddDeployment(dao, other), ErrDeployNotAllowed)
+	assert.NoError(t, p.AddDeployment(dao, deployer))
+	assert.NoError(t, p.AddDeployment(dao, deployer))
+	assert.ErrorIs(t, p.AddDeployment(dao, deployer), ErrDeployQuotaExceeded)
+	assert.ErrorIs(t, p.CheckDeploy(dao, deployer), ErrDeployQuotaExceeded)
+	assert.False(t, p.IsDeployAllowed(dao, deployer))
+	quota, deployed, ok := p.GetDeployer(dao, deployer)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), quota)
+	assert.Equal(t, uint64(2), deployed)
+
+	input, err = p.Abi.Pack("removeDeployer", deployer)
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.Error(t, err)
+	_, _, ok = p.GetDeployer(dao, deployer)
+	assert.False(t, ok)
+
+	input, err = p.Abi.Pack("setDeployOpen", true)
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	assert.True(t, p.IsDeployAllowed(dao, deployer))
+}
+
+func TestLimits(t *testing.T) {
+	pubs, _ := keys.NewPublicKeysFromStrings([]string{
+		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
+	})
+	dao := dao.NewSimple(storage.NewMemoryStore())
+	des := NewDesignate(config.ProtocolConfiguration{
+		StandbyValidators: pubs,
+	})
+	p := NewPolicy(&Contracts{
+		Designate: des,
+	})
+	ic := interopContext{
+		D: dao,
+		L: make([]*types.Log, 1),
+	}
+	err := des.ContractCall_initialize(ic)
+	assert.NoError(t, err)
+	err = p.ContractCall_initialize(ic)
+	assert.NoError(t, err)
+	assert.NoError(t, p.UpdateCache(dao))
+	assert.Equal(t, uint64(0), p.GetMaxBlockGas(dao))
+
+	ic.S, _ = des.GetConsensusAddress(dao, 1)
+	input, err := p.Abi.Pack("setMaxBlockGas", 2*MinMaxTxGas)
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	input, err = p.Abi.Pack("setMaxContractSize", uint32(100))
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	input, err = p.Abi.Pack("setMaxTransactionsPerBlock", uint32(1<<16))
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+
+	// New values take effect after the block is persisted.
+	assert.Equal(t, uint64(0), p.GetMaxBlockGas(dao))
+	assert.NoError(t, p.PostPersist(dao, nil))
+	assert.Equal(t, 2*MinMaxTxGas, p.GetMaxBlockGas(dao))
+	assert.Equal(t, 100, p.GetMaxContractSize(dao))
+	assert.Equal(t, uint16(0), p.GetMaxTransactionsPerBlock(dao))
+
+	input, err = p.Abi.Pack("setMaxBlockGas", uint64(0))
+	assert.NoError(t, err)
+	_, err = p.Run(ic, input)
+	assert.NoError(t, err)
+	assert.NoError(t, p.PostPersist(dao, nil))
+	assert.Equal(t, uint64(0), p.GetMaxBlockGas(dao))
+}
+
+func TestLimitsMinimum(t *testing.T) {
+	pubs, _ := keys.NewPublicKeysFromStrings([]string{
+		"023c4d39a3fd2150407a9d4654430cdce0464eccaaf739eea79d63e2862f989ee6",
+	})
+	dao := dao.NewSimple(storage.NewMemoryStore())
+	des := NewDesignate(config.ProtocolConfiguration{
+		StandbyValidators: pubs,
+	})
+	p := NewPolicy(&Contracts{
+		Designate: des,
+	})
+	ic := interopContext{
+		D: dao,
+		L: make([]*types.Log, 1),
+	}
+	assert.NoError(t, des.ContractCall_initialize(ic))
+	assert.NoError(t, p.ContractCall_initialize(ic))
+	ic.S, _ = des.GetConsensusAddress(dao, 1)
+	run := func(method string, value interface{}) error {
+		input, err := p.Abi.Pack(method, value)
+		assert.NoError(t, err)
+		_, err = p.Run(ic, input)
+		return err
+	}
+
+	// Limits making transactions unable to fit into blocks are rejected.
+	assert.ErrorIs(t, run("setMaxBlockSize", uint32(1)), ErrInvalidLimit)
+	assert.ErrorIs(t, run("setMaxBlockSize", MinMaxBlockSize-1), ErrInvalidLimit)
+	assert.NoError(t, run("setMaxBlockSize", MinMaxBlockSize))
+	assert.ErrorIs(t, run("setMaxBlockGas", uint64(1)), ErrInvalidLimit)
+	assert.ErrorIs(t, run("setMaxBlockGas", MinMaxTxGas-1), ErrInvalidLimit)
+	assert.ErrorIs(t, run("setMaxTxGas", uint64(21000)), ErrInvalidLimit)
+	assert.ErrorIs(t, run("setMaxTxGas", MinMaxTxGas-1), ErrInvalidLimit)
+
+	// Block gas can't be lower than transaction gas and vice versa.
+	assert.NoError(t, run("setMaxTxGas", 2*MinMaxTxGas))
+	assert.ErrorIs(t, run("setMaxBlockGas", MinMaxTxGas), ErrInvalidLimit)
+	assert.NoError(t, run("setMaxBlockGas", 2*MinMaxTxGas))
+	assert.ErrorIs(t, run("setMaxTxGas", 3*MinMaxTxGas), ErrInvalidLimit)
+	assert.NoError(t, run("setMaxTxGas", MinMaxTxGas))
+
+	// Resetting to the configured value is always allowed.
+	assert.NoError(t, run("setMaxBlockSize", uint32(0)))
+	assert.NoError(t, run("setMaxBlockGas", uint64(0)))
+	assert.NoError(t, run("setMaxTxGas", uint64(0)))
+}
+
 func TestEvent(t *testing.T) {
 	p := NewPolicy(nil)
 	e := p.Abi.Events["setFeePerByte"]
@@ -30,6 +30,14 @@ var (
 			Namespace: "neo_go_evm",
 		},
 	)
+	//reexecutedTxs prometheus metric.
+	reexecutedTxs = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of transactions re-executed because of conflicts in parallel execution",
+			Name:      "parallel_reexecuted_transactions_total",
+			Namespace: "neo_go_evm",
+		},
+	)
 )
 
 func init() {
@@ -37,6 +45,7 @@ func init() {
 		blockHeight,
 		persistedHeight,
 		headerHeight,
+		reexecutedTxs,
 	)
 }
 
@@ -51,3 +60,7 @@ func updateHeaderHeightMetric(hHeight int) {
 func updateBlockHeightMetric(bHeight uint32) {
 	blockHeight.Set(float64(bHeight))
 }
+
+func updateReexecutedTxsMetric(n int) {
+	reexecutedTxs.Add(float64(n))
+}
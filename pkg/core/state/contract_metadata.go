@@ -0,0 +1,60 @@
+package state
+
+import (
+	"encoding/json"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ContractMetadata is an optional information about deployed contract
+// attached to it by its creator.
+type ContractMetadata struct {
+	Name string
+	// ABI is a JSON ABI of the contract.
+	ABI string
+	// SourceHash is a hash of contract sources (or compiler metadata) which
+	// can be used for source verification.
+	SourceHash []byte
+}
+
+type contractMetadataJSON struct {
+	Name       string          `json:"name"`
+	ABI        json.RawMessage `json:"abi,omitempty"`
+	SourceHash hexutil.Bytes   `json:"sourceHash,omitempty"`
+}
+
+func (m *ContractMetadata) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteString(m.Name)
+	bw.WriteString(m.ABI)
+	bw.WriteVarBytes(m.SourceHash)
+}
+
+func (m *ContractMetadata) DecodeBinary(br *io.BinReader) {
+	m.Name = br.ReadString()
+	m.ABI = br.ReadString()
+	m.SourceHash = br.ReadVarBytes()
+}
+
+func (m ContractMetadata) MarshalJSON() ([]byte, error) {
+	var abi json.RawMessage
+	if len(m.ABI) != 0 {
+		abi = json.RawMessage(m.ABI)
+	}
+	return json.Marshal(contractMetadataJSON{
+		Name:       m.Name,
+		ABI:        abi,
+		SourceHash: m.SourceHash,
+	})
+}
+
+func (m *ContractMetadata) UnmarshalJSON(data []byte) error {
+	aux := new(contractMetadataJSON)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	m.Name = aux.Name
+	m.ABI = string(aux.ABI)
+	m.SourceHash = aux.SourceHash
+	return nil
+}
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OracleResponseCode represents result code of oracle response.
+type OracleResponseCode byte
+
+// Oracle response codes, compatible with Neo N3 ones.
+const (
+	Success                 OracleResponseCode = 0x00
+	ProtocolNotSupported    OracleResponseCode = 0x10
+	ConsensusUnreachable    OracleResponseCode = 0x12
+	NotFound                OracleResponseCode = 0x14
+	Timeout                 OracleResponseCode = 0x16
+	Forbidden               OracleResponseCode = 0x18
+	ResponseTooLarge        OracleResponseCode = 0x1a
+	InsufficientFunds       OracleResponseCode = 0x1c
+	ContentTypeNotSupported OracleResponseCode = 0x1f
+	Error                   OracleResponseCode = 0xff
+)
+
+// IsValid checks if c is valid response code.
+func (c OracleResponseCode) IsValid() bool {
+	return c == Success || c == ProtocolNotSupported || c == ConsensusUnreachable || c == NotFound ||
+		c == Timeout || c == Forbidden || c == ResponseTooLarge || c == InsufficientFunds ||
+		c == ContentTypeNotSupported || c == Error
+}
+
+// OracleRequest represents oracle request stored by Oracle native contract.
+type OracleRequest struct {
+	OriginalTxID     common.Hash    `json:"originaltxid"`
+	GasForResponse   uint64         `json:"gasforresponse"`
+	URL              string         `json:"url"`
+	Filter           string         `json:"filter,omitempty"`
+	CallbackContract common.Address `json:"callbackcontract"`
+	CallbackMethod   string         `json:"callbackmethod"`
+	UserData         []byte         `json:"userdata"`
+}
+
+// EncodeBinary implements Serializable interface.
+func (o *OracleRequest) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteBytes(o.OriginalTxID[:])
+	bw.WriteU64LE(o.GasForResponse)
+	bw.WriteString(o.URL)
+	bw.WriteString(o.Filter)
+	bw.WriteBytes(o.CallbackContract[:])
+	bw.WriteString(o.CallbackMethod)
+	bw.WriteVarBytes(o.UserData)
+}
+
+// DecodeBinary implements Serializable interface.
+func (o *OracleRequest) DecodeBinary(br *io.BinReader) {
+	br.ReadBytes(o.OriginalTxID[:])
+	o.GasForResponse = br.ReadU64LE()
+	o.URL = br.ReadString()
+	o.Filter = br.ReadString()
+	br.ReadBytes(o.CallbackContract[:])
+	o.CallbackMethod = br.ReadString()
+	o.UserData = br.ReadVarBytes()
+}
@@ -170,6 +170,30 @@ func (s *Module) JumpToState(sr *state.MPTRoot) {
 	s.mpt = mpt.NewTrie(mpt.NewHashNode(sr.Root), s.mode, s.Store)
 }
 
+// Rewind removes state roots above the specified height and makes the state
+// at this height the current local one. It's only intended to be used for
+// offline chain rewinding.
+func (s *Module) Rewind(height uint32) error {
+	sr, err := s.GetStateRoot(height)
+	if err != nil {
+		return fmt.Errorf("can't get state root at %d: %w", height, err)
+	}
+	for i := height + 1; i <= s.CurrentLocalHeight(); i++ {
+		s.Store.Delete(makeStateRootKey(i))
+	}
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, height)
+	s.Store.Put([]byte{byte(storage.DataMPTAux), prefixLocal}, data)
+	if s.CurrentValidatedHeight() > height {
+		s.Store.Put([]byte{byte(storage.DataMPTAux), prefixValidated}, data)
+		s.validatedHeight.Store(height)
+	}
+	s.currentLocal.Store(sr.Root)
+	s.localHeight.Store(height)
+	s.mpt = mpt.NewTrie(mpt.NewHashNode(sr.Root), s.mode, s.Store)
+	return nil
+}
+
 // GC performs garbage collection.
 func (s *Module) GC(index uint32, store storage.Store) time.Duration {
 	if !s.mode.GC() {
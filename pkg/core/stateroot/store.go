@@ -80,3 +80,23 @@ func (s *Module) AddStateRoot(sr *state.MPTRoot) error {
 	updateStateHeightMetric(sr.Index)
 	return nil
 }
+
+// AddLightStateRoot adds state root verified against the known state validators
+// only. It's used by the headers-only node having no local state roots to
+// compare with.
+func (s *Module) AddLightStateRoot(sr *state.MPTRoot) error {
+	if len(sr.Witness.VerificationScript) == 0 {
+		return errors.New("no witness")
+	}
+	if err := s.verifyWitness(sr); err != nil {
+		return err
+	}
+	putStateRoot(s.Store, makeStateRootKey(sr.Index), sr)
+
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, sr.Index)
+	s.Store.Put([]byte{byte(storage.DataMPTAux), prefixValidated}, data)
+	s.validatedHeight.Store(sr.Index)
+	updateStateHeightMetric(sr.Index)
+	return nil
+}
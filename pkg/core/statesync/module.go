@@ -0,0 +1,504 @@
+/*
+Package statesync implements module for the P2P state synchronisation process. The
+module manages state synchronisation for non-archival nodes which are joining the
+network and don't have the ability to resync from the genesis block.
+
+Given the currently available state synchronisation point P, state sync process
+includes the following stages:
+
+1. Fetching headers starting from height 0 up to P.
+2. Fetching the state root for height P signed by trusted state validators.
+3. Fetching MPT nodes for height P starting from the corresponding state root.
+
+After that the node jumps to the state at height P and continues regular
+blocks processing starting from P+1, blocks up to P are never fetched.
+State validators are taken from the node configuration, because the state
+served by peers can't prove itself.
+*/
+package statesync
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// stateSyncStage is a type of state synchronisation stage.
+type stateSyncStage uint8
+
+const (
+	// inactive means that state exchange is disabled by the protocol configuration
+	// or that it's already completed. Can't be combined with other states.
+	inactive stateSyncStage = 1 << iota
+	// none means that state exchange is enabled in the configuration, but
+	// initialisation of the state sync module wasn't yet performed, i.e.
+	// (*Module).Init wasn't called. Can't be combined with other states.
+	none
+	// initialized means that (*Module).Init was called, but other sync stages
+	// are not yet reached (i.e. that headers are requested, but not yet fetched).
+	initialized
+	// headersSynced means that headers for the current state sync point are fetched.
+	headersSynced
+	// rootSynced means that signed state root for the current state sync point
+	// is fetched. Always combined with headersSynced.
+	rootSynced
+	// mptSynced means that MPT nodes for the current state sync point are fetched.
+	// Always combined with headersSynced and rootSynced.
+	mptSynced
+
+	// synced is a combination of stages meaning that all the data required
+	// for the state jump is fetched.
+	synced = headersSynced | rootSynced | mptSynced
+)
+
+// ErrStateMismatch is returned by the jump callback if restored state doesn't
+// match the state root it was restored for (it's not signed by state
+// validators designated in this state).
+var ErrStateMismatch = errors.New("restored state doesn't match signed state root")
+
+// Ledger is the interface required from Blockchain for Module to operate.
+type Ledger interface {
+	AddHeaders(...*block.Header) error
+	BlockHeight() uint32
+	GetConfig() config.ProtocolConfiguration
+	HeaderHeight() uint32
+	VerifyWitness(common.Address, hash.Hashable, *transaction.Witness) error
+}
+
+// Module represents state sync module and aimed to gather state-related data to
+// perform an atomic state jump.
+type Module struct {
+	lock sync.RWMutex
+	log  *zap.Logger
+
+	// syncPoint is the state synchronisation point P we're currently working against.
+	syncPoint uint32
+	// syncStage is the stage of the sync process.
+	syncStage stateSyncStage
+	// syncInterval is the delta between two adjacent state sync points.
+	syncInterval uint32
+	// root is the signed state root for syncPoint.
+	root *state.MPTRoot
+	// badRoots contains state roots which restored state doesn't match.
+	badRoots map[common.Hash]struct{}
+	// validators is the multisignature address of the trusted state
+	// validators, state roots must be signed by it.
+	validators common.Address
+
+	mode     mpt.TrieMode
+	dao      *dao.Simple
+	bc       Ledger
+	stateMod *stateroot.Module
+	mptpool  *Pool
+
+	billet *mpt.Billet
+
+	jumpCallback func(sr *state.MPTRoot) error
+}
+
+// NewModule returns new instance of statesync module. jumpCallback is called
+// with the signed state root once all the state data is fetched.
+func NewModule(bc Ledger, stateMod *stateroot.Module, log *zap.Logger, s *dao.Simple, jumpCallback func(sr *state.MPTRoot) error) *Module {
+	cfg := bc.GetConfig()
+	var mode mpt.TrieMode
+	if cfg.KeepOnlyLatestState {
+		mode |= mpt.ModeLatest
+	}
+	if cfg.RemoveUntraceableBlocks {
+		mode |= mpt.ModeGC
+	}
+	if !cfg.P2PStateExchangeExtensions {
+		return &Module{
+			dao:       s,
+			bc:        bc,
+			stateMod:  stateMod,
+			mode:      mode,
+			syncStage: inactive,
+		}
+	}
+	var validators common.Address
+	if script, err := cfg.StateSyncValidators.CreateDefaultMultiSigRedeemScript(); err == nil {
+		validators = hash.Hash160(script)
+	}
+	return &Module{
+		dao:          s,
+		bc:           bc,
+		stateMod:     stateMod,
+		log:          log,
+		mode:         mode,
+		syncInterval: cfg.StateSyncInterval,
+		badRoots:     make(map[common.Hash]struct{}),
+		validators:   validators,
+		mptpool:      NewPool(),
+		syncStage:    none,
+		jumpCallback: jumpCallback,
+	}
+}
+
+// Init initializes state sync module for the current chain's height.
+func (s *Module) Init(currChainHeight uint32) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.syncStage != none {
+		return errors.New("already initialized or inactive")
+	}
+
+	p := (currChainHeight / s.syncInterval) * s.syncInterval
+	if p < 2*s.syncInterval {
+		// chain is too low to start state exchange process, use the standard sync mechanism
+		s.syncStage = inactive
+		return nil
+	}
+	pOld, err := s.dao.GetStateSyncPoint()
+	if err == nil && s.bc.BlockHeight() >= pOld {
+		// State jump has already been performed, regular blocks processing continues.
+		s.syncStage = inactive
+		return nil
+	}
+	if err == nil && pOld >= p-s.syncInterval {
+		// old point is still valid, so try to resync states for this point.
+		p = pOld
+	} else {
+		if s.bc.BlockHeight() != 0 {
+			// Regular blocks processing was started, so the node has its own
+			// state and can continue processing blocks from there.
+			s.syncStage = inactive
+			return nil
+		}
+		if err == nil {
+			// pOld was found, it is outdated, and chain wasn't completely synchronised for pOld. Need to drop the db.
+			return fmt.Errorf("state sync point %d is found in the storage, "+
+				"but sync process wasn't completed and point is outdated. Please, drop the database manually and restart the node to run state sync process", pOld)
+		}
+
+		// We've reached this point, so chain has genesis block only. As far as we can't ruin
+		// current chain's state until new state is completely fetched, outdated state-related data
+		// will be removed from storage during (*Blockchain).jumpToState(...) execution.
+		// All we need to do right now is to remove genesis-related MPT nodes.
+		err = s.stateMod.CleanStorage()
+		if err != nil {
+			return fmt.Errorf("failed to remove outdated MPT data from storage: %w", err)
+		}
+	}
+
+	s.syncPoint = p
+	s.dao.PutStateSyncPoint(p)
+	s.syncStage = initialized
+	s.log.Info("try to sync state for the latest state synchronisation point",
+		zap.Uint32("point", p),
+		zap.Uint32("evaluated chain's blockHeight", currChainHeight))
+
+	return s.defineSyncStage()
+}
+
+// TemporaryPrefix returns prefix used to store contract storage items during
+// state synchronisation for the given live storage items prefix.
+func TemporaryPrefix(existing storage.KeyPrefix) storage.KeyPrefix {
+	switch existing {
+	case storage.STStorage:
+		return storage.STTempStorage
+	case storage.STTempStorage:
+		return storage.STStorage
+	default:
+		panic(fmt.Sprintf("invalid storage prefix: %x", existing))
+	}
+}
+
+// defineSyncStage sequentially checks and sets sync state process stage after Module
+// initialization. It also performs initialization of MPT Billet if the state
+// root is already known.
+func (s *Module) defineSyncStage() error {
+	if s.bc.HeaderHeight() >= s.syncPoint {
+		s.syncStage |= headersSynced
+		s.log.Info("headers are in sync",
+			zap.Uint32("headerHeight", s.bc.HeaderHeight()))
+	}
+	if s.syncStage&headersSynced == 0 {
+		return nil
+	}
+	sr, err := s.dao.GetStateSyncRoot()
+	if err == nil && sr.Index == s.syncPoint {
+		return s.setRoot(sr)
+	}
+	return nil
+}
+
+// setRoot initializes MPT billet for the given state root. Some MPT nodes may
+// already be stored, so billet is traversed to find the missing ones.
+func (s *Module) setRoot(sr *state.MPTRoot) error {
+	s.root = sr
+	s.billet = mpt.NewBillet(sr.Root, s.mode, TemporaryPrefix(s.dao.Version.StoragePrefix), s.dao.Store)
+	s.syncStage |= rootSynced
+	s.log.Info("MPT billet initialized",
+		zap.Uint32("height", s.syncPoint),
+		zap.String("state root", sr.Root.String()))
+	pool := NewPool()
+	pool.Add(sr.Root, []byte{})
+	err := s.billet.Traverse(func(_ []byte, n mpt.Node, _ []byte) bool {
+		nPaths, ok := pool.TryGet(n.Hash())
+		if !ok {
+			// if this situation occurs, then it's a bug in MPT pool or Traverse.
+			panic("failed to get MPT node from the pool")
+		}
+		pool.Remove(n.Hash())
+		childrenPaths := make(map[common.Hash][][]byte)
+		for _, path := range nPaths {
+			nChildrenPaths := mpt.GetChildrenPaths(path, n)
+			for hash, paths := range nChildrenPaths {
+				childrenPaths[hash] = append(childrenPaths[hash], paths...) // it's OK to have duplicates, they'll be handled by mempool
+			}
+		}
+		pool.Update(nil, childrenPaths)
+		return false
+	}, true)
+	if err != nil {
+		return fmt.Errorf("failed to traverse MPT while initialization: %w", err)
+	}
+	s.mptpool.Update(nil, pool.GetAll())
+	if s.mptpool.Count() == 0 {
+		s.syncStage |= mptSynced
+		s.log.Info("MPT is in sync",
+			zap.Uint32("stateroot height", s.syncPoint))
+		s.checkSyncIsCompleted()
+	}
+	return nil
+}
+
+// AddHeaders validates and adds specified headers to the chain.
+func (s *Module) AddHeaders(hdrs ...*block.Header) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.syncStage != initialized {
+		return errors.New("headers were not requested")
+	}
+
+	hdrsErr := s.bc.AddHeaders(hdrs...)
+	if s.bc.HeaderHeight() >= s.syncPoint {
+		err := s.defineSyncStage()
+		if err != nil {
+			return fmt.Errorf("failed to define current sync stage: %w", err)
+		}
+	}
+	return hdrsErr
+}
+
+// AddStateRoot checks the state root for the current state synchronisation
+// point and starts MPT nodes synchronisation for it. The root must be signed
+// by the trusted state validators from the protocol configuration.
+func (s *Module) AddStateRoot(sr *state.MPTRoot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.syncStage&headersSynced == 0 || s.syncStage&rootSynced != 0 || sr.Index != s.syncPoint {
+		// it can easily happen after receiving the same root from different peers.
+		return nil
+	}
+	if _, ok := s.badRoots[sr.Root]; ok {
+		return fmt.Errorf("%w: %s", ErrStateMismatch, sr.Root.String())
+	}
+	if len(sr.Witness.VerificationScript) == 0 {
+		return errors.New("state root is not signed")
+	}
+	if s.validators == (common.Address{}) {
+		return errors.New("no trusted state validators")
+	}
+	if err := s.bc.VerifyWitness(s.validators, sr, &sr.Witness); err != nil {
+		return fmt.Errorf("state root is not signed by trusted state validators: %w", err)
+	}
+	s.dao.PutStateSyncRoot(sr)
+	return s.setRoot(sr)
+}
+
+// AddMPTNodes tries to add provided set of MPT nodes to the MPT billet if they are
+// not yet collected.
+func (s *Module) AddMPTNodes(nodes [][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.syncStage&rootSynced == 0 || s.syncStage&mptSynced != 0 {
+		return errors.New("MPT nodes were not requested")
+	}
+
+	for _, nBytes := range nodes {
+		var n mpt.NodeObject
+		r := io.NewBinReaderFromBuf(nBytes)
+		n.DecodeBinary(r)
+		if r.Err != nil {
+			return fmt.Errorf("failed to decode MPT node: %w", r.Err)
+		}
+		err := s.restoreNode(n.Node)
+		if err != nil {
+			return err
+		}
+	}
+	if s.mptpool.Count() == 0 {
+		s.syncStage |= mptSynced
+		s.log.Info("MPT is in sync",
+			zap.Uint32("height", s.syncPoint))
+		s.checkSyncIsCompleted()
+	}
+	return nil
+}
+
+func (s *Module) restoreNode(n mpt.Node) error {
+	nPaths, ok := s.mptpool.TryGet(n.Hash())
+	if !ok {
+		// it can easily happen after receiving the same data from different peers.
+		return nil
+	}
+	var childrenPaths = make(map[common.Hash][][]byte)
+	for _, path := range nPaths {
+		// Must clone here in order to avoid future collapse collisions. If the node's refcount>1 then MPT pool
+		// will manage all paths for this node and call RestoreHashNode separately for each of the paths.
+		err := s.billet.RestoreHashNode(path, n.Clone())
+		if err != nil {
+			return fmt.Errorf("failed to restore MPT node with hash %s and path %s: %w", n.Hash().String(), hex.EncodeToString(path), err)
+		}
+		for h, paths := range mpt.GetChildrenPaths(path, n) {
+			childrenPaths[h] = append(childrenPaths[h], paths...) // it's OK to have duplicates, they'll be handled by mempool
+		}
+	}
+
+	s.mptpool.Update(map[common.Hash][][]byte{n.Hash(): nPaths}, childrenPaths)
+
+	for h := range childrenPaths {
+		if child, err := s.billet.GetFromStore(h); err == nil {
+			// child is already in the storage, so we don't need to request it one more time.
+			err = s.restoreNode(child)
+			if err != nil {
+				return fmt.Errorf("unable to restore saved children: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkSyncIsCompleted checks whether state sync process is completed, i.e. headers up to P
+// height are fetched, signed state root and MPT nodes for P height are stored. If so, then
+// jumping to P state sync point occurs. It is not protected by lock, thus caller should take
+// care of it.
+func (s *Module) checkSyncIsCompleted() {
+	if s.syncStage&synced != synced {
+		return
+	}
+	s.log.Info("state is in sync",
+		zap.Uint32("state sync point", s.syncPoint))
+	err := s.jumpCallback(s.root)
+	if errors.Is(err, ErrStateMismatch) {
+		s.log.Error("restored state is rejected, requesting another state root",
+			zap.String("state root", s.root.Root.String()),
+			zap.Error(err))
+		if err = s.resetRoot(); err != nil {
+			s.log.Fatal("failed to remove restored state", zap.Error(err))
+		}
+		return
+	}
+	if err != nil {
+		s.log.Fatal("failed to jump to the latest state sync point", zap.Error(err))
+	}
+	s.syncStage = inactive
+	s.billet = nil
+}
+
+// resetRoot drops the current state root along with the contract storage
+// items restored for it, so that another state root can be fetched.
+func (s *Module) resetRoot() error {
+	s.badRoots[s.root.Root] = struct{}{}
+	s.root = nil
+	s.billet = nil
+	s.mptpool = NewPool()
+	s.syncStage &^= rootSynced | mptSynced
+
+	b := storage.NewMemCachedStore(s.dao.Store)
+	b.Delete([]byte{byte(storage.SYSStateSyncRoot)})
+	s.dao.Store.Seek(storage.SeekRange{Prefix: []byte{byte(TemporaryPrefix(s.dao.Version.StoragePrefix))}}, func(k, _ []byte) bool {
+		b.Delete(k)
+		return true
+	})
+	_, err := b.Persist()
+	return err
+}
+
+// SyncPoint returns the current state synchronisation point P.
+func (s *Module) SyncPoint() uint32 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.syncPoint
+}
+
+// IsActive tells whether state sync module is on and still gathering state
+// synchronisation data (headers, state root or MPT nodes).
+func (s *Module) IsActive() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.syncStage != inactive
+}
+
+// IsInitialized tells whether state sync module does not require initialization.
+// If `false` is returned then Init can be safely called.
+func (s *Module) IsInitialized() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.syncStage != none
+}
+
+// NeedHeaders tells whether the module hasn't completed headers synchronisation.
+func (s *Module) NeedHeaders() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.syncStage == initialized
+}
+
+// NeedStateRoot tells whether the module needs signed state root for the
+// current state synchronisation point.
+func (s *Module) NeedStateRoot() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.syncStage&headersSynced != 0 && s.syncStage&rootSynced == 0
+}
+
+// NeedMPTNodes returns whether the module hasn't completed MPT synchronisation.
+func (s *Module) NeedMPTNodes() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.syncStage&rootSynced != 0 && s.syncStage&mptSynced == 0
+}
+
+// Traverse traverses local MPT nodes starting from the specified root down to its
+// children calling `process` for each serialised node until stop condition is satisfied.
+func (s *Module) Traverse(root common.Hash, process func(node mpt.Node, nodeBytes []byte) bool) error {
+	b := mpt.NewBillet(root, s.mode, 0, storage.NewMemCachedStore(s.dao.Store))
+	return b.Traverse(func(pathToNode []byte, node mpt.Node, nodeBytes []byte) bool {
+		return process(node, nodeBytes)
+	}, false)
+}
+
+// GetUnknownMPTNodesBatch returns set of currently unknown MPT nodes (`limit` at max).
+func (s *Module) GetUnknownMPTNodesBatch(limit int) []common.Hash {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.mptpool.GetBatch(limit)
+}
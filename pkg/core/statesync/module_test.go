@@ -0,0 +1,175 @@
+package statesync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/dao"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/stateroot"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type testLedger struct {
+	cfg          config.ProtocolConfiguration
+	headerHeight uint32
+}
+
+func (l *testLedger) AddHeaders(...*block.Header) error { return nil }
+func (l *testLedger) BlockHeight() uint32               { return 0 }
+func (l *testLedger) GetConfig() config.ProtocolConfiguration {
+	return l.cfg
+}
+func (l *testLedger) HeaderHeight() uint32 { return l.headerHeight }
+func (l *testLedger) VerifyWitness(h common.Address, _ hash.Hashable, w *transaction.Witness) error {
+	if h != w.Address() {
+		return errors.New("witness hash mismatch")
+	}
+	return nil
+}
+
+// newValidators returns state validators keys along with their
+// multisignature verification script.
+func newValidators(t *testing.T, n int) (keys.PublicKeys, []byte) {
+	var pubs keys.PublicKeys
+	for i := 0; i < n; i++ {
+		k, err := keys.NewPrivateKey()
+		require.NoError(t, err)
+		pubs = append(pubs, k.PublicKey())
+	}
+	script, err := pubs.CreateDefaultMultiSigRedeemScript()
+	require.NoError(t, err)
+	return pubs, script
+}
+
+func newTestModule(t *testing.T, bc *testLedger, jump func(sr *state.MPTRoot) error) *Module {
+	d := dao.NewSimple(storage.NewMemoryStore())
+	stateMod := stateroot.NewModule(bc.cfg, bc.VerifyWitness, zaptest.NewLogger(t), d.Store)
+	return NewModule(bc, stateMod, zaptest.NewLogger(t), d, jump)
+}
+
+func TestModule_RestoreMPT(t *testing.T) {
+	validators, script := newValidators(t, 4)
+	bc := &testLedger{
+		cfg: config.ProtocolConfiguration{
+			P2PStateExchangeExtensions: true,
+			StateSyncInterval:          10,
+			StateSyncValidators:        validators,
+		},
+		headerHeight: 35,
+	}
+
+	// Source state served by some peer.
+	src := newTestModule(t, bc, nil)
+	tr := mpt.NewTrie(nil, mpt.ModeAll, src.dao.Store)
+	kvs := map[string][]byte{
+		"\x01\x02":     {1},
+		"\x01\x03":     {2},
+		"\x01\x03\x04": {3},
+		"\x05":         {4},
+	}
+	for k, v := range kvs {
+		require.NoError(t, tr.Put([]byte(k), v))
+	}
+	tr.Flush(0)
+	root := tr.StateRoot()
+
+	var jumped *state.MPTRoot
+	s := newTestModule(t, bc, func(sr *state.MPTRoot) error {
+		jumped = sr
+		return nil
+	})
+	require.True(t, s.IsActive())
+	require.False(t, s.IsInitialized())
+	require.NoError(t, s.Init(35))
+	require.Equal(t, uint32(30), s.SyncPoint())
+	require.False(t, s.NeedHeaders())
+	require.True(t, s.NeedStateRoot())
+
+	sr := &state.MPTRoot{Index: 20, Root: root, Witness: transaction.Witness{VerificationScript: script}}
+	require.NoError(t, s.AddStateRoot(sr)) // wrong index is ignored
+	require.True(t, s.NeedStateRoot())
+	sr.Index = 30
+	sr.Witness.VerificationScript = nil
+	require.Error(t, s.AddStateRoot(sr))
+	sr.Witness.VerificationScript = script
+	require.NoError(t, s.AddStateRoot(sr))
+	require.True(t, s.NeedMPTNodes())
+
+	for i := 0; s.NeedMPTNodes(); i++ {
+		require.True(t, i < 10, "MPT isn't restored")
+		for _, h := range s.GetUnknownMPTNodesBatch(32) {
+			var nodes [][]byte
+			require.NoError(t, src.Traverse(h, func(_ mpt.Node, nodeBytes []byte) bool {
+				nodes = append(nodes, nodeBytes)
+				return false
+			}))
+			require.NoError(t, s.AddMPTNodes(nodes))
+		}
+	}
+	require.Equal(t, sr, jumped)
+	require.False(t, s.IsActive())
+	for k, v := range kvs {
+		actual, err := s.dao.Store.Get(append([]byte{byte(storage.STTempStorage)}, k...))
+		require.NoError(t, err)
+		require.Equal(t, v, actual)
+	}
+}
+
+func TestModule_UntrustedStateRoot(t *testing.T) {
+	validators, _ := newValidators(t, 4)
+	bc := &testLedger{
+		cfg: config.ProtocolConfiguration{
+			P2PStateExchangeExtensions: true,
+			StateSyncInterval:          10,
+			StateSyncValidators:        validators,
+		},
+		headerHeight: 35,
+	}
+	s := newTestModule(t, bc, func(sr *state.MPTRoot) error {
+		t.Fatal("untrusted state is restored")
+		return nil
+	})
+	require.NoError(t, s.Init(35))
+
+	// The root is correctly signed by its own signers and the state served
+	// along with it could designate them as state validators, but they're
+	// not the trusted ones.
+	_, fakeScript := newValidators(t, 1)
+	sr := &state.MPTRoot{Index: 30, Root: common.Hash{1}, Witness: transaction.Witness{VerificationScript: fakeScript}}
+	require.Error(t, s.AddStateRoot(sr))
+	require.True(t, s.NeedStateRoot())
+	require.False(t, s.NeedMPTNodes())
+
+	// No state root is trusted without configured state validators.
+	bc.cfg.StateSyncValidators = nil
+	s = newTestModule(t, bc, nil)
+	require.NoError(t, s.Init(35))
+	require.Error(t, s.AddStateRoot(sr))
+	require.True(t, s.NeedStateRoot())
+}
+
+func TestModule_Inactive(t *testing.T) {
+	bc := &testLedger{
+		cfg: config.ProtocolConfiguration{
+			P2PStateExchangeExtensions: true,
+			StateSyncInterval:          10,
+		},
+	}
+	s := newTestModule(t, bc, nil)
+	require.NoError(t, s.Init(15)) // chain is too low
+	require.False(t, s.IsActive())
+
+	bc.cfg.P2PStateExchangeExtensions = false
+	s = newTestModule(t, bc, nil)
+	require.False(t, s.IsActive())
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/util/slice"
+	"github.com/syndtr/goleveldb/leveldb"
+	"go.etcd.io/bbolt"
+)
+
+// ErrReadOnly is returned on attempt to modify a read-only Store snapshot.
+var ErrReadOnly = errors.New("store is read-only")
+
+// Snapshotter is implemented by Stores able to provide a consistent
+// point-in-time view of their contents.
+type Snapshotter interface {
+	// Snapshot returns a read-only Store that is not affected by any
+	// changes made to the original Store after the call. Snapshot must be
+	// closed to release the resources it holds.
+	Snapshot() (Store, error)
+}
+
+// memorySnapshot is a read-only copy of MemoryStore.
+type memorySnapshot struct {
+	*MemoryStore
+}
+
+// levelDBSnapshot is a read-only Store over LevelDB snapshot.
+type levelDBSnapshot struct {
+	store *LevelDBStore
+	snap  *leveldb.Snapshot
+}
+
+// boltDBSnapshot is a read-only Store over BoltDB read transaction.
+type boltDBSnapshot struct {
+	tx *bbolt.Tx
+}
+
+// Snapshot implements the Snapshotter interface.
+func (s *LevelDBStore) Snapshot() (Store, error) {
+	snap, err := s.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &levelDBSnapshot{store: s, snap: snap}, nil
+}
+
+// Snapshot implements the Snapshotter interface. The snapshot holds a read
+// transaction, so writers that need to grow the database file are blocked
+// until the snapshot is closed.
+func (s *BoltDBStore) Snapshot() (Store, error) {
+	tx, err := s.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &boltDBSnapshot{tx: tx}, nil
+}
+
+// Snapshot implements the Snapshotter interface, it returns a read-only copy
+// of the store.
+func (s *MemoryStore) Snapshot() (Store, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	snap := NewMemoryStore()
+	for k, v := range s.mem {
+		snap.mem[k] = v
+	}
+	for k, v := range s.stor {
+		snap.stor[k] = v
+	}
+	return memorySnapshot{snap}, nil
+}
+
+// Put implements the Store interface, it always returns ErrReadOnly.
+func (s memorySnapshot) Put(key, value []byte) error {
+	return ErrReadOnly
+}
+
+// PutChangeSet implements the Store interface, it always returns ErrReadOnly.
+func (s memorySnapshot) PutChangeSet(puts map[string][]byte, stores map[string][]byte) error {
+	return ErrReadOnly
+}
+
+// SeekGC implements the Store interface, it always returns ErrReadOnly.
+func (s memorySnapshot) SeekGC(rng SeekRange, keep func(k, v []byte) bool) error {
+	return ErrReadOnly
+}
+
+// Get implements the Store interface.
+func (s *levelDBSnapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.snap.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		err = ErrKeyNotFound
+	}
+	return value, err
+}
+
+// Put implements the Store interface, it always returns ErrReadOnly.
+func (s *levelDBSnapshot) Put(key, value []byte) error {
+	return ErrReadOnly
+}
+
+// PutChangeSet implements the Store interface, it always returns ErrReadOnly.
+func (s *levelDBSnapshot) PutChangeSet(puts map[string][]byte, stores map[string][]byte) error {
+	return ErrReadOnly
+}
+
+// Seek implements the Store interface.
+func (s *levelDBSnapshot) Seek(rng SeekRange, f func(k, v []byte) bool) {
+	iter := s.snap.NewIterator(seekRangeToPrefixes(rng), nil)
+	s.store.seek(iter, rng.Backwards, f)
+}
+
+// SeekGC implements the Store interface, it always returns ErrReadOnly.
+func (s *levelDBSnapshot) SeekGC(rng SeekRange, keep func(k, v []byte) bool) error {
+	return ErrReadOnly
+}
+
+// Close implements the Store interface, it releases the snapshot.
+func (s *levelDBSnapshot) Close() error {
+	s.snap.Release()
+	return nil
+}
+
+// Get implements the Store interface.
+func (s *boltDBSnapshot) Get(key []byte) ([]byte, error) {
+	val := s.tx.Bucket(Bucket).Get(key)
+	if val == nil {
+		return nil, ErrKeyNotFound
+	}
+	return slice.Copy(val), nil
+}
+
+// Put implements the Store interface, it always returns ErrReadOnly.
+func (s *boltDBSnapshot) Put(key, value []byte) error {
+	return ErrReadOnly
+}
+
+// PutChangeSet implements the Store interface, it always returns ErrReadOnly.
+func (s *boltDBSnapshot) PutChangeSet(puts map[string][]byte, stores map[string][]byte) error {
+	return ErrReadOnly
+}
+
+// Seek implements the Store interface.
+func (s *boltDBSnapshot) Seek(rng SeekRange, f func(k, v []byte) bool) {
+	view := func(fn func(*bbolt.Tx) error) error { return fn(s.tx) }
+	err := boltSeek(view, rng, func(_ *bbolt.Cursor, k, v []byte) (bool, error) {
+		return f(k, v), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SeekGC implements the Store interface, it always returns ErrReadOnly.
+func (s *boltDBSnapshot) SeekGC(rng SeekRange, keep func(k, v []byte) bool) error {
+	return ErrReadOnly
+}
+
+// Close implements the Store interface, it finishes the read transaction.
+func (s *boltDBSnapshot) Close() error {
+	return s.tx.Rollback()
+}
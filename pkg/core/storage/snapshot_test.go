@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	stores := map[string]func(testing.TB) Store{
+		"memory":  func(testing.TB) Store { return NewMemoryStore() },
+		"leveldb": newLevelDBForTesting,
+		"boltdb":  newBoltStoreForTesting,
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			defer s.Close()
+			k1, k2 := []byte{byte(DataMPT), 1}, []byte{byte(STStorage), 2}
+			require.NoError(t, s.PutChangeSet(map[string][]byte{string(k1): {1}}, map[string][]byte{string(k2): {2}}))
+
+			snap, err := s.(Snapshotter).Snapshot()
+			require.NoError(t, err)
+			done := make(chan error, 1)
+			go func() {
+				done <- s.PutChangeSet(map[string][]byte{string(k1): nil}, map[string][]byte{string(k2): {3}})
+			}()
+			// BoltDB writer can wait for the read transaction to finish.
+			if name != "boltdb" {
+				require.NoError(t, <-done)
+			}
+
+			v, err := snap.Get(k1)
+			require.NoError(t, err)
+			require.Equal(t, []byte{1}, v)
+			var seen int
+			snap.Seek(SeekRange{Prefix: []byte{byte(STStorage)}}, func(k, v []byte) bool {
+				require.Equal(t, k2, k)
+				require.Equal(t, []byte{2}, v)
+				seen++
+				return true
+			})
+			require.Equal(t, 1, seen)
+			require.ErrorIs(t, snap.Put(k1, []byte{1}), ErrReadOnly)
+			require.NoError(t, snap.Close())
+			if name == "boltdb" {
+				require.NoError(t, <-done)
+			}
+
+			_, err = s.Get(k1)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+	}
+}
@@ -8,8 +8,9 @@ import (
 
 // MerkleTree implementation.
 type MerkleTree struct {
-	root  *MerkleTreeNode
-	depth int
+	root   *MerkleTreeNode
+	leaves []*MerkleTreeNode
+	depth  int
 }
 
 // NewMerkleTree returns new MerkleTree object.
@@ -26,8 +27,9 @@ func NewMerkleTree(hashes []common.Hash) (*MerkleTree, error) {
 	}
 
 	return &MerkleTree{
-		root:  buildMerkleTree(nodes),
-		depth: 1,
+		root:   buildMerkleTree(nodes),
+		leaves: nodes,
+		depth:  1,
 	}, nil
 }
 
@@ -70,6 +72,139 @@ func (t *MerkleTree) Prove(target common.Hash) ([]common.Hash, uint32, error) {
 	return hashes, path, nil
 }
 
+// Trim returns partial merkle tree (as defined by BIP37) proving inclusion of
+// the leaves marked in matches. It consists of the hashes and flag bits
+// (packed LSB first) of the depth-first tree traversal, the right node that is
+// the same as the left one is skipped.
+func (t *MerkleTree) Trim(matches []bool) ([]common.Hash, []byte) {
+	marked := make(map[*MerkleTreeNode]bool)
+	for i, leaf := range t.leaves {
+		if i >= len(matches) || !matches[i] {
+			continue
+		}
+		for n := leaf; n != nil && !marked[n]; n = n.parent {
+			marked[n] = true
+		}
+	}
+	var (
+		hashes []common.Hash
+		flags  []byte
+		bits   int
+		trim   func(n *MerkleTreeNode)
+	)
+	trim = func(n *MerkleTreeNode) {
+		if bits%8 == 0 {
+			flags = append(flags, 0)
+		}
+		if marked[n] {
+			flags[bits/8] |= 1 << (bits % 8)
+		}
+		bits++
+		if n.IsLeaf() || !marked[n] {
+			hashes = append(hashes, n.hash)
+			return
+		}
+		trim(n.leftChild)
+		if n.rightChild != n.leftChild {
+			trim(n.rightChild)
+		}
+	}
+	trim(t.root)
+	return hashes, flags
+}
+
+// NewPartialMerkleTree builds merkle tree for the given leaf hashes and trims
+// it to prove inclusion of the ones marked in matches, see Trim.
+func NewPartialMerkleTree(hashes []common.Hash, matches []bool) ([]common.Hash, []byte) {
+	t, err := NewMerkleTree(hashes)
+	if err != nil {
+		return nil, nil
+	}
+	return t.Trim(matches)
+}
+
+// ExtractPartialMerkleTree restores the merkle root and matched leaf hashes
+// from the partial merkle tree of count leaves produced by Trim.
+func ExtractPartialMerkleTree(count int, hashes []common.Hash, flags []byte) (common.Hash, []common.Hash, error) {
+	if count == 0 {
+		if len(hashes) != 0 || len(flags) != 0 {
+			return common.Hash{}, nil, errors.New("non-empty tree without leaves")
+		}
+		return common.Hash{}, nil, nil
+	}
+	if len(hashes) > count {
+		return common.Hash{}, nil, errors.New("too many hashes")
+	}
+	if len(flags) > (MerkleTreeSize(count)+7)/8 {
+		return common.Hash{}, nil, errors.New("too many flags")
+	}
+	// Only the shape of the tree is used.
+	t, _ := NewMerkleTree(make([]common.Hash, count))
+	var (
+		bits    int
+		used    int
+		matched []common.Hash
+		err     error
+		extract func(n *MerkleTreeNode) common.Hash
+	)
+	extract = func(n *MerkleTreeNode) common.Hash {
+		if err != nil {
+			return common.Hash{}
+		}
+		if bits >= len(flags)*8 {
+			err = errors.New("not enough flags")
+			return common.Hash{}
+		}
+		parentOfMatch := flags[bits/8]&(1<<(bits%8)) != 0
+		bits++
+		if n.IsLeaf() || !parentOfMatch {
+			if used >= len(hashes) {
+				err = errors.New("not enough hashes")
+				return common.Hash{}
+			}
+			h := hashes[used]
+			used++
+			if n.IsLeaf() && parentOfMatch {
+				matched = append(matched, h)
+			}
+			return h
+		}
+		left := extract(n.leftChild)
+		right := left
+		if n.rightChild != n.leftChild {
+			right = extract(n.rightChild)
+			// Identical siblings allow to forge different trees with the
+			// same root.
+			if right == left && err == nil {
+				err = errors.New("duplicate subtree")
+			}
+		}
+		return DoubleSha256(append(left.Bytes(), right.Bytes()...))
+	}
+	root := extract(t.root)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	if used != len(hashes) {
+		return common.Hash{}, nil, errors.New("not all hashes used")
+	}
+	if (bits+7)/8 != len(flags) {
+		return common.Hash{}, nil, errors.New("not all flags used")
+	}
+	return root, matched, nil
+}
+
+// MerkleTreeSize returns the number of distinct nodes in the merkle tree of
+// count leaves, it's the maximum number of partial merkle tree flags.
+func MerkleTreeSize(count int) int {
+	n := count
+	for w := count; w > 1; {
+		w = (w + 1) / 2
+		n += w
+	}
+	return n
+}
+
 func buildMerkleTree(leaves []*MerkleTreeNode) *MerkleTreeNode {
 	if len(leaves) == 0 {
 		panic("length of leaves cannot be zero")
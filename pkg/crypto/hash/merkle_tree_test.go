@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProve(t *testing.T) {
@@ -40,3 +41,58 @@ func TestVerifySingle(t *testing.T) {
 	r := VerifyMerkleProof(h, h, nil, uint32(0))
 	assert.True(t, r)
 }
+
+func TestPartialMerkleTree(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 10, 16} {
+		hashes := make([]common.Hash, n)
+		for i := range hashes {
+			hashes[i] = common.BytesToHash([]byte{byte(i + 1)})
+		}
+		root := CalcMerkleRoot(append([]common.Hash{}, hashes...))
+		for _, matchIdx := range [][]int{{}, {0}, {n - 1}, {0, n / 2, n - 1}} {
+			matches := make([]bool, n)
+			var expected []common.Hash
+			for _, i := range matchIdx {
+				matches[i] = true
+			}
+			for i := range matches {
+				if matches[i] {
+					expected = append(expected, hashes[i])
+				}
+			}
+			proof, flags := NewPartialMerkleTree(hashes, matches)
+			r, matched, err := ExtractPartialMerkleTree(n, proof, flags)
+			require.NoError(t, err)
+			require.Equal(t, root, r, "n=%d matches=%v", n, matchIdx)
+			require.Equal(t, expected, matched)
+		}
+	}
+}
+
+func TestPartialMerkleTreeInvalid(t *testing.T) {
+	hashes := make([]common.Hash, 5)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash([]byte{byte(i + 1)})
+	}
+	proof, flags := NewPartialMerkleTree(hashes, []bool{false, true, false, false, true})
+
+	_, _, err := ExtractPartialMerkleTree(5, proof[:len(proof)-1], flags)
+	require.Error(t, err)
+	_, _, err = ExtractPartialMerkleTree(5, append(proof, common.Hash{}), flags)
+	require.Error(t, err)
+	_, _, err = ExtractPartialMerkleTree(5, proof, append(flags, 0))
+	require.Error(t, err)
+	_, _, err = ExtractPartialMerkleTree(0, proof, flags)
+	require.Error(t, err)
+
+	root, matched, err := ExtractPartialMerkleTree(0, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, root)
+	require.Nil(t, matched)
+}
+
+func TestMerkleTreeSize(t *testing.T) {
+	for count, size := range map[int]int{0: 0, 1: 1, 2: 3, 3: 6, 4: 7, 5: 11} {
+		require.Equal(t, size, MerkleTreeSize(count), count)
+	}
+}
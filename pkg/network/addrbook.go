@@ -0,0 +1,428 @@
+package network
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
+)
+
+const (
+	defaultBanScore    = 100
+	defaultBanDuration = 24 * time.Hour
+	defaultScoreDecay  = time.Minute
+
+	// addrBookSaveInterval is the period of address book saving.
+	addrBookSaveInterval = 5 * time.Minute
+	// maxAddrBookSize is the maximum number of addresses and the maximum
+	// number of hosts in the address book.
+	maxAddrBookSize = 4096
+)
+
+// Misbehaviour scores added to peers.
+const (
+	// scoreProtocolViolation is added for messages that can't be handled.
+	scoreProtocolViolation = 10
+	// scoreUnrequested is added for data that wasn't requested.
+	scoreUnrequested = 5
+	// scoreRateLimited is added for messages exceeding peer byte budget and
+	// for repeatedly dropped messages.
+	scoreRateLimited = 2
+	// scoreInvalidMessage is added for messages that can't be decoded.
+	scoreInvalidMessage = 50
+	// scoreInvalidBlock is added for blocks not matching the header chain.
+	scoreInvalidBlock = 100
+)
+
+var (
+	errBanned         = errors.New("peer is banned")
+	errInvalidMessage = errors.New("invalid message")
+	errInvalidBlock   = errors.New("invalid block")
+	errUnrequested    = errors.New("unrequested data")
+)
+
+// AddressInfo is the address book entry for some peer.
+type AddressInfo struct {
+	Address      string
+	Capabilities capability.Capabilities
+	// LastSeen is the last time peer was connected.
+	LastSeen time.Time
+	// Latency is the last measured ping/pong round-trip time.
+	Latency time.Duration
+	// Score is the misbehaviour score of the peer host.
+	Score int
+	// BannedUntil is the end of the peer host ban, zero if it's not banned.
+	BannedUntil time.Time
+}
+
+// hostInfo is the misbehaviour information kept per host, peers can
+// reconnect from different ports.
+type hostInfo struct {
+	score       int
+	bannedUntil time.Time
+	// decayed is the last time score was decreased because of decay.
+	decayed time.Time
+}
+
+// AddressBook keeps known peer addresses along with their misbehaviour scores
+// and bans, it's saved to disk if the path is configured.
+type AddressBook struct {
+	cfg config.AddressBook
+
+	lock  sync.RWMutex
+	addrs map[string]*AddressInfo
+	hosts map[string]*hostInfo
+}
+
+// NewAddressBook returns new empty address book.
+func NewAddressBook(cfg config.AddressBook) *AddressBook {
+	if cfg.BanScore <= 0 {
+		cfg.BanScore = defaultBanScore
+	}
+	if cfg.BanDuration <= 0 {
+		cfg.BanDuration = defaultBanDuration
+	}
+	if cfg.ScoreDecay <= 0 {
+		cfg.ScoreDecay = defaultScoreDecay
+	}
+	return &AddressBook{
+		cfg:   cfg,
+		addrs: make(map[string]*AddressInfo),
+		hosts: make(map[string]*hostInfo),
+	}
+}
+
+// hostOf returns the host part of the address or the address itself if it
+// has no port.
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// RegisterGood records address that passed handshake successfully.
+func (ab *AddressBook) RegisterGood(addr string, caps capability.Capabilities) {
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+
+	info := ab.getOrAdd(addr)
+	if info == nil {
+		return
+	}
+	info.Capabilities = caps
+	info.LastSeen = time.Now()
+}
+
+// UpdateLatency records ping/pong round-trip time for the address.
+func (ab *AddressBook) UpdateLatency(addr string, latency time.Duration) {
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+
+	info := ab.getOrAdd(addr)
+	if info == nil {
+		return
+	}
+	info.Latency = latency
+	info.LastSeen = time.Now()
+}
+
+func (ab *AddressBook) getOrAdd(addr string) *AddressInfo {
+	info, ok := ab.addrs[addr]
+	if !ok {
+		if len(ab.addrs) >= maxAddrBookSize {
+			return nil
+		}
+		info = &AddressInfo{Address: addr}
+		ab.addrs[addr] = info
+	}
+	return info
+}
+
+// AddScore adds misbehaviour score to the host of the given address and bans
+// it if ban score is reached. It returns true if the host is banned.
+func (ab *AddressBook) AddScore(addr string, score int) bool {
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+
+	host := hostOf(addr)
+	h := ab.getHost(host)
+	if !h.bannedUntil.IsZero() {
+		return true
+	}
+	h.score += score
+	if h.score >= ab.cfg.BanScore {
+		h.bannedUntil = time.Now().Add(ab.cfg.BanDuration)
+		return true
+	}
+	return false
+}
+
+// getHost returns host misbehaviour information resetting expired bans and
+// decaying the score. The least important host is evicted if there are too
+// many of them.
+func (ab *AddressBook) getHost(host string) *hostInfo {
+	h, ok := ab.hosts[host]
+	if !ok {
+		if len(ab.hosts) >= maxAddrBookSize {
+			ab.evictHost()
+		}
+		h = &hostInfo{decayed: time.Now()}
+		ab.hosts[host] = h
+	}
+	ab.refreshHost(h)
+	return h
+}
+
+// refreshHost resets expired ban of the host and decays its score.
+func (ab *AddressBook) refreshHost(h *hostInfo) {
+	now := time.Now()
+	if !h.bannedUntil.IsZero() {
+		if now.Before(h.bannedUntil) {
+			return
+		}
+		h.bannedUntil = time.Time{}
+		h.score = 0
+	}
+	n := now.Sub(h.decayed) / ab.cfg.ScoreDecay
+	if h.score == 0 || int64(n) >= int64(h.score) {
+		h.score = 0
+		h.decayed = now
+		return
+	}
+	h.score -= int(n)
+	h.decayed = h.decayed.Add(n * ab.cfg.ScoreDecay)
+}
+
+// evictHost removes the least important host: hosts that are not banned go
+// first starting from the lowest score, then banned ones starting from the
+// earliest ban end.
+func (ab *AddressBook) evictHost() {
+	var (
+		victim string
+		vh     *hostInfo
+	)
+	for host, h := range ab.hosts {
+		ab.refreshHost(h)
+		if vh == nil || h.lessImportant(vh) {
+			victim, vh = host, h
+		}
+	}
+	delete(ab.hosts, victim)
+}
+
+func (h *hostInfo) lessImportant(other *hostInfo) bool {
+	if h.bannedUntil.IsZero() != other.bannedUntil.IsZero() {
+		return h.bannedUntil.IsZero()
+	}
+	if h.bannedUntil.IsZero() {
+		return h.score < other.score
+	}
+	return h.bannedUntil.Before(other.bannedUntil)
+}
+
+// Ban bans the host of the given address (which can also be just a host)
+// for the specified duration, the default ban duration is used if it's zero.
+func (ab *AddressBook) Ban(addr string, d time.Duration) {
+	if d <= 0 {
+		d = ab.cfg.BanDuration
+	}
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+
+	h := ab.getHost(hostOf(addr))
+	h.score = ab.cfg.BanScore
+	h.bannedUntil = time.Now().Add(d)
+}
+
+// Unban removes the ban and resets misbehaviour score for the host of the
+// given address. It returns false if the host wasn't banned.
+func (ab *AddressBook) Unban(addr string) bool {
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+
+	host := hostOf(addr)
+	h, ok := ab.hosts[host]
+	if !ok {
+		return false
+	}
+	delete(ab.hosts, host)
+	return !h.bannedUntil.IsZero() && time.Now().Before(h.bannedUntil)
+}
+
+// IsBanned checks whether the host of the given address is banned.
+func (ab *AddressBook) IsBanned(addr string) bool {
+	ab.lock.RLock()
+	h, ok := ab.hosts[hostOf(addr)]
+	banned := ok && !h.bannedUntil.IsZero() && time.Now().Before(h.bannedUntil)
+	ab.lock.RUnlock()
+	return banned
+}
+
+// Addresses returns known addresses of hosts that are not banned starting
+// from the most recently seen ones.
+func (ab *AddressBook) Addresses() []string {
+	list := ab.List()
+	res := make([]string, 0, len(list))
+	for _, info := range list {
+		// Hosts without known address can't be connected to.
+		if info.BannedUntil.IsZero() && hostOf(info.Address) != info.Address {
+			res = append(res, info.Address)
+		}
+	}
+	return res
+}
+
+// List returns all address book entries starting from the most recently seen
+// ones. Hosts that have misbehaviour score, but no known address, are also
+// included.
+func (ab *AddressBook) List() []AddressInfo {
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+
+	res := make([]AddressInfo, 0, len(ab.addrs))
+	known := make(map[string]bool)
+	for _, info := range ab.addrs {
+		entry := *info
+		host := hostOf(info.Address)
+		known[host] = true
+		if h, ok := ab.hosts[host]; ok {
+			h = ab.getHost(host)
+			entry.Score = h.score
+			entry.BannedUntil = h.bannedUntil
+		}
+		res = append(res, entry)
+	}
+	for host := range ab.hosts {
+		if known[host] {
+			continue
+		}
+		h := ab.getHost(host)
+		res = append(res, AddressInfo{Address: host, Score: h.score, BannedUntil: h.bannedUntil})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].LastSeen.Equal(res[j].LastSeen) {
+			return res[i].LastSeen.After(res[j].LastSeen)
+		}
+		return res[i].Address < res[j].Address
+	})
+	return res
+}
+
+// Load reads the address book from disk, it's not an error for the file to
+// not exist.
+func (ab *AddressBook) Load() error {
+	if ab.cfg.Path == "" {
+		return nil
+	}
+	f, err := os.Open(ab.cfg.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	r := io.NewBinReaderFromIO(bufio.NewReader(f))
+	n := r.ReadVarUint()
+	if n > maxAddrBookSize {
+		return errors.New("too many addresses in the address book")
+	}
+	addrs := make(map[string]*AddressInfo, n)
+	for i := uint64(0); i < n && r.Err == nil; i++ {
+		info := new(AddressInfo)
+		info.Address = r.ReadString()
+		info.Capabilities.DecodeBinary(r)
+		info.LastSeen = readTime(r)
+		info.Latency = time.Duration(r.ReadU64LE())
+		addrs[info.Address] = info
+	}
+	n = r.ReadVarUint()
+	if n > maxAddrBookSize {
+		return errors.New("too many hosts in the address book")
+	}
+	hosts := make(map[string]*hostInfo, n)
+	for i := uint64(0); i < n && r.Err == nil; i++ {
+		host := r.ReadString()
+		h := &hostInfo{decayed: time.Now()}
+		h.score = int(int32(r.ReadU32LE()))
+		h.bannedUntil = readTime(r)
+		hosts[host] = h
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+
+	ab.lock.Lock()
+	ab.addrs = addrs
+	ab.hosts = hosts
+	ab.lock.Unlock()
+	return nil
+}
+
+// Save writes the address book to disk.
+func (ab *AddressBook) Save() error {
+	if ab.cfg.Path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(ab.cfg.Path), os.ModePerm); err != nil {
+		return err
+	}
+	buf := io.NewBufBinWriter()
+	ab.lock.Lock()
+	buf.WriteVarUint(uint64(len(ab.addrs)))
+	for _, info := range ab.addrs {
+		buf.WriteString(info.Address)
+		info.Capabilities.EncodeBinary(buf.BinWriter)
+		writeTime(buf.BinWriter, info.LastSeen)
+		buf.WriteU64LE(uint64(info.Latency))
+	}
+	// Hosts having nothing to remember are dropped.
+	for host, h := range ab.hosts {
+		ab.refreshHost(h)
+		if h.score == 0 && h.bannedUntil.IsZero() {
+			delete(ab.hosts, host)
+		}
+	}
+	buf.WriteVarUint(uint64(len(ab.hosts)))
+	for host, h := range ab.hosts {
+		buf.WriteString(host)
+		buf.WriteU32LE(uint32(int32(h.score)))
+		writeTime(buf.BinWriter, h.bannedUntil)
+	}
+	ab.lock.Unlock()
+	if buf.Err != nil {
+		return buf.Err
+	}
+	tmp := ab.cfg.Path + ".new"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ab.cfg.Path)
+}
+
+func writeTime(w *io.BinWriter, t time.Time) {
+	var ms int64
+	if !t.IsZero() {
+		ms = t.UnixMilli()
+	}
+	w.WriteU64LE(uint64(ms))
+}
+
+func readTime(r *io.BinReader) time.Time {
+	ms := int64(r.ReadU64LE())
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressBookScore(t *testing.T) {
+	ab := NewAddressBook(config.AddressBook{BanScore: 30})
+
+	require.False(t, ab.AddScore("1.2.3.4:20333", scoreProtocolViolation))
+	require.False(t, ab.AddScore("1.2.3.4:56789", scoreProtocolViolation))
+	require.False(t, ab.IsBanned("1.2.3.4:20333"))
+	// Score is kept per host.
+	require.True(t, ab.AddScore("1.2.3.4:1", scoreProtocolViolation))
+	require.True(t, ab.IsBanned("1.2.3.4:20333"))
+	require.False(t, ab.IsBanned("1.2.3.5:20333"))
+
+	require.True(t, ab.Unban("1.2.3.4"))
+	require.False(t, ab.IsBanned("1.2.3.4:20333"))
+	require.False(t, ab.Unban("1.2.3.4"))
+	require.False(t, ab.AddScore("1.2.3.4:20333", scoreProtocolViolation))
+
+	ab.Ban("1.2.3.5:20333", time.Millisecond)
+	require.True(t, ab.IsBanned("1.2.3.5:1"))
+	time.Sleep(2 * time.Millisecond)
+	require.False(t, ab.IsBanned("1.2.3.5:1"))
+	// Score is reset after ban expiration.
+	require.False(t, ab.AddScore("1.2.3.5:1", scoreProtocolViolation))
+}
+
+func TestAddressBookSaveLoad(t *testing.T) {
+	cfg := config.AddressBook{Path: filepath.Join(t.TempDir(), "addrbook")}
+	ab := NewAddressBook(cfg)
+	caps := capability.Capabilities{{
+		Type: capability.TCPServer,
+		Data: &capability.Server{Port: 20333},
+	}}
+	ab.RegisterGood("1.2.3.4:20333", caps)
+	time.Sleep(2 * time.Millisecond) // Saved with millisecond precision.
+	ab.RegisterGood("1.2.3.5:20333", nil)
+	ab.UpdateLatency("1.2.3.5:20333", 42*time.Millisecond)
+	ab.Ban("1.2.3.4", 0)
+	ab.AddScore("1.2.3.6:1", scoreUnrequested)
+	require.NoError(t, ab.Save())
+
+	loaded := NewAddressBook(cfg)
+	require.NoError(t, loaded.Load())
+	list := loaded.List()
+	require.Equal(t, 3, len(list))
+	require.Equal(t, "1.2.3.5:20333", list[0].Address)
+	require.Equal(t, 42*time.Millisecond, list[0].Latency)
+	require.Equal(t, "1.2.3.4:20333", list[1].Address)
+	require.Equal(t, caps, list[1].Capabilities)
+	require.Equal(t, defaultBanScore, list[1].Score)
+	require.False(t, list[1].BannedUntil.IsZero())
+	require.Equal(t, "1.2.3.6", list[2].Address)
+	require.Equal(t, scoreUnrequested, list[2].Score)
+	require.True(t, loaded.IsBanned("1.2.3.4:1"))
+	require.Equal(t, []string{"1.2.3.5:20333"}, loaded.Addresses())
+}
+
+func TestAddressBookScoreDecay(t *testing.T) {
+	ab := NewAddressBook(config.AddressBook{BanScore: 30, ScoreDecay: 10 * time.Millisecond})
+
+	require.False(t, ab.AddScore("1.2.3.4:20333", 25))
+	time.Sleep(60 * time.Millisecond)
+	// At least 5 points are gone.
+	require.False(t, ab.AddScore("1.2.3.4:20333", 5))
+	list := ab.List()
+	require.Equal(t, 1, len(list))
+	require.True(t, list[0].Score <= 25)
+
+	time.Sleep(300 * time.Millisecond)
+	require.Equal(t, 0, ab.List()[0].Score)
+}
+
+func TestAddressBookHostsLimit(t *testing.T) {
+	cfg := config.AddressBook{Path: filepath.Join(t.TempDir(), "addrbook")}
+	ab := NewAddressBook(cfg)
+
+	ab.Ban("10.0.0.1", 0)
+	ab.AddScore("10.0.0.2:1", scoreProtocolViolation)
+	for i := 0; i < maxAddrBookSize; i++ {
+		ab.AddScore(fmt.Sprintf("11.0.%d.%d:1", i/256, i%256), scoreUnrequested)
+	}
+	require.Equal(t, maxAddrBookSize, len(ab.hosts))
+	// Hosts with the lowest scores are evicted, bans are kept.
+	require.True(t, ab.IsBanned("10.0.0.1:1"))
+	require.Contains(t, ab.hosts, "10.0.0.2")
+
+	require.NoError(t, ab.Save())
+	loaded := NewAddressBook(cfg)
+	require.NoError(t, loaded.Load())
+	require.Equal(t, maxAddrBookSize, len(loaded.hosts))
+	require.True(t, loaded.IsBanned("10.0.0.1:1"))
+}
+
+func TestMisbehaviourScore(t *testing.T) {
+	require.Equal(t, scoreInvalidBlock, misbehaviourScore(fmt.Errorf("%w: block 1", errInvalidBlock)))
+	require.Equal(t, scoreInvalidMessage, misbehaviourScore(fmt.Errorf("handling block message: %w", errInvalidMessage)))
+	require.Equal(t, scoreProtocolViolation, misbehaviourScore(errInvalidInvType))
+	// Errors that are not the peer fault.
+	require.Equal(t, 0, misbehaviourScore(nil))
+	require.Equal(t, 0, misbehaviourScore(errors.New("failed to store block")))
+	require.Equal(t, 0, misbehaviourScore(errGone))
+}
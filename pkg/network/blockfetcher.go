@@ -0,0 +1,293 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+)
+
+const (
+	// blockRangeSize is the number of blocks requested from a peer at once.
+	blockRangeSize = 100
+	// blockRangeTimeout is the time a peer has to deliver requested range
+	// before it's requested from some other peer.
+	blockRangeTimeout = 15 * time.Second
+	// maxPeerRanges is the maximum number of ranges requested from the
+	// fastest peer concurrently, slower peers get less.
+	maxPeerRanges = 4
+)
+
+// blockFetcher schedules block requests during the catch-up sync. Blocks
+// above the current height are split into ranges of blockRangeSize that are
+// requested from several peers concurrently. Peers are ranked by delivered
+// throughput and the faster ones get more outstanding ranges, ranges not
+// delivered in time are re-requested from other peers.
+type blockFetcher struct {
+	timeout time.Duration
+
+	lock sync.Mutex
+	// ranges are sorted by the start index and don't overlap.
+	ranges []*blockRange
+	peers  map[Peer]*fetchPeer
+	// next is the first index not covered by ranges.
+	next uint32
+}
+
+// blockRange is a range of blocks being fetched.
+type blockRange struct {
+	start    uint32
+	received []bool
+	left     int
+
+	// peer is the peer range is requested from, nil if it's not requested.
+	peer      Peer
+	requested time.Time
+	// failed is the last peer that didn't deliver the range in time.
+	failed Peer
+}
+
+// fetchPeer is the block delivery statistics of the peer.
+type fetchPeer struct {
+	ranges int
+	// throughput is the moving average of blocks per second delivered.
+	throughput float64
+	delivered  int
+	timeouts   int
+}
+
+func newBlockFetcher(timeout time.Duration) *blockFetcher {
+	return &blockFetcher{
+		timeout: timeout,
+		peers:   make(map[Peer]*fetchPeer),
+	}
+}
+
+func (r *blockRange) end() uint32 {
+	return r.start + uint32(len(r.received)) - 1
+}
+
+// request returns requests to be sent to the peer to fill its window.
+// height is the current chain height, queued is the last block height
+// queued and peerHeight is the height of the peer.
+func (bf *blockFetcher) request(p Peer, height, queued, peerHeight uint32) []*payload.GetBlockByIndex {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	now := time.Now()
+	bf.prune(height, now)
+
+	fp := bf.getPeer(p)
+	limit := bf.peerLimit(fp)
+	var res []*payload.GetBlockByIndex
+	for fp.ranges < limit {
+		r := bf.findRange(p, height, queued, peerHeight, now)
+		if r == nil {
+			break
+		}
+		r.peer = p
+		r.requested = now
+		fp.ranges++
+		// Only request blocks not received yet.
+		start, end := r.start, r.end()
+		for start < end && r.received[start-r.start] {
+			start++
+		}
+		for end > start && r.received[end-r.start] {
+			end--
+		}
+		res = append(res, payload.NewGetBlockByIndex(start, int16(end-start+1)))
+	}
+	updateBlockFetchRangesMetric(bf.outstanding())
+	return res
+}
+
+// findRange returns a range not requested from anyone that can be requested
+// from the peer, new range is created if there is no such range. The peer
+// that failed to deliver the range can only get it again if nobody else
+// requested it for another timeout period.
+func (bf *blockFetcher) findRange(p Peer, height, queued, peerHeight uint32, now time.Time) *blockRange {
+	for _, r := range bf.ranges {
+		if r.peer == nil && r.end() <= peerHeight &&
+			(r.failed != p || now.Sub(r.requested) > 2*bf.timeout) {
+			return r
+		}
+	}
+	if bf.next <= height {
+		bf.next = height + 1
+	}
+	if bf.next <= queued && len(bf.ranges) == 0 {
+		bf.next = queued + 1
+	}
+	// blockQueue can't hold blocks above this height.
+	last := height + blockCacheSize
+	if peerHeight < last {
+		last = peerHeight
+	}
+	if bf.next > last {
+		return bf.stealRange(p, peerHeight)
+	}
+	count := last - bf.next + 1
+	if count > blockRangeSize {
+		count = blockRangeSize
+	}
+	r := &blockRange{
+		start:    bf.next,
+		received: make([]bool, count),
+		left:     int(count),
+	}
+	bf.next += count
+	bf.ranges = append(bf.ranges, r)
+	return r
+}
+
+// stealRange returns the lowest range requested from a peer that is much
+// slower than the given one. It's used when no new ranges can be created,
+// the lowest range then blocks the sync and the slow peer shouldn't be
+// waited for.
+func (bf *blockFetcher) stealRange(p Peer, peerHeight uint32) *blockRange {
+	fp := bf.peers[p]
+	for _, r := range bf.ranges {
+		if r.peer == nil || r.peer == p || r.end() > peerHeight {
+			continue
+		}
+		if fp.throughput > 2*bf.peers[r.peer].throughput {
+			bf.release(r)
+			return r
+		}
+		// Only the lowest range matters.
+		break
+	}
+	return nil
+}
+
+// prune removes ranges below the current height and releases timed out
+// ranges.
+func (bf *blockFetcher) prune(height uint32, now time.Time) {
+	var i int
+	for i < len(bf.ranges) && bf.ranges[i].end() <= height {
+		bf.release(bf.ranges[i])
+		i++
+	}
+	bf.ranges = bf.ranges[i:]
+	for _, r := range bf.ranges {
+		if r.peer != nil && now.Sub(r.requested) > bf.timeout {
+			fp := bf.peers[r.peer]
+			fp.timeouts++
+			fp.throughput /= 2
+			r.failed = r.peer
+			bf.release(r)
+			blockFetchTimeouts.Inc()
+		}
+	}
+}
+
+// release marks the range as not requested.
+func (bf *blockFetcher) release(r *blockRange) {
+	if r.peer == nil {
+		return
+	}
+	if fp, ok := bf.peers[r.peer]; ok {
+		fp.ranges--
+	}
+	r.peer = nil
+}
+
+func (bf *blockFetcher) getPeer(p Peer) *fetchPeer {
+	fp, ok := bf.peers[p]
+	if !ok {
+		fp = new(fetchPeer)
+		bf.peers[p] = fp
+	}
+	return fp
+}
+
+// peerLimit returns the number of ranges that can be requested from the
+// peer concurrently. Peers with unknown throughput get a single range.
+func (bf *blockFetcher) peerLimit(fp *fetchPeer) int {
+	var best float64
+	for _, other := range bf.peers {
+		if other.throughput > best {
+			best = other.throughput
+		}
+	}
+	if fp.throughput == 0 || best == 0 {
+		return 1
+	}
+	return 1 + int(float64(maxPeerRanges-1)*fp.throughput/best+0.5)
+}
+
+// delivered marks the block as received from the peer. It returns true if
+// the range requested from this peer is completed, so that more blocks can be
+// requested from it.
+func (bf *blockFetcher) delivered(p Peer, index uint32) bool {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	for i, r := range bf.ranges {
+		if index < r.start || index > r.end() {
+			continue
+		}
+		if r.received[index-r.start] {
+			return false
+		}
+		r.received[index-r.start] = true
+		r.left--
+		if r.left != 0 {
+			return false
+		}
+		bf.ranges = append(bf.ranges[:i], bf.ranges[i+1:]...)
+		if r.peer != p {
+			bf.release(r)
+			return false
+		}
+		fp := bf.peers[p]
+		fp.delivered += len(r.received)
+		speed := float64(len(r.received)) / time.Since(r.requested).Seconds()
+		if fp.throughput == 0 {
+			fp.throughput = speed
+		} else {
+			fp.throughput = (fp.throughput + speed) / 2
+		}
+		bf.release(r)
+		updateBlockSyncSpeedMetric(bf.throughput())
+		updateBlockFetchRangesMetric(bf.outstanding())
+		return true
+	}
+	return false
+}
+
+// removePeer releases all ranges requested from the peer.
+func (bf *blockFetcher) removePeer(p Peer) {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	for _, r := range bf.ranges {
+		if r.peer == p {
+			bf.release(r)
+		}
+	}
+	delete(bf.peers, p)
+	updateBlockSyncSpeedMetric(bf.throughput())
+	updateBlockFetchRangesMetric(bf.outstanding())
+}
+
+// throughput returns the overall throughput of all peers.
+func (bf *blockFetcher) throughput() float64 {
+	var res float64
+	for _, fp := range bf.peers {
+		res += fp.throughput
+	}
+	return res
+}
+
+// outstanding returns the number of requested ranges.
+func (bf *blockFetcher) outstanding() int {
+	var res int
+	for _, r := range bf.ranges {
+		if r.peer != nil {
+			res++
+		}
+	}
+	return res
+}
@@ -0,0 +1,225 @@
+package network
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/zap/zaptest"
+)
+
+// fetchTestPeer is only used as a key in blockFetcher, so Peer methods are
+// not implemented.
+type fetchTestPeer struct {
+	Peer
+	id int
+}
+
+func TestBlockFetcherRanges(t *testing.T) {
+	bf := newBlockFetcher(time.Minute)
+	p1, p2, p3 := &fetchTestPeer{id: 1}, &fetchTestPeer{id: 2}, &fetchTestPeer{id: 3}
+
+	// Different peers get different ranges, peers with unknown throughput
+	// get a single one.
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(1, blockRangeSize)}, bf.request(p1, 0, 0, 1000))
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(101, blockRangeSize)}, bf.request(p2, 0, 0, 1000))
+	require.Nil(t, bf.request(p1, 0, 0, 1000))
+	// Ranges are limited by the peer height.
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(201, 50)}, bf.request(p3, 0, 0, 250))
+
+	// Blocks from not requested ranges don't complete anything.
+	require.False(t, bf.delivered(p1, 101))
+	for i := uint32(1); i < blockRangeSize; i++ {
+		require.False(t, bf.delivered(p1, i))
+	}
+	require.True(t, bf.delivered(p1, blockRangeSize))
+	require.Equal(t, blockRangeSize, bf.peers[p1].delivered)
+
+	// The fastest peer gets maximum number of ranges.
+	res := bf.request(p1, blockRangeSize, blockRangeSize, 1000)
+	require.Equal(t, maxPeerRanges, len(res))
+	for i, pl := range res {
+		require.Equal(t, uint32(251+i*blockRangeSize), pl.IndexStart)
+	}
+
+	// Timed out ranges are re-requested from other peers, already received
+	// blocks are not requested again.
+	bf.timeout = 0
+	res = bf.request(p3, blockRangeSize, blockRangeSize, 1000)
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(102, blockRangeSize-1)}, res)
+	require.Equal(t, maxPeerRanges, bf.peers[p1].timeouts)
+	require.Equal(t, 1, bf.peers[p2].timeouts)
+	require.Equal(t, 1, bf.peers[p3].timeouts)
+
+	bf.timeout = time.Minute
+	res = bf.request(p2, blockRangeSize, blockRangeSize, 1000)
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(201, 50)}, res)
+
+	// Ranges of the removed peer are requested from others, but not from
+	// the peer that failed them.
+	bf.removePeer(p3)
+	res = bf.request(p1, blockRangeSize, blockRangeSize, 1000)
+	require.Equal(t, maxPeerRanges, len(res))
+	require.Equal(t, uint32(102), res[0].IndexStart)
+	require.Equal(t, uint32(251+maxPeerRanges*blockRangeSize), res[1].IndexStart)
+}
+
+func TestBlockFetcherSteal(t *testing.T) {
+	bf := newBlockFetcher(time.Minute)
+	slow, fast := &fetchTestPeer{id: 1}, &fetchTestPeer{id: 2}
+
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(1, blockRangeSize)}, bf.request(slow, 0, 0, 2*blockRangeSize))
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(101, blockRangeSize)}, bf.request(fast, 0, 0, 2*blockRangeSize))
+	for i := uint32(blockRangeSize + 1); i <= 2*blockRangeSize; i++ {
+		bf.delivered(fast, i)
+	}
+	require.Nil(t, bf.request(slow, 0, 0, 2*blockRangeSize))
+	// The lowest range is taken from the slow peer.
+	require.Equal(t, []*payload.GetBlockByIndex{payload.NewGetBlockByIndex(1, blockRangeSize)}, bf.request(fast, 0, 0, 2*blockRangeSize))
+	require.Equal(t, 0, bf.peers[slow].ranges)
+	require.Equal(t, 1, bf.peers[fast].ranges)
+}
+
+// fetchTestChain is a chain accepting blocks in order only.
+type fetchTestChain struct {
+	lock   sync.Mutex
+	height uint32
+}
+
+func (c *fetchTestChain) AddBlock(b *block.Block) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if b.Index != c.height+1 {
+		return errors.New("unexpected block")
+	}
+	c.height++
+	return nil
+}
+
+func (c *fetchTestChain) AddHeaders(...*block.Header) error { return nil }
+
+func (c *fetchTestChain) BlockHeight() uint32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.height
+}
+
+// fetchHarness simulates the catch-up sync from several peers with different
+// speeds in memory, it follows the Server behaviour: blocks are requested
+// from every peer periodically and after the requested range is delivered.
+type fetchHarness struct {
+	chain  *fetchTestChain
+	bq     *blockQueue
+	bf     *blockFetcher
+	target uint32
+	peers  []*fetchHarnessPeer
+}
+
+type fetchHarnessPeer struct {
+	fetchTestPeer
+	h        *fetchHarness
+	perBlock time.Duration
+	silent   bool
+	reqs     chan *payload.GetBlockByIndex
+	served   atomic.Int32
+}
+
+func newFetchHarness(t *testing.T, target uint32, timeout time.Duration) *fetchHarness {
+	chain := new(fetchTestChain)
+	h := &fetchHarness{
+		chain:  chain,
+		bq:     newBlockQueue(0, chain, zaptest.NewLogger(t), nil),
+		bf:     newBlockFetcher(timeout),
+		target: target,
+	}
+	go h.bq.run()
+	t.Cleanup(h.bq.discard)
+	return h
+}
+
+func (h *fetchHarness) addPeer(perBlock time.Duration, silent bool) *fetchHarnessPeer {
+	p := &fetchHarnessPeer{
+		fetchTestPeer: fetchTestPeer{id: len(h.peers)},
+		h:             h,
+		perBlock:      perBlock,
+		silent:        silent,
+		reqs:          make(chan *payload.GetBlockByIndex, 4*maxPeerRanges),
+	}
+	h.peers = append(h.peers, p)
+	return p
+}
+
+func (h *fetchHarness) request(p *fetchHarnessPeer) {
+	for _, pl := range h.bf.request(p, h.chain.BlockHeight(), h.bq.lastQueued(), h.target) {
+		select {
+		case p.reqs <- pl:
+		default:
+			// Overloaded peer drops requests.
+		}
+	}
+}
+
+func (p *fetchHarnessPeer) run(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case pl := <-p.reqs:
+			if p.silent {
+				continue
+			}
+			// Sleeping per block is too imprecise.
+			time.Sleep(p.perBlock * time.Duration(pl.Count))
+			for i := pl.IndexStart; i < pl.IndexStart+uint32(pl.Count); i++ {
+				_ = p.h.bq.putBlock(&block.Block{Header: block.Header{Index: i}})
+				p.served.Inc()
+				if p.h.bf.delivered(p, i) {
+					p.h.request(p)
+				}
+			}
+		}
+	}
+}
+
+func (h *fetchHarness) run(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	for _, p := range h.peers {
+		go p.run(done)
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(30 * time.Second)
+	for h.chain.BlockHeight() < h.target {
+		select {
+		case <-ticker.C:
+			for _, p := range h.peers {
+				h.request(p)
+			}
+		case <-deadline:
+			t.Fatalf("sync stuck at %d", h.chain.BlockHeight())
+		}
+	}
+}
+
+func TestBlockFetcherSync(t *testing.T) {
+	h := newFetchHarness(t, 3*blockCacheSize, 200*time.Millisecond)
+	fast := h.addPeer(10*time.Microsecond, false)
+	h.addPeer(10*time.Microsecond, false)
+	slow := h.addPeer(500*time.Microsecond, false)
+	silent := h.addPeer(0, true)
+
+	h.run(t)
+	require.Equal(t, 3*blockCacheSize, int(h.chain.BlockHeight()))
+
+	h.bf.lock.Lock()
+	defer h.bf.lock.Unlock()
+	require.Equal(t, 0, h.bf.peers[silent].delivered)
+	require.Equal(t, int32(0), silent.served.Load())
+	require.True(t, h.bf.peers[fast].delivered > h.bf.peers[slow].delivered)
+}
@@ -6,8 +6,13 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
 )
 
-// MaxCapabilities is the maximum number of capabilities per payload.
-const MaxCapabilities = 32
+const (
+	// MaxCapabilities is the maximum number of capabilities per payload.
+	MaxCapabilities = 32
+	// MaxDataSize is the maximum size of capability data not known to the
+	// node.
+	MaxDataSize = 1024
+)
 
 // Capabilities is a list of Capability.
 type Capabilities []Capability
@@ -26,7 +31,7 @@ func (cs *Capabilities) EncodeBinary(br *io.BinWriter) {
 // checkUniqueCapabilities checks whether payload capabilities have unique type.
 func (cs Capabilities) checkUniqueCapabilities() error {
 	err := errors.New("capabilities with the same type are not allowed")
-	var isFullNode, isTCP, isWS bool
+	var isFullNode, isTCP, isWS, isCompact bool
 	for _, cap := range cs {
 		switch cap.Type {
 		case FullNode:
@@ -44,6 +49,11 @@ func (cs Capabilities) checkUniqueCapabilities() error {
 				return err
 			}
 			isWS = true
+		case CompactBlocks:
+			if isCompact {
+				return err
+			}
+			isCompact = true
 		}
 	}
 	return nil
@@ -63,9 +73,10 @@ func (c *Capability) DecodeBinary(br *io.BinReader) {
 		c.Data = &Node{}
 	case TCPServer, WSServer:
 		c.Data = &Server{}
+	case CompactBlocks:
+		c.Data = &Compact{}
 	default:
-		br.Err = errors.New("unknown node capability type")
-		return
+		c.Data = &Unknown{}
 	}
 	c.Data.DecodeBinary(br)
 }
@@ -110,3 +121,43 @@ func (s *Server) DecodeBinary(br *io.BinReader) {
 func (s *Server) EncodeBinary(bw *io.BinWriter) {
 	bw.WriteU16LE(s.Port)
 }
+
+// Compact represents compact block relay capability with the protocol
+// version. Its data is encoded as Unknown, so that the nodes not knowing
+// compact blocks can skip it.
+type Compact struct {
+	Version byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *Compact) DecodeBinary(br *io.BinReader) {
+	data := br.ReadVarBytes(MaxDataSize)
+	if br.Err != nil {
+		return
+	}
+	if len(data) == 0 {
+		br.Err = errors.New("empty compact blocks capability")
+		return
+	}
+	c.Version = data[0]
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *Compact) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes([]byte{c.Version})
+}
+
+// Unknown represents capability of the type not known to the node, its data
+// is a variable-length byte array. New capability types are to be encoded the
+// same way to be skipped by older nodes.
+type Unknown []byte
+
+// DecodeBinary implements Serializable interface.
+func (u *Unknown) DecodeBinary(br *io.BinReader) {
+	*u = br.ReadVarBytes(MaxDataSize)
+}
+
+// EncodeBinary implements Serializable interface.
+func (u *Unknown) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(*u)
+}
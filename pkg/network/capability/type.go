@@ -10,4 +10,6 @@ const (
 	WSServer Type = 0x02
 	// FullNode represents full node capability type.
 	FullNode Type = 0x10
+	// CompactBlocks represents compact block relay capability type.
+	CompactBlocks Type = 0x11
 )
@@ -0,0 +1,270 @@
+package network
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"sync"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// compactBlockVersion is the version of compact block protocol.
+	compactBlockVersion = 1
+	// maxCompactEntries is the maximum number of blocks tracked by
+	// compactRelay.
+	maxCompactEntries = 16
+)
+
+// compactRelay keeps the state of compact block relay: compact blocks
+// waiting for the missing transactions and transactions to be prefilled
+// when relaying blocks further.
+type compactRelay struct {
+	lock    sync.Mutex
+	pending map[common.Hash]*pendingBlock
+	// prefill are the transactions of the received block that were missing
+	// in the mempool, other nodes are likely to miss them too.
+	prefill map[common.Hash]map[common.Hash]struct{}
+}
+
+// pendingBlock is the block restored from compact block except for the
+// missing transactions requested from the peer.
+type pendingBlock struct {
+	peer    Peer
+	block   *block.Block
+	missing []uint16
+}
+
+func newCompactRelay() *compactRelay {
+	return &compactRelay{
+		pending: make(map[common.Hash]*pendingBlock),
+		prefill: make(map[common.Hash]map[common.Hash]struct{}),
+	}
+}
+
+// addPending saves the block waiting for transactions, blocks not above the
+// given height are dropped.
+func (c *compactRelay) addPending(height uint32, pb *pendingBlock) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for h, other := range c.pending {
+		if other.block.Index <= height || len(c.pending) >= maxCompactEntries {
+			delete(c.pending, h)
+		}
+	}
+	c.pending[pb.block.Hash()] = pb
+}
+
+// takePending removes and returns the block pending for transactions from
+// the peer.
+func (c *compactRelay) takePending(h common.Hash, p Peer) *pendingBlock {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	pb, ok := c.pending[h]
+	if !ok || pb.peer != p {
+		return nil
+	}
+	delete(c.pending, h)
+	return pb
+}
+
+// setPrefill saves the hashes of transactions to be prefilled when relaying
+// the block.
+func (c *compactRelay) setPrefill(h common.Hash, txs []common.Hash) {
+	if len(txs) == 0 {
+		return
+	}
+	set := make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		set[tx] = struct{}{}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for bh := range c.prefill {
+		if len(c.prefill) < maxCompactEntries {
+			break
+		}
+		delete(c.prefill, bh)
+	}
+	c.prefill[h] = set
+}
+
+// prefillFunc returns the function selecting transactions to be prefilled
+// in the compact block.
+func (c *compactRelay) prefillFunc(h common.Hash) func(*transaction.Transaction) bool {
+	c.lock.Lock()
+	set := c.prefill[h]
+	delete(c.prefill, h)
+	c.lock.Unlock()
+	return func(tx *transaction.Transaction) bool {
+		_, ok := set[tx.Hash()]
+		return ok
+	}
+}
+
+// reconstructBlock restores the block from the compact block and the pool
+// transactions. Transactions not found in the pool are left nil and their
+// indexes are returned. Short ID collisions in the pool are treated as
+// missing transactions, collisions with transactions outside the pool are
+// detected by the merkle root check only.
+func reconstructBlock(cb *payload.CompactBlock, pool []*transaction.Transaction) (*block.Block, []uint16) {
+	byID := make(map[uint64]*transaction.Transaction, len(pool))
+	for _, tx := range pool {
+		id := cb.ShortID(tx.Hash())
+		if _, ok := byID[id]; ok {
+			byID[id] = nil
+		} else {
+			byID[id] = tx
+		}
+	}
+	b := &block.Block{
+		Header:       *cb.Header,
+		Transactions: make([]*transaction.Transaction, cb.TxCount()),
+	}
+	for _, p := range cb.Prefilled {
+		b.Transactions[p.Index] = p.Tx
+	}
+	var (
+		missing []uint16
+		next    int
+	)
+	for i := range b.Transactions {
+		if b.Transactions[i] != nil {
+			continue
+		}
+		if tx := byID[cb.ShortIDs[next]]; tx != nil {
+			b.Transactions[i] = tx
+		} else {
+			missing = append(missing, uint16(i))
+		}
+		next++
+	}
+	return b, missing
+}
+
+// supportsCompact checks whether compact blocks can be relayed to the peer.
+func (s *Server) supportsCompact(p Peer) bool {
+	if !s.CompactBlocks {
+		return false
+	}
+	ver := p.Version()
+	if ver == nil {
+		return false
+	}
+	for _, c := range ver.Capabilities {
+		if c.Type == capability.CompactBlocks {
+			return true
+		}
+	}
+	return false
+}
+
+// relayBlock announces the new block to peers that don't have it yet, compact
+// block is sent to the peers supporting it and inventory to others.
+func (s *Server) relayBlock(b *block.Block) {
+	// Filter out nodes that are more current (avoid spamming the network
+	// during initial sync).
+	isBehind := func(p Peer) bool {
+		return p.Handshaked() && p.LastBlockIndex() < b.Index
+	}
+	if s.CompactBlocks {
+		cb := payload.NewCompactBlock(b, mrand.Uint64(), s.compact.prefillFunc(b.Hash()))
+		s.iteratePeersWithSendMsg(NewMessage(CMDCompactBlock, cb), Peer.EnqueuePacket, func(p Peer) bool {
+			return isBehind(p) && s.supportsCompact(p)
+		})
+	}
+	msg := NewMessage(CMDInv, payload.NewInventory(payload.BlockType, []common.Hash{b.Hash()}))
+	s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+		return isBehind(p) && !s.supportsCompact(p)
+	})
+}
+
+// handleCompactBlockCmd restores the block from the mempool, missing
+// transactions are requested from the peer.
+func (s *Server) handleCompactBlockCmd(p Peer, cb *payload.CompactBlock) error {
+	if !s.CompactBlocks {
+		return fmt.Errorf("%w: compact block", errUnrequested)
+	}
+	if s.stateSync.IsActive() || s.light != nil {
+		return nil
+	}
+	h := cb.Hash()
+	height := s.chain.BlockHeight()
+	if cb.Index <= height || s.chain.HasBlock(h) {
+		return nil
+	}
+	if cb.Index != height+1 {
+		// Mempool can't have transactions of the future blocks, these are
+		// fetched by the regular sync.
+		return nil
+	}
+	b, missing := reconstructBlock(cb, s.mempool.GetVerifiedTransactions())
+	if len(missing) == 0 {
+		return s.processCompactBlock(p, b)
+	}
+	s.compact.addPending(height, &pendingBlock{peer: p, block: b, missing: missing})
+	compactMissingTxs.Add(float64(len(missing)))
+	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockTxn, &payload.GetBlockTxn{
+		BlockHash: h,
+		Indexes:   missing,
+	}))
+}
+
+// handleGetBlockTxnCmd sends the requested transactions of the block.
+func (s *Server) handleGetBlockTxnCmd(p Peer, req *payload.GetBlockTxn) error {
+	b, _, err := s.chain.GetBlock(req.BlockHash, true)
+	if err != nil {
+		return p.EnqueueP2PMessage(NewMessage(CMDNotFound,
+			payload.NewInventory(payload.BlockType, []common.Hash{req.BlockHash})))
+	}
+	res := &payload.BlockTxn{
+		BlockHash:    req.BlockHash,
+		Transactions: make([]*transaction.Transaction, 0, len(req.Indexes)),
+	}
+	for _, i := range req.Indexes {
+		if int(i) >= len(b.Transactions) {
+			return fmt.Errorf("%w: transaction index %d", errInvalidMessage, i)
+		}
+		res.Transactions = append(res.Transactions, b.Transactions[i])
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDBlockTxn, res))
+}
+
+// handleBlockTxnCmd completes the pending compact block with the received
+// transactions.
+func (s *Server) handleBlockTxnCmd(p Peer, resp *payload.BlockTxn) error {
+	pb := s.compact.takePending(resp.BlockHash, p)
+	if pb == nil {
+		// The block may already be received from someone else.
+		return nil
+	}
+	if len(resp.Transactions) != len(pb.missing) {
+		return fmt.Errorf("%w: %d transactions instead of %d", errInvalidMessage,
+			len(resp.Transactions), len(pb.missing))
+	}
+	for i, idx := range pb.missing {
+		pb.block.Transactions[idx] = resp.Transactions[i]
+	}
+	return s.processCompactBlock(p, pb.block)
+}
+
+// processCompactBlock passes the restored block to the queue, the full block
+// is requested if its merkle root doesn't match which is possible on short ID
+// collision.
+func (s *Server) processCompactBlock(p Peer, b *block.Block) error {
+	if b.MerkleRoot != b.ComputeMerkleRoot() {
+		compactFallbacks.Inc()
+		return s.requestFullBlock(p, b.Hash())
+	}
+	compactBlocksRestored.Inc()
+	return s.handleBlockCmd(p, b)
+}
+
+// requestFullBlock requests the block by hash.
+func (s *Server) requestFullBlock(p Peer, h common.Hash) error {
+	return p.EnqueueP2PMessage(NewMessage(CMDGetData, payload.NewInventory(payload.BlockType, []common.Hash{h})))
+}
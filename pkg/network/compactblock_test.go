@@ -0,0 +1,224 @@
+package network
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompactTestTxs creates transactions with random call data and
+// witnesses, so that they're not compressible like the real ones.
+func newCompactTestTxs(n int, from common.Address) []*transaction.Transaction {
+	to := common.HexToAddress("0xff")
+	r := rand.New(rand.NewSource(int64(n)))
+	random := func(size int) []byte {
+		b := make([]byte, size)
+		r.Read(b)
+		return b
+	}
+	txs := make([]*transaction.Transaction, n)
+	for i := range txs {
+		txs[i] = transaction.NewTx(&transaction.NeoTx{
+			Nonce:    uint64(i),
+			GasPrice: big.NewInt(1),
+			Gas:      100000,
+			From:     from,
+			To:       &to,
+			Value:    big.NewInt(int64(i)),
+			Data:     random(68),
+			Witness: transaction.Witness{
+				InvocationScript:   random(66),
+				VerificationScript: random(35),
+			},
+		})
+	}
+	return txs
+}
+
+func messageSize(t *testing.T, cmd CommandType, p payload.Payload) int {
+	data, err := NewMessage(cmd, p).Bytes()
+	require.NoError(t, err)
+	return len(data)
+}
+
+func TestCompactBlockRelay(t *testing.T) {
+	const (
+		inPool  = 500
+		inBlock = 300
+		unknown = 15
+	)
+	pool := newCompactTestTxs(inPool, common.HexToAddress("0x01"))
+	b := &block.Block{Header: block.Header{Index: 1}}
+	b.Transactions = append(b.Transactions, pool[:inBlock]...)
+	b.Transactions = append(b.Transactions, newCompactTestTxs(unknown, common.HexToAddress("0x02"))...)
+	b.RebuildMerkleRoot()
+
+	cb := payload.NewCompactBlock(b, 1, nil)
+	res, missing := reconstructBlock(cb, pool)
+	require.Equal(t, unknown, len(missing))
+	for i, idx := range missing {
+		require.Equal(t, uint16(inBlock+i), idx)
+		require.Nil(t, res.Transactions[idx])
+	}
+
+	// Round-trip for the missing transactions.
+	req := &payload.GetBlockTxn{BlockHash: b.Hash(), Indexes: missing}
+	resp := &payload.BlockTxn{BlockHash: b.Hash()}
+	for _, idx := range missing {
+		resp.Transactions = append(resp.Transactions, b.Transactions[idx])
+	}
+	for i, idx := range missing {
+		res.Transactions[idx] = resp.Transactions[i]
+	}
+	require.Equal(t, b.MerkleRoot, res.ComputeMerkleRoot())
+	require.Equal(t, b.Hash(), res.Hash())
+
+	full := messageSize(t, CMDBlock, b)
+	compact := messageSize(t, CMDCompactBlock, cb) +
+		messageSize(t, CMDGetBlockTxn, req) +
+		messageSize(t, CMDBlockTxn, resp)
+	t.Logf("full block: %d bytes, compact block with round-trip: %d bytes (%.1f%% saved)",
+		full, compact, 100-100*float64(compact)/float64(full))
+	require.Less(t, compact*5, full)
+
+	// Prefilled transactions are not requested.
+	unknownTxs := make(map[common.Hash]bool)
+	for _, tx := range b.Transactions[inBlock:] {
+		unknownTxs[tx.Hash()] = true
+	}
+	cb = payload.NewCompactBlock(b, 2, func(tx *transaction.Transaction) bool {
+		return unknownTxs[tx.Hash()]
+	})
+	res, missing = reconstructBlock(cb, pool)
+	require.Empty(t, missing)
+	require.Equal(t, b.MerkleRoot, res.ComputeMerkleRoot())
+	prefilled := messageSize(t, CMDCompactBlock, cb)
+	t.Logf("compact block with prefilled transactions: %d bytes (%.1f%% saved)",
+		prefilled, 100-100*float64(prefilled)/float64(full))
+	require.Less(t, prefilled, compact)
+}
+
+func TestCompactBlockCollision(t *testing.T) {
+	pool := newCompactTestTxs(3, common.HexToAddress("0x01"))
+	b := &block.Block{Header: block.Header{Index: 1}, Transactions: pool[:1]}
+	b.RebuildMerkleRoot()
+	cb := payload.NewCompactBlock(b, 1, nil)
+
+	// Ambiguous short IDs are requested from the peer.
+	cb.ShortIDs[0] = cb.ShortID(pool[1].Hash())
+	_, missing := reconstructBlock(cb, append(pool, pool[1]))
+	require.Equal(t, []uint16{0}, missing)
+
+	// Collision with a transaction outside the block is detected by the
+	// merkle root.
+	res, missing := reconstructBlock(cb, pool)
+	require.Empty(t, missing)
+	require.NotEqual(t, b.MerkleRoot, res.ComputeMerkleRoot())
+}
+
+func TestCompactRelayState(t *testing.T) {
+	c := newCompactRelay()
+	p1, p2 := &fetchTestPeer{id: 1}, &fetchTestPeer{id: 2}
+	blocks := make([]*block.Block, maxCompactEntries+1)
+	for i := range blocks {
+		blocks[i] = &block.Block{Header: block.Header{Index: uint32(i + 1)}}
+		c.addPending(0, &pendingBlock{peer: p1, block: blocks[i]})
+	}
+	require.Equal(t, maxCompactEntries, len(c.pending))
+
+	last := blocks[len(blocks)-1].Hash()
+	require.Nil(t, c.takePending(last, p2))
+	require.NotNil(t, c.takePending(last, p1))
+	require.Nil(t, c.takePending(last, p1))
+
+	// Blocks below the height are dropped.
+	c.addPending(uint32(len(blocks)), &pendingBlock{peer: p1, block: &block.Block{Header: block.Header{Index: 100}}})
+	require.Equal(t, 1, len(c.pending))
+
+	tx := newCompactTestTxs(1, common.Address{})[0]
+	c.setPrefill(last, []common.Hash{tx.Hash()})
+	require.True(t, c.prefillFunc(last)(tx))
+	require.False(t, c.prefillFunc(last)(tx))
+}
+
+func TestCompactBlockNegotiation(t *testing.T) {
+	s := newTestServer(t, ServerConfig{CompactBlocks: true})
+
+	// Compact blocks support is announced with the capability.
+	msg, err := s.getVersionMsg()
+	require.NoError(t, err)
+	data, err := msg.Bytes()
+	require.NoError(t, err)
+	decoded := &Message{}
+	require.NoError(t, decoded.Decode(io.NewBinReaderFromBuf(data)))
+	ver := decoded.Payload.(*payload.Version)
+	require.Equal(t, uint32(0), ver.Version)
+	require.Contains(t, ver.Capabilities, capability.Capability{
+		Type: capability.CompactBlocks,
+		Data: &capability.Compact{Version: compactBlockVersion},
+	})
+
+	// Capability is encoded as unknown one, so that it can be skipped by the
+	// nodes not knowing compact blocks.
+	encode := func(c capability.Capability) []byte {
+		w := io.NewBufBinWriter()
+		c.EncodeBinary(w.BinWriter)
+		require.NoError(t, w.Err)
+		return w.Bytes()
+	}
+	compactCap := encode(capability.Capability{
+		Type: capability.CompactBlocks,
+		Data: &capability.Compact{Version: compactBlockVersion},
+	})
+	unknownCap := encode(capability.Capability{
+		Type: capability.CompactBlocks,
+		Data: &capability.Unknown{compactBlockVersion},
+	})
+	require.Equal(t, unknownCap, compactCap)
+
+	// Unknown capabilities don't break the handshake.
+	unknown := payload.NewVersion(s.ChainID, 3, "/test/", capability.Capabilities{
+		{Type: 0x7f, Data: &capability.Unknown{1, 2, 3}},
+		{Type: capability.TCPServer, Data: &capability.Server{Port: 20333}},
+	})
+	data, err = NewMessage(CMDVersion, unknown).Bytes()
+	require.NoError(t, err)
+	decoded = &Message{}
+	require.NoError(t, decoded.Decode(io.NewBinReaderFromBuf(data)))
+	require.Equal(t, unknown.Capabilities, decoded.Payload.(*payload.Version).Capabilities)
+
+	// Peer without compact blocks support handshakes and gets inventory.
+	old := newTestPeer("1.2.3.4:20333", nil)
+	require.NoError(t, s.handleVersionCmd(old, &payload.Version{Nonce: 1, Capabilities: unknown.Capabilities}))
+	require.Equal(t, []CommandType{CMDVerack}, old.commands())
+	require.False(t, s.supportsCompact(old))
+
+	compact := newTestPeer("1.2.3.5:20333", nil)
+	require.NoError(t, s.handleVersionCmd(compact, &payload.Version{Nonce: 2, Capabilities: ver.Capabilities}))
+	require.Equal(t, []CommandType{CMDVerack}, compact.commands())
+	require.True(t, s.supportsCompact(compact))
+
+	addPeers(s, old, compact)
+	b := &block.Block{Header: block.Header{Index: 1}}
+	b.RebuildMerkleRoot()
+	s.relayBlock(b)
+	require.Equal(t, []CommandType{CMDInv}, old.commands())
+	require.Equal(t, []CommandType{CMDCompactBlock}, compact.commands())
+
+	// Compact blocks are not relayed if they're disabled locally.
+	s.CompactBlocks = false
+	require.False(t, s.supportsCompact(compact))
+	msg, err = s.getVersionMsg()
+	require.NoError(t, err)
+	for _, c := range msg.Payload.(*payload.Version).Capabilities {
+		require.NotEqual(t, capability.CompactBlocks, c.Type)
+	}
+}
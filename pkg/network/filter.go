@@ -0,0 +1,118 @@
+package network
+
+import (
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// getFilter returns the bloom filter loaded by the peer, nil if there is
+// none. Filters are never modified after being set, so the result can be
+// used without locking.
+func (s *Server) getFilter(p Peer) *payload.FilterLoad {
+	s.filterLock.RLock()
+	defer s.filterLock.RUnlock()
+	return s.filters[p]
+}
+
+// setFilter sets the bloom filter of the peer, nil filter removes it.
+func (s *Server) setFilter(p Peer, f *payload.FilterLoad) {
+	s.filterLock.Lock()
+	defer s.filterLock.Unlock()
+	if f == nil {
+		delete(s.filters, p)
+	} else {
+		s.filters[p] = f
+	}
+}
+
+// addToFilter adds the data to the filter loaded by the peer.
+func (s *Server) addToFilter(p Peer, data []byte) error {
+	s.filterLock.Lock()
+	defer s.filterLock.Unlock()
+	f, ok := s.filters[p]
+	if !ok {
+		return errNoFilter
+	}
+	f = f.Copy()
+	f.Add(data)
+	s.filters[p] = f
+	return nil
+}
+
+// isFiltered checks whether the peer has loaded a bloom filter.
+func (s *Server) isFiltered(p Peer) bool {
+	return s.getFilter(p) != nil
+}
+
+// txMatches checks whether the transaction matches the filter. Transaction
+// hash, sender, recipient and addresses of the logs from the receipt (if it's
+// known) are checked.
+func txMatches(f *payload.FilterLoad, tx *transaction.Transaction, receipt *types.Receipt) bool {
+	h := tx.Hash()
+	if f.Matches(h[:]) {
+		return true
+	}
+	from := tx.From()
+	if f.Matches(from[:]) {
+		return true
+	}
+	if to := tx.To(); to != nil && f.Matches(to[:]) {
+		return true
+	}
+	if receipt == nil {
+		return false
+	}
+	if receipt.ContractAddress != (common.Address{}) && f.Matches(receipt.ContractAddress[:]) {
+		return true
+	}
+	for _, l := range receipt.Logs {
+		if f.Matches(l.Address[:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTxHashes returns the hashes of transactions matching the filter.
+func filterTxHashes(f *payload.FilterLoad, txs []*transaction.Transaction) []common.Hash {
+	var res []common.Hash
+	for _, tx := range txs {
+		if txMatches(f, tx, nil) {
+			res = append(res, tx.Hash())
+		}
+	}
+	return res
+}
+
+// enqueueMerkleBlock sends the block filtered for the peer: MerkleBlock
+// proving inclusion of the matching transactions followed by these
+// transactions.
+func (s *Server) enqueueMerkleBlock(p Peer, f *payload.FilterLoad, b *block.Block) error {
+	hashes := make([]common.Hash, len(b.Transactions))
+	matches := make([]bool, len(b.Transactions))
+	var txs []*transaction.Transaction
+	for i, tx := range b.Transactions {
+		hashes[i] = tx.Hash()
+		_, receipt, err := s.chain.GetTransaction(hashes[i])
+		if err != nil {
+			receipt = nil
+		}
+		if txMatches(f, tx, receipt) {
+			matches[i] = true
+			txs = append(txs, tx)
+		}
+	}
+	msg := NewMessage(CMDMerkleBlock, payload.NewMerkleBlock(&b.Header, hashes, matches))
+	if err := p.EnqueueP2PMessage(msg); err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		if err := p.EnqueueP2PMessage(NewMessage(CMDTX, tx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newFilterTestTx(nonce uint64, from common.Address, to *common.Address) *transaction.Transaction {
+	return transaction.NewTx(&transaction.NeoTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		From:     from,
+		To:       to,
+		Value:    big.NewInt(0),
+		Witness: transaction.Witness{
+			InvocationScript:   []byte{0},
+			VerificationScript: []byte{0},
+		},
+	})
+}
+
+func TestTxMatches(t *testing.T) {
+	var (
+		alice    = common.HexToAddress("0x01")
+		bob      = common.HexToAddress("0x02")
+		carol    = common.HexToAddress("0x03")
+		contract = common.HexToAddress("0x04")
+	)
+	f := payload.NewFilterLoad(10, 0.0001, 0)
+	f.Add(alice[:])
+
+	fromAlice := newFilterTestTx(0, alice, &bob)
+	toAlice := newFilterTestTx(1, bob, &alice)
+	other := newFilterTestTx(2, bob, &carol)
+	require.True(t, txMatches(f, fromAlice, nil))
+	require.True(t, txMatches(f, toAlice, nil))
+	require.False(t, txMatches(f, other, nil))
+
+	// Log addresses are only known from the receipt.
+	receipt := &types.Receipt{Logs: []*types.Log{{Address: contract}}}
+	f.Add(contract[:])
+	require.False(t, txMatches(f, other, nil))
+	require.True(t, txMatches(f, other, receipt))
+
+	// Transaction hash matches too.
+	f = payload.NewFilterLoad(10, 0.0001, 0)
+	h := other.Hash()
+	f.Add(h[:])
+	require.Equal(t, []common.Hash{h}, filterTxHashes(f, []*transaction.Transaction{fromAlice, toAlice, other}))
+}
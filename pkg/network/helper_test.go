@@ -0,0 +1,171 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/statesync"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testLedger implements the parts of Ledger used by the tests, other methods
+// panic.
+type testLedger struct {
+	Ledger
+	pool   *mempool.Pool
+	sync   *statesync.Module
+	height uint32
+}
+
+func (l *testLedger) BlockHeight() uint32                     { return l.height }
+func (l *testLedger) HeaderHeight() uint32                    { return l.height }
+func (l *testLedger) GetConfig() config.ProtocolConfiguration { return config.ProtocolConfiguration{} }
+func (l *testLedger) GetMemPool() *mempool.Pool               { return l.pool }
+func (l *testLedger) GetStateSyncModule() *statesync.Module   { return l.sync }
+func (l *testLedger) HasBlock(common.Hash) bool               { return false }
+func (l *testLedger) GetTransaction(common.Hash) (*transaction.Transaction, *types.Receipt, error) {
+	return nil, nil, storage.ErrKeyNotFound
+}
+
+// blocksLedger is a testLedger serving the given blocks.
+type blocksLedger struct {
+	*testLedger
+	blocks []*block.Block
+}
+
+func (l *blocksLedger) GetHeaderHash(i int) common.Hash {
+	if i < 0 || i >= len(l.blocks) {
+		return common.Hash{}
+	}
+	return l.blocks[i].Hash()
+}
+
+func (l *blocksLedger) GetBlock(hash common.Hash, _ bool) (*block.Block, *types.Receipt, error) {
+	for _, b := range l.blocks {
+		if b.Hash() == hash {
+			return b, nil, nil
+		}
+	}
+	return nil, nil, storage.ErrKeyNotFound
+}
+
+func newTestServer(t *testing.T, cfg ServerConfig) *Server {
+	l := &testLedger{pool: mempool.New(100, 0, false)}
+	l.sync = statesync.NewModule(l, nil, zap.NewNop(), nil, nil)
+	s, err := newServerFromConstructors(cfg, l, zap.NewNop(), newFakeTransp, newDefaultDiscovery)
+	require.NoError(t, err)
+	return s
+}
+
+// testPeer is a handshaked peer recording messages sent to it.
+type testPeer struct {
+	Peer
+	addr    net.Addr
+	version *payload.Version
+	height  uint32
+
+	lock         sync.Mutex
+	messages     []*Message
+	disconnected error
+}
+
+func newTestPeer(addr string, version *payload.Version) *testPeer {
+	a, _ := net.ResolveTCPAddr("tcp", addr)
+	if version == nil {
+		version = &payload.Version{}
+	}
+	return &testPeer{addr: a, version: version}
+}
+
+func (p *testPeer) RemoteAddr() net.Addr      { return p.addr }
+func (p *testPeer) PeerAddr() net.Addr        { return p.addr }
+func (p *testPeer) Version() *payload.Version { return p.version }
+func (p *testPeer) LastBlockIndex() uint32    { return p.height }
+func (p *testPeer) Handshaked() bool          { return true }
+func (p *testPeer) IsFullNode() bool          { return true }
+func (p *testPeer) Stats() PeerStats          { return PeerStats{} }
+
+func (p *testPeer) HandleVersion(v *payload.Version) error {
+	p.version = v
+	return nil
+}
+
+func (p *testPeer) Disconnect(err error) {
+	p.lock.Lock()
+	p.disconnected = err
+	p.lock.Unlock()
+}
+
+func (p *testPeer) record(pkt []byte) error {
+	msg := &Message{}
+	if err := msg.Decode(io.NewBinReaderFromBuf(pkt)); err != nil {
+		return err
+	}
+	p.lock.Lock()
+	p.messages = append(p.messages, msg)
+	p.lock.Unlock()
+	return nil
+}
+
+func (p *testPeer) send(msg *Message) error {
+	pkt, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	return p.record(pkt)
+}
+
+func (p *testPeer) EnqueueMessage(msg *Message) error    { return p.send(msg) }
+func (p *testPeer) EnqueueP2PMessage(msg *Message) error { return p.send(msg) }
+func (p *testPeer) SendVersionAck(msg *Message) error    { return p.send(msg) }
+func (p *testPeer) EnqueuePacket(_ bool, pkt []byte) error {
+	return p.record(pkt)
+}
+func (p *testPeer) EnqueueP2PPacket(pkt []byte) error { return p.record(pkt) }
+func (p *testPeer) EnqueueHPPacket(_ bool, pkt []byte) error {
+	return p.record(pkt)
+}
+
+// getMessages returns the messages sent to the peer and forgets them.
+func (p *testPeer) getMessages() []*Message {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	msgs := p.messages
+	p.messages = nil
+	return msgs
+}
+
+// commands returns the commands of the messages sent to the peer.
+func (p *testPeer) commands() []CommandType {
+	var cmds []CommandType
+	for _, msg := range p.getMessages() {
+		cmds = append(cmds, msg.Command)
+	}
+	return cmds
+}
+
+// addPeers registers handshaked peers in the server.
+func addPeers(s *Server, peers ...*testPeer) {
+	s.lock.Lock()
+	for _, p := range peers {
+		s.peers[p] = true
+	}
+	s.lock.Unlock()
+}
+
+// waitFor waits for the condition to become true.
+func waitFor(t *testing.T, cond func() bool) {
+	require.Eventually(t, cond, time.Second, time.Millisecond)
+}
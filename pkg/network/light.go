@@ -0,0 +1,448 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/blockchainer"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+	"go.uber.org/zap"
+)
+
+const (
+	// lightRootsWindow is the number of state roots requested from a peer
+	// at once.
+	lightRootsWindow = 32
+	// lightKnownRoots is the number of the latest validated roots the state
+	// can be requested for.
+	lightKnownRoots = 1024
+	// lightProofAttempts is the number of peers asked for the proof before
+	// giving up.
+	lightProofAttempts = 3
+
+	defaultProofCacheSize = 1024
+	defaultProofTimeout   = 5 * time.Second
+)
+
+var (
+	// ErrUnknownStateRoot is returned by the light node for the state roots
+	// it hasn't validated.
+	ErrUnknownStateRoot = errors.New("state root is not validated")
+	// ErrNoStateProof is returned by the light node if none of the peers
+	// provided the state proof.
+	ErrNoStateProof = errors.New("no state proof received")
+)
+
+type (
+	// lightStateModule is the state root module functionality the light node
+	// needs to track validated state roots.
+	lightStateModule interface {
+		blockchainer.StateRoot
+		AddLightStateRoot(*state.MPTRoot) error
+		UpdateStateValidators(uint32, keys.PublicKeys)
+	}
+
+	// lightClient is the headers-only node part syncing validated state roots
+	// and fetching state proofs from full nodes. State roots are validated
+	// sequentially: after the root N is accepted, the designation of state
+	// validators taking effect at N+2 is checked with the proof against it,
+	// so that every next root is verified with the right keys.
+	lightClient struct {
+		s       *Server
+		module  lightStateModule
+		timeout time.Duration
+		log     *zap.Logger
+		// proofs are the verified proof results by root and key.
+		proofs *lru.Cache
+		// known are the indexes of the latest validated roots.
+		known *lru.Cache
+		wake  chan struct{}
+
+		lock    sync.Mutex
+		anchor  *state.MPTRoot
+		pending map[uint32]*state.MPTRoot
+		waiters map[string]map[chan *stateProofResult]struct{}
+		// check is the validated root designations are to be checked
+		// against, checkFrom is the first designation index to check.
+		check     *state.MPTRoot
+		checkFrom uint32
+	}
+
+	// stateProofResult is the value proven by the state proof, exists is
+	// false if the proof is the proof of absence.
+	stateProofResult struct {
+		value  []byte
+		exists bool
+	}
+)
+
+func newLightClient(s *Server) (*lightClient, error) {
+	if !s.config.P2PStateExchangeExtensions {
+		return nil, errors.New("P2PStateExchangeExtensions are required")
+	}
+	module, ok := s.chain.GetStateModule().(lightStateModule)
+	if !ok {
+		return nil, errors.New("state module doesn't support light mode")
+	}
+	if s.LightNode.ProofCacheSize <= 0 {
+		s.LightNode.ProofCacheSize = defaultProofCacheSize
+	}
+	if s.LightNode.ProofTimeout <= 0 {
+		s.LightNode.ProofTimeout = defaultProofTimeout
+	}
+	proofs, err := lru.New(s.LightNode.ProofCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	known, err := lru.New(lightKnownRoots)
+	if err != nil {
+		return nil, err
+	}
+	l := &lightClient{
+		s:       s,
+		module:  module,
+		timeout: s.LightNode.ProofTimeout,
+		log:     s.log,
+		proofs:  proofs,
+		known:   known,
+		wake:    make(chan struct{}, 1),
+		pending: make(map[uint32]*state.MPTRoot),
+		waiters: make(map[string]map[chan *stateProofResult]struct{}),
+	}
+	if err := l.init(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// init restores state validators from the latest validated root or sets the
+// configured ones if there is no such root yet.
+func (l *lightClient) init() error {
+	h := l.module.CurrentValidatedHeight()
+	if h == 0 {
+		pubs, err := keys.NewPublicKeysFromStrings(l.s.LightNode.StateValidators)
+		if err != nil {
+			return fmt.Errorf("invalid state validators: %w", err)
+		}
+		if len(pubs) == 0 {
+			return errors.New("no state validators configured")
+		}
+		l.module.UpdateStateValidators(0, pubs)
+		return nil
+	}
+	sr, err := l.module.GetStateRoot(h)
+	if err != nil {
+		return fmt.Errorf("can't get validated state root %d: %w", h, err)
+	}
+	pubs, err := witnessKeys(sr.Witness.VerificationScript)
+	if err != nil {
+		return fmt.Errorf("can't get state validators from root %d: %w", h, err)
+	}
+	l.module.UpdateStateValidators(h, pubs)
+	l.known.Add(sr.Root, sr.Index)
+	// Designations learned before the restart are lost, so recheck them.
+	l.check, l.checkFrom = sr, sr.Index+1
+	return nil
+}
+
+// witnessKeys returns the keys from the verification script.
+func witnessKeys(script []byte) (keys.PublicKeys, error) {
+	if crypto.IsMultiVerificationScript(script) {
+		pubs, _, err := crypto.ParseMultiVerificationScript(script)
+		return pubs, err
+	}
+	pub, err := crypto.ParseVerificationScript(script)
+	if err != nil {
+		return nil, err
+	}
+	return keys.PublicKeys{pub}, nil
+}
+
+// run processes the received state roots until the server is stopped.
+func (l *lightClient) run() {
+	for {
+		select {
+		case <-l.s.quit:
+			return
+		case <-l.wake:
+			l.processRoots()
+		}
+	}
+}
+
+func (l *lightClient) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// requestRoots requests the state roots following the latest validated one
+// from the peer, the latest root is requested if there is none yet.
+func (l *lightClient) requestRoots(p Peer) error {
+	if !p.IsFullNode() {
+		return nil
+	}
+	// Roots received earlier may be waiting for proofs.
+	l.signal()
+	h := l.module.CurrentValidatedHeight()
+	if h == 0 {
+		return p.EnqueueP2PMessage(NewMessage(CMDGetStateRoot, payload.NewGetStateRoot(payload.LatestStateRoot)))
+	}
+	for i := h + 1; i <= h+lightRootsWindow && i <= p.LastBlockIndex(); i++ {
+		l.lock.Lock()
+		_, ok := l.pending[i]
+		l.lock.Unlock()
+		if ok {
+			continue
+		}
+		err := p.EnqueueP2PMessage(NewMessage(CMDGetStateRoot, payload.NewGetStateRoot(i)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addRoot saves the received state root for processing.
+func (l *lightClient) addRoot(sr *state.MPTRoot) error {
+	if len(sr.Witness.VerificationScript) == 0 {
+		return fmt.Errorf("%w: state root is not signed", errInvalidMessage)
+	}
+	h := l.module.CurrentValidatedHeight()
+	l.lock.Lock()
+	if h == 0 {
+		if l.anchor == nil || l.anchor.Index < sr.Index {
+			l.anchor = sr
+		}
+	} else if sr.Index > h && sr.Index <= h+lightRootsWindow {
+		l.pending[sr.Index] = sr
+	}
+	l.lock.Unlock()
+	l.signal()
+	return nil
+}
+
+// takeRoot returns the next state root to be validated.
+func (l *lightClient) takeRoot() *state.MPTRoot {
+	h := l.module.CurrentValidatedHeight()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if h == 0 {
+		sr := l.anchor
+		l.anchor = nil
+		return sr
+	}
+	for i := range l.pending {
+		if i <= h {
+			delete(l.pending, i)
+		}
+	}
+	sr := l.pending[h+1]
+	delete(l.pending, h+1)
+	return sr
+}
+
+// processRoots validates pending state roots in order.
+func (l *lightClient) processRoots() {
+	var advanced bool
+	for {
+		if l.check != nil {
+			if err := l.checkDesignations(); err != nil {
+				l.log.Warn("failed to check state validators designation",
+					zap.Uint32("root", l.check.Index), zap.Error(err))
+				return
+			}
+		}
+		sr := l.takeRoot()
+		if sr == nil {
+			break
+		}
+		first := l.module.CurrentValidatedHeight() == 0
+		if err := l.module.AddLightStateRoot(sr); err != nil {
+			l.log.Warn("invalid state root", zap.Uint32("index", sr.Index), zap.Error(err))
+			continue
+		}
+		advanced = true
+		l.known.Add(sr.Root, sr.Index)
+		l.check, l.checkFrom = sr, sr.Index+2
+		if first {
+			l.checkFrom = sr.Index + 1
+			l.log.Info("light node anchored at state root", zap.Uint32("index", sr.Index),
+				zap.Stringer("root", sr.Root))
+		}
+	}
+	if advanced {
+		peers := l.s.getPeers(func(p Peer) bool { return p.Handshaked() && p.IsFullNode() })
+		if len(peers) != 0 {
+			_ = l.requestRoots(peers[mrand.Intn(len(peers))])
+		}
+	}
+}
+
+// checkDesignations updates state validators with the designations taking
+// effect up to two blocks after the last validated root, designation made in
+// block N takes effect at N+2 and is present in the state since N.
+func (l *lightClient) checkDesignations() error {
+	for i := l.checkFrom; i <= l.check.Index+2; i++ {
+		key := stateKey(native.DesignationAddress, native.MakeRoleKey(noderoles.StateValidator, i))
+		res, err := l.getProof(l.check.Root, key)
+		if err != nil {
+			return err
+		}
+		if res.exists {
+			var pubs keys.PublicKeys
+			if err := pubs.DecodeBytes(res.value); err != nil {
+				return fmt.Errorf("invalid designation at %d: %w", i, err)
+			}
+			l.module.UpdateStateValidators(i, pubs)
+			l.log.Info("state validators updated", zap.Uint32("index", i), zap.Int("count", len(pubs)))
+		}
+		l.checkFrom = i + 1
+	}
+	l.check = nil
+	return nil
+}
+
+// stateKey returns the MPT key of the contract storage item.
+func stateKey(contract common.Address, key []byte) []byte {
+	k := make([]byte, common.AddressLength+len(key))
+	copy(k, contract.Bytes())
+	copy(k[common.AddressLength:], key)
+	return k
+}
+
+// getState returns the contract storage item at the validated state root,
+// mpt.ErrNotFound is returned if it doesn't exist.
+func (l *lightClient) getState(root common.Hash, contract common.Address, key []byte) ([]byte, error) {
+	if !l.known.Contains(root) {
+		return nil, ErrUnknownStateRoot
+	}
+	res, err := l.getProof(root, stateKey(contract, key))
+	if err != nil {
+		return nil, err
+	}
+	if !res.exists {
+		return nil, mpt.ErrNotFound
+	}
+	return res.value, nil
+}
+
+// getProof returns the verified state proof result from the cache or from
+// the peers.
+func (l *lightClient) getProof(root common.Hash, key []byte) (*stateProofResult, error) {
+	id := string(root[:]) + string(key)
+	if res, ok := l.proofs.Get(id); ok {
+		return res.(*stateProofResult), nil
+	}
+	tried := make(map[Peer]bool)
+	for i := 0; i < lightProofAttempts; i++ {
+		peers := l.s.getPeers(func(p Peer) bool {
+			return p.Handshaked() && p.IsFullNode() && !tried[p]
+		})
+		if len(peers) == 0 {
+			break
+		}
+		p := peers[mrand.Intn(len(peers))]
+		tried[p] = true
+		if res := l.requestProof(p, id, root, key); res != nil {
+			lightProofsFetched.Inc()
+			return res, nil
+		}
+	}
+	lightProofFailures.Inc()
+	return nil, ErrNoStateProof
+}
+
+// requestProof requests the proof from the peer and waits for the response.
+func (l *lightClient) requestProof(p Peer, id string, root common.Hash, key []byte) *stateProofResult {
+	ch := make(chan *stateProofResult, 1)
+	l.lock.Lock()
+	if l.waiters[id] == nil {
+		l.waiters[id] = make(map[chan *stateProofResult]struct{})
+	}
+	l.waiters[id][ch] = struct{}{}
+	l.lock.Unlock()
+	defer func() {
+		l.lock.Lock()
+		delete(l.waiters[id], ch)
+		if len(l.waiters[id]) == 0 {
+			delete(l.waiters, id)
+		}
+		l.lock.Unlock()
+	}()
+
+	err := p.EnqueueP2PMessage(NewMessage(CMDGetStateProof, &payload.GetStateProof{Root: root, Key: key}))
+	if err != nil {
+		return nil
+	}
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-ch:
+		return res
+	case <-timer.C:
+	case <-l.s.quit:
+	}
+	return nil
+}
+
+// addProof verifies the received state proof and passes its result to the
+// requests waiting for it. Empty proof means the peer doesn't have the state.
+func (l *lightClient) addProof(sp *payload.StateProof) error {
+	id := string(sp.Root[:]) + string(sp.Key)
+	l.lock.Lock()
+	waiting := len(l.waiters[id]) != 0
+	l.lock.Unlock()
+	if !waiting {
+		// The response may come after the timeout.
+		return nil
+	}
+	var res *stateProofResult
+	if len(sp.Proof) != 0 {
+		if val, ok := mpt.VerifyProof(sp.Root, sp.Key, sp.Proof); ok {
+			res = &stateProofResult{value: val, exists: true}
+		} else if mpt.VerifyAbsenceProof(sp.Root, sp.Key, sp.Proof) {
+			res = &stateProofResult{}
+		} else {
+			return fmt.Errorf("%w: invalid state proof", errInvalidMessage)
+		}
+		l.proofs.Add(id, res)
+	}
+	l.lock.Lock()
+	for ch := range l.waiters[id] {
+		select {
+		case ch <- res:
+		default:
+		}
+	}
+	l.lock.Unlock()
+	return nil
+}
+
+// IsLightNode checks whether the node runs in the headers-only mode.
+func (s *Server) IsLightNode() bool {
+	return s.light != nil
+}
+
+// GetVerifiedState returns the contract storage item at the validated state
+// root, it's fetched from peers and verified with the state proof. It's only
+// available in the light node mode, mpt.ErrNotFound is returned for the
+// missing item.
+func (s *Server) GetVerifiedState(root common.Hash, contract common.Address, key []byte) ([]byte, error) {
+	if s.light == nil {
+		return nil, errors.New("not a light node")
+	}
+	return s.light.getState(root, contract, key)
+}
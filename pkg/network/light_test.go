@@ -0,0 +1,68 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLightClientProofs(t *testing.T) {
+	tr := mpt.NewTrie(nil, mpt.ModeAll, storage.NewMemCachedStore(storage.NewMemoryStore()))
+	contract := common.HexToAddress("0x0102")
+	require.NoError(t, tr.Put(stateKey(contract, []byte{1}), []byte("value")))
+	require.NoError(t, tr.Put(stateKey(contract, []byte{2}), []byte("other")))
+	tr.Flush(0)
+	root := tr.StateRoot()
+
+	proofs, err := lru.New(16)
+	require.NoError(t, err)
+	l := &lightClient{
+		proofs:  proofs,
+		waiters: make(map[string]map[chan *stateProofResult]struct{}),
+	}
+	wait := func(key []byte) chan *stateProofResult {
+		ch := make(chan *stateProofResult, 1)
+		l.waiters[string(root[:])+string(key)] = map[chan *stateProofResult]struct{}{ch: {}}
+		return ch
+	}
+
+	key := stateKey(contract, []byte{1})
+	proof, err := tr.GetProof(key)
+	require.NoError(t, err)
+	sp := &payload.StateProof{Root: root, Key: key, Proof: proof}
+	// Not requested.
+	require.NoError(t, l.addProof(sp))
+	require.Equal(t, 0, l.proofs.Len())
+
+	ch := wait(key)
+	require.NoError(t, l.addProof(sp))
+	res := <-ch
+	require.True(t, res.exists)
+	require.Equal(t, []byte("value"), res.value)
+	require.Equal(t, 1, l.proofs.Len())
+
+	missing := stateKey(contract, []byte{3})
+	proof, err = tr.GetProof(missing)
+	require.True(t, errors.Is(err, mpt.ErrNotFound))
+	ch = wait(missing)
+	require.NoError(t, l.addProof(&payload.StateProof{Root: root, Key: missing, Proof: proof}))
+	res = <-ch
+	require.False(t, res.exists)
+
+	// Peer without the state.
+	ch = wait(missing)
+	require.NoError(t, l.addProof(&payload.StateProof{Root: root, Key: missing}))
+	require.Nil(t, <-ch)
+
+	// Proof of the other key.
+	proof, err = tr.GetProof(stateKey(contract, []byte{2}))
+	require.NoError(t, err)
+	wait(key)
+	require.ErrorIs(t, l.addProof(&payload.StateProof{Root: root, Key: key, Proof: proof}), errInvalidMessage)
+}
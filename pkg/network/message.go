@@ -1,10 +1,10 @@
 package network
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
@@ -73,6 +73,13 @@ const (
 	CMDP2PNotaryRequest             = CommandType(payload.P2PNotaryRequestType)
 	CMDGetMPTData       CommandType = 0x51 // 0x5.. commands are used for extensions (P2PNotary, state exchange cmds)
 	CMDMPTData          CommandType = 0x52
+	CMDGetStateRoot     CommandType = 0x53
+	CMDStateRoot        CommandType = 0x54
+	CMDCompactBlock     CommandType = 0x55
+	CMDGetBlockTxn      CommandType = 0x56
+	CMDBlockTxn         CommandType = 0x57
+	CMDGetStateProof    CommandType = 0x58
+	CMDStateProof       CommandType = 0x59
 	CMDReject           CommandType = 0x2f
 
 	// SPV protocol.
@@ -109,19 +116,23 @@ func (m *Message) Decode(br *io.BinReader) error {
 		case CMDFilterClear, CMDGetAddr, CMDMempool, CMDVerack:
 			m.Payload = payload.NewNullPayload()
 		default:
-			return fmt.Errorf("unexpected empty payload: %s", m.Command)
+			return fmt.Errorf("%w: unexpected empty payload: %s", errInvalidMessage, m.Command)
 		}
 		return nil
 	}
 	if l > payload.MaxSize {
-		return errors.New("invalid payload size")
+		return fmt.Errorf("%w: invalid payload size", errInvalidMessage)
 	}
 	m.compressedPayload = make([]byte, l)
 	br.ReadBytes(m.compressedPayload)
 	if br.Err != nil {
 		return br.Err
 	}
-	return m.decodePayload()
+	err := m.decodePayload()
+	if err != nil && err != payload.ErrTooManyHeaders {
+		return fmt.Errorf("%w: %s: %v", errInvalidMessage, m.Command, err)
+	}
+	return err
 }
 
 func (m *Message) decodePayload() error {
@@ -145,6 +156,10 @@ func (m *Message) decodePayload() error {
 		p = &payload.MPTInventory{}
 	case CMDMPTData:
 		p = &payload.MPTData{}
+	case CMDGetStateRoot:
+		p = &payload.GetStateRoot{}
+	case CMDStateRoot:
+		p = &state.MPTRoot{}
 	case CMDAddr:
 		p = &payload.AddressList{}
 	case CMDBlock:
@@ -166,6 +181,20 @@ func (m *Message) decodePayload() error {
 		}
 		m.Payload = p
 		return nil
+	case CMDCompactBlock:
+		p = &payload.CompactBlock{}
+	case CMDGetBlockTxn:
+		p = &payload.GetBlockTxn{}
+	case CMDBlockTxn:
+		p = &payload.BlockTxn{}
+	case CMDGetStateProof:
+		p = &payload.GetStateProof{}
+	case CMDStateProof:
+		p = &payload.StateProof{}
+	case CMDFilterLoad:
+		p = &payload.FilterLoad{}
+	case CMDFilterAdd:
+		p = &payload.FilterAdd{}
 	case CMDMerkleBlock:
 		p = &payload.MerkleBlock{}
 	case CMDPing, CMDPong:
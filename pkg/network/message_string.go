@@ -28,6 +28,13 @@ func _() {
 	_ = x[CMDP2PNotaryRequest-80]
 	_ = x[CMDGetMPTData-81]
 	_ = x[CMDMPTData-82]
+	_ = x[CMDGetStateRoot-83]
+	_ = x[CMDStateRoot-84]
+	_ = x[CMDCompactBlock-85]
+	_ = x[CMDGetBlockTxn-86]
+	_ = x[CMDBlockTxn-87]
+	_ = x[CMDGetStateProof-88]
+	_ = x[CMDStateProof-89]
 	_ = x[CMDReject-47]
 	_ = x[CMDFilterLoad-48]
 	_ = x[CMDFilterAdd-49]
@@ -46,7 +53,7 @@ const (
 	_CommandType_name_6 = "CMDExtensibleCMDRejectCMDFilterLoadCMDFilterAddCMDFilterClear"
 	_CommandType_name_7 = "CMDMerkleBlock"
 	_CommandType_name_8 = "CMDAlert"
-	_CommandType_name_9 = "CMDP2PNotaryRequestCMDGetMPTDataCMDMPTData"
+	_CommandType_name_9 = "CMDP2PNotaryRequestCMDGetMPTDataCMDMPTDataCMDGetStateRootCMDStateRootCMDCompactBlockCMDGetBlockTxnCMDBlockTxnCMDGetStateProofCMDStateProof"
 )
 
 var (
@@ -57,7 +64,7 @@ var (
 	_CommandType_index_4 = [...]uint8{0, 12, 22}
 	_CommandType_index_5 = [...]uint8{0, 6, 16, 34, 45, 50, 58}
 	_CommandType_index_6 = [...]uint8{0, 13, 22, 35, 47, 61}
-	_CommandType_index_9 = [...]uint8{0, 19, 32, 42}
+	_CommandType_index_9 = [...]uint8{0, 19, 32, 42, 57, 69, 84, 98, 109, 125, 138}
 )
 
 func (i CommandType) String() string {
@@ -86,7 +93,7 @@ func (i CommandType) String() string {
 		return _CommandType_name_7
 	case i == 64:
 		return _CommandType_name_8
-	case 80 <= i && i <= 82:
+	case 80 <= i && i <= 89:
 		i -= 80
 		return _CommandType_name_9[_CommandType_index_9[i]:_CommandType_index_9[i+1]]
 	default:
@@ -0,0 +1,76 @@
+package payload
+
+import (
+	"errors"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GetBlockTxn is the request for transactions of the compact block missing
+// in the receiver's mempool.
+type GetBlockTxn struct {
+	BlockHash common.Hash
+	// Indexes are sorted indexes of transactions in the block.
+	Indexes []uint16
+}
+
+// BlockTxn is the response to GetBlockTxn, transactions are in the order
+// of requested indexes.
+type BlockTxn struct {
+	BlockHash    common.Hash
+	Transactions []*transaction.Transaction
+}
+
+// DecodeBinary implements Serializable interface.
+func (g *GetBlockTxn) DecodeBinary(br *io.BinReader) {
+	br.ReadBytes(g.BlockHash[:])
+	n := br.ReadVarUint()
+	if n == 0 || n > block.MaxTransactionsPerBlock {
+		br.Err = errors.New("invalid indexes count")
+		return
+	}
+	g.Indexes = make([]uint16, n)
+	for i := range g.Indexes {
+		g.Indexes[i] = br.ReadU16LE()
+		if br.Err == nil && i > 0 && g.Indexes[i] <= g.Indexes[i-1] {
+			br.Err = errors.New("indexes are not sorted")
+			return
+		}
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (g *GetBlockTxn) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteBytes(g.BlockHash[:])
+	bw.WriteVarUint(uint64(len(g.Indexes)))
+	for _, i := range g.Indexes {
+		bw.WriteU16LE(i)
+	}
+}
+
+// DecodeBinary implements Serializable interface.
+func (b *BlockTxn) DecodeBinary(br *io.BinReader) {
+	br.ReadBytes(b.BlockHash[:])
+	n := br.ReadVarUint()
+	if n > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	b.Transactions = make([]*transaction.Transaction, n)
+	for i := range b.Transactions {
+		b.Transactions[i] = &transaction.Transaction{}
+		b.Transactions[i].DecodeBinary(br)
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (b *BlockTxn) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteBytes(b.BlockHash[:])
+	bw.WriteVarUint(uint64(len(b.Transactions)))
+	for _, tx := range b.Transactions {
+		tx.EncodeBinary(bw)
+	}
+}
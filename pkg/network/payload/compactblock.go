@@ -0,0 +1,124 @@
+package payload
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ShortIDSize is the size of the short transaction ID in bytes.
+const ShortIDSize = 6
+
+// CompactBlock is the block with transactions replaced by short IDs, the
+// receiver restores them from its mempool. Transactions the receiver is
+// unlikely to have are included as is.
+type CompactBlock struct {
+	*block.Header
+	// Nonce is the salt of short IDs, it makes collisions unpredictable.
+	Nonce     uint64
+	ShortIDs  []uint64
+	Prefilled []PrefilledTx
+}
+
+// PrefilledTx is the transaction included into compact block with its index
+// in the block.
+type PrefilledTx struct {
+	Index uint16
+	Tx    *transaction.Transaction
+}
+
+// NewCompactBlock returns compact block for the given block, transactions
+// for which prefill returns true are included as is.
+func NewCompactBlock(b *block.Block, nonce uint64, prefill func(*transaction.Transaction) bool) *CompactBlock {
+	c := &CompactBlock{
+		Header: &b.Header,
+		Nonce:  nonce,
+	}
+	for i, tx := range b.Transactions {
+		if prefill != nil && prefill(tx) {
+			c.Prefilled = append(c.Prefilled, PrefilledTx{Index: uint16(i), Tx: tx})
+		} else {
+			c.ShortIDs = append(c.ShortIDs, c.ShortID(tx.Hash()))
+		}
+	}
+	return c
+}
+
+// TxCount returns the number of transactions in the block.
+func (c *CompactBlock) TxCount() int {
+	return len(c.ShortIDs) + len(c.Prefilled)
+}
+
+// ShortID returns the short ID of the transaction in this block.
+func (c *CompactBlock) ShortID(h common.Hash) uint64 {
+	buf := make([]byte, 2*common.HashLength+8)
+	bh := c.Hash()
+	copy(buf, bh[:])
+	binary.LittleEndian.PutUint64(buf[common.HashLength:], c.Nonce)
+	copy(buf[common.HashLength+8:], h[:])
+	sum := hash.Sha256(buf)
+	var res uint64
+	for i := ShortIDSize - 1; i >= 0; i-- {
+		res = res<<8 | uint64(sum[i])
+	}
+	return res
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *CompactBlock) DecodeBinary(br *io.BinReader) {
+	c.Header = &block.Header{}
+	c.Header.DecodeBinary(br)
+	c.Nonce = br.ReadU64LE()
+	n := br.ReadVarUint()
+	if n > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	c.ShortIDs = make([]uint64, n)
+	buf := make([]byte, 8)
+	for i := range c.ShortIDs {
+		br.ReadBytes(buf[:ShortIDSize])
+		c.ShortIDs[i] = binary.LittleEndian.Uint64(buf)
+	}
+	n = br.ReadVarUint()
+	if n+uint64(len(c.ShortIDs)) > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	c.Prefilled = make([]PrefilledTx, n)
+	for i := range c.Prefilled {
+		c.Prefilled[i].Index = br.ReadU16LE()
+		c.Prefilled[i].Tx = &transaction.Transaction{}
+		c.Prefilled[i].Tx.DecodeBinary(br)
+		if br.Err != nil {
+			return
+		}
+		if int(c.Prefilled[i].Index) >= c.TxCount() ||
+			i > 0 && c.Prefilled[i].Index <= c.Prefilled[i-1].Index {
+			br.Err = errors.New("invalid prefilled transaction index")
+			return
+		}
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *CompactBlock) EncodeBinary(bw *io.BinWriter) {
+	c.Header.EncodeBinary(bw)
+	bw.WriteU64LE(c.Nonce)
+	bw.WriteVarUint(uint64(len(c.ShortIDs)))
+	buf := make([]byte, 8)
+	for _, id := range c.ShortIDs {
+		binary.LittleEndian.PutUint64(buf, id)
+		bw.WriteBytes(buf[:ShortIDSize])
+	}
+	bw.WriteVarUint(uint64(len(c.Prefilled)))
+	for _, p := range c.Prefilled {
+		bw.WriteU16LE(p.Index)
+		p.Tx.EncodeBinary(bw)
+	}
+}
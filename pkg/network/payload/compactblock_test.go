@@ -0,0 +1,87 @@
+package payload
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlock(n int) *block.Block {
+	b := &block.Block{Header: block.Header{Index: 1}}
+	to := common.HexToAddress("0x02")
+	for i := 0; i < n; i++ {
+		b.Transactions = append(b.Transactions, transaction.NewTx(&transaction.NeoTx{
+			Nonce:    uint64(i),
+			GasPrice: big.NewInt(1),
+			Gas:      21000,
+			From:     common.HexToAddress("0x01"),
+			To:       &to,
+			Value:    big.NewInt(0),
+			Witness: transaction.Witness{
+				InvocationScript:   []byte{0},
+				VerificationScript: []byte{0},
+			},
+		}))
+	}
+	b.RebuildMerkleRoot()
+	return b
+}
+
+func TestCompactBlock(t *testing.T) {
+	b := newTestBlock(5)
+	prefilled := b.Transactions[3].Hash()
+	c := NewCompactBlock(b, 42, func(tx *transaction.Transaction) bool {
+		return tx.Hash() == prefilled
+	})
+	require.Equal(t, 5, c.TxCount())
+	require.Equal(t, 4, len(c.ShortIDs))
+	require.Equal(t, uint16(3), c.Prefilled[0].Index)
+	for _, id := range c.ShortIDs {
+		require.Zero(t, id>>(8*ShortIDSize))
+	}
+	// Short IDs depend on the nonce.
+	other := NewCompactBlock(b, 43, nil)
+	require.NotEqual(t, c.ShortIDs[0], other.ShortIDs[0])
+
+	data, err := io.ToByteArray(c)
+	require.NoError(t, err)
+	actual := new(CompactBlock)
+	require.NoError(t, io.FromByteArray(actual, data))
+	require.Equal(t, c.Nonce, actual.Nonce)
+	require.Equal(t, c.ShortIDs, actual.ShortIDs)
+	require.Equal(t, prefilled, actual.Prefilled[0].Tx.Hash())
+	require.Equal(t, b.Hash(), actual.Hash())
+
+	c.Prefilled[0].Index = 5
+	data, err = io.ToByteArray(c)
+	require.NoError(t, err)
+	require.Error(t, io.FromByteArray(new(CompactBlock), data))
+}
+
+func TestBlockTxn(t *testing.T) {
+	b := newTestBlock(2)
+	req := &GetBlockTxn{BlockHash: b.Hash(), Indexes: []uint16{0, 1}}
+	data, err := io.ToByteArray(req)
+	require.NoError(t, err)
+	actualReq := new(GetBlockTxn)
+	require.NoError(t, io.FromByteArray(actualReq, data))
+	require.Equal(t, req, actualReq)
+
+	req.Indexes = []uint16{1, 0}
+	data, err = io.ToByteArray(req)
+	require.NoError(t, err)
+	require.Error(t, io.FromByteArray(new(GetBlockTxn), data))
+
+	resp := &BlockTxn{BlockHash: b.Hash(), Transactions: b.Transactions}
+	data, err = io.ToByteArray(resp)
+	require.NoError(t, err)
+	actualResp := new(BlockTxn)
+	require.NoError(t, io.FromByteArray(actualResp, data))
+	require.Equal(t, 2, len(actualResp.Transactions))
+	require.Equal(t, b.Transactions[1].Hash(), actualResp.Transactions[1].Hash())
+}
@@ -0,0 +1,21 @@
+package payload
+
+import (
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+)
+
+// FilterAdd is the element added to the filter previously loaded by the
+// light node.
+type FilterAdd struct {
+	Data []byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (f *FilterAdd) DecodeBinary(br *io.BinReader) {
+	f.Data = br.ReadVarBytes(MaxFilterAddSize)
+}
+
+// EncodeBinary implements Serializable interface.
+func (f *FilterAdd) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(f.Data)
+}
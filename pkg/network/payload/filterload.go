@@ -0,0 +1,140 @@
+package payload
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+)
+
+const (
+	// MaxFilterSize is the maximum size of the bloom filter in bytes.
+	MaxFilterSize = 36000
+	// MaxFilterHashFuncs is the maximum number of hash functions of the
+	// bloom filter.
+	MaxFilterHashFuncs = 50
+	// MaxFilterAddSize is the maximum size of the element added to the
+	// filter.
+	MaxFilterAddSize = 520
+)
+
+// FilterLoad is the bloom filter (as defined by BIP37) set by the light node
+// to receive only the data it's interested in.
+type FilterLoad struct {
+	Filter []byte
+	K      uint8
+	Tweak  uint32
+}
+
+// NewFilterLoad returns an empty filter sized for the given number of
+// elements and false positive rate.
+func NewFilterLoad(elements int, fpRate float64, tweak uint32) *FilterLoad {
+	if elements < 1 {
+		elements = 1
+	}
+	size := -1 / (math.Ln2 * math.Ln2) * float64(elements) * math.Log(fpRate) / 8
+	size = math.Max(1, math.Min(size, MaxFilterSize))
+	k := size * 8 / float64(elements) * math.Ln2
+	k = math.Max(1, math.Min(k, MaxFilterHashFuncs))
+	return &FilterLoad{
+		Filter: make([]byte, int(size)),
+		K:      uint8(k),
+		Tweak:  tweak,
+	}
+}
+
+// Add adds the data to the filter.
+func (f *FilterLoad) Add(data []byte) {
+	if len(f.Filter) == 0 {
+		return
+	}
+	for i := uint32(0); i < uint32(f.K); i++ {
+		n := f.bit(i, data)
+		f.Filter[n/8] |= 1 << (n % 8)
+	}
+}
+
+// Matches checks whether the data matches the filter.
+func (f *FilterLoad) Matches(data []byte) bool {
+	if len(f.Filter) == 0 {
+		return false
+	}
+	for i := uint32(0); i < uint32(f.K); i++ {
+		n := f.bit(i, data)
+		if f.Filter[n/8]&(1<<(n%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a deep copy of the filter.
+func (f *FilterLoad) Copy() *FilterLoad {
+	res := *f
+	res.Filter = append([]byte{}, f.Filter...)
+	return &res
+}
+
+func (f *FilterLoad) bit(i uint32, data []byte) uint32 {
+	return murmur3(i*0xFBA4C795+f.Tweak, data) % (uint32(len(f.Filter)) * 8)
+}
+
+// DecodeBinary implements Serializable interface.
+func (f *FilterLoad) DecodeBinary(br *io.BinReader) {
+	f.Filter = br.ReadVarBytes(MaxFilterSize)
+	f.K = br.ReadB()
+	f.Tweak = br.ReadU32LE()
+	if br.Err == nil && f.K > MaxFilterHashFuncs {
+		br.Err = errors.New("too many hash functions")
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (f *FilterLoad) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(f.Filter)
+	bw.WriteB(f.K)
+	bw.WriteU32LE(f.Tweak)
+}
+
+// murmur3 is the 32-bit MurmurHash3 used by BIP37 filters.
+func murmur3(seed uint32, data []byte) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := uint32(data[4*i]) | uint32(data[4*i+1])<<8 | uint32(data[4*i+2])<<16 | uint32(data[4*i+3])<<24
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+	var k uint32
+	tail := data[4*n:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
@@ -0,0 +1,59 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMurmur3(t *testing.T) {
+	testCases := []struct {
+		seed     uint32
+		data     []byte
+		expected uint32
+	}{
+		{0, nil, 0},
+		{0xFBA4C795, nil, 0x6a396f08},
+		{0, []byte{0x00}, 0x514e28b7},
+		{0, []byte{0xff}, 0xfd6cf10d},
+		{0, []byte{0x00, 0x11}, 0x16c6b7ab},
+		{0, []byte{0x00, 0x11, 0x22}, 0x8eb51c3d},
+		{0, []byte{0x00, 0x11, 0x22, 0x33}, 0xb4471bf8},
+		{0, []byte{0x00, 0x11, 0x22, 0x33, 0x44}, 0xe2301fa8},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, murmur3(tc.seed, tc.data), "%x", tc.data)
+	}
+}
+
+func TestFilterLoad(t *testing.T) {
+	f := NewFilterLoad(3, 0.01, 5)
+	require.True(t, len(f.Filter) > 0)
+	require.True(t, f.K > 0)
+
+	addr := common.HexToAddress("0x5b38da6a701c568545dcfcb03fcb875f56beddc4")
+	h := common.HexToHash("0x8228840c950c5c7402828d2f073d5973ded9f6147ae6a57767d68229531a2082")
+	f.Add(addr.Bytes())
+	f.Add(h.Bytes())
+	require.True(t, f.Matches(addr.Bytes()))
+	require.True(t, f.Matches(h.Bytes()))
+	require.False(t, f.Matches(common.HexToAddress("0x01").Bytes()))
+
+	c := f.Copy()
+	c.Add([]byte{1, 2, 3})
+	require.True(t, c.Matches([]byte{1, 2, 3}))
+	require.False(t, f.Matches([]byte{1, 2, 3}))
+
+	b, err := io.ToByteArray(f)
+	require.NoError(t, err)
+	actual := new(FilterLoad)
+	require.NoError(t, io.FromByteArray(actual, b))
+	require.Equal(t, f, actual)
+
+	f.K = MaxFilterHashFuncs + 1
+	b, err = io.ToByteArray(f)
+	require.NoError(t, err)
+	require.Error(t, io.FromByteArray(new(FilterLoad), b))
+}
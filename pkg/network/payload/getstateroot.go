@@ -0,0 +1,33 @@
+package payload
+
+import (
+	"math"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+)
+
+// LatestStateRoot is the GetStateRoot index requesting the latest validated
+// state root.
+const LatestStateRoot = math.MaxUint32
+
+// GetStateRoot payload.
+type GetStateRoot struct {
+	Index uint32
+}
+
+// NewGetStateRoot returns GetStateRoot payload with specified index.
+func NewGetStateRoot(index uint32) *GetStateRoot {
+	return &GetStateRoot{
+		Index: index,
+	}
+}
+
+// DecodeBinary implements Serializable interface.
+func (g *GetStateRoot) DecodeBinary(br *io.BinReader) {
+	g.Index = br.ReadU32LE()
+}
+
+// EncodeBinary implements Serializable interface.
+func (g *GetStateRoot) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteU32LE(g.Index)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -16,6 +17,31 @@ type MerkleBlock struct {
 	Flags   []byte
 }
 
+// NewMerkleBlock returns MerkleBlock for the block header and transaction
+// hashes proving inclusion of the transactions marked in matches.
+func NewMerkleBlock(h *block.Header, txHashes []common.Hash, matches []bool) *MerkleBlock {
+	hashes, flags := hash.NewPartialMerkleTree(txHashes, matches)
+	return &MerkleBlock{
+		Header:  h,
+		TxCount: len(txHashes),
+		Hashes:  hashes,
+		Flags:   flags,
+	}
+}
+
+// Verify checks the partial merkle tree against the header merkle root and
+// returns the hashes of matched transactions.
+func (m *MerkleBlock) Verify() ([]common.Hash, error) {
+	root, matched, err := hash.ExtractPartialMerkleTree(m.TxCount, m.Hashes, m.Flags)
+	if err != nil {
+		return nil, err
+	}
+	if root != m.MerkleRoot {
+		return nil, errors.New("merkle root mismatch")
+	}
+	return matched, nil
+}
+
 // DecodeBinary implements Serializable interface.
 func (m *MerkleBlock) DecodeBinary(br *io.BinReader) {
 	m.Header = &block.Header{}
@@ -28,15 +54,18 @@ func (m *MerkleBlock) DecodeBinary(br *io.BinReader) {
 	}
 	m.TxCount = txCount
 	count := br.ReadVarUint()
-	if uint64(txCount) != count {
-		br.Err = errors.New("invalid tx count")
+	// Partial tree has at least one hash (the root) and at most one hash
+	// per transaction.
+	if count > uint64(txCount) || (count == 0) != (txCount == 0) {
+		br.Err = errors.New("invalid hashes count")
 		return
 	}
 	m.Hashes = make([]common.Hash, count)
 	for i := uint64(0); i < count; i++ {
 		br.ReadBytes(m.Hashes[i][:])
 	}
-	m.Flags = br.ReadVarBytes((txCount + 7) / 8)
+	// There is at most one flag per tree node.
+	m.Flags = br.ReadVarBytes((hash.MerkleTreeSize(txCount) + 7) / 8)
 }
 
 // EncodeBinary implements Serializable interface.
@@ -0,0 +1,73 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleBlock(t *testing.T) {
+	hashes := make([]common.Hash, 5)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash([]byte{byte(i + 1)})
+	}
+	h := &block.Header{
+		MerkleRoot: hash.CalcMerkleRoot(append([]common.Hash{}, hashes...)),
+		Index:      10,
+	}
+	m := NewMerkleBlock(h, hashes, []bool{false, false, true, false, false})
+
+	b, err := io.ToByteArray(m)
+	require.NoError(t, err)
+	actual := new(MerkleBlock)
+	require.NoError(t, io.FromByteArray(actual, b))
+	require.Equal(t, m.TxCount, actual.TxCount)
+	require.Equal(t, m.Hashes, actual.Hashes)
+	require.Equal(t, m.Flags, actual.Flags)
+	require.Equal(t, h.Hash(), actual.Hash())
+
+	matched, err := actual.Verify()
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{hashes[2]}, matched)
+
+	actual.MerkleRoot = common.Hash{}
+	_, err = actual.Verify()
+	require.Error(t, err)
+}
+
+func TestMerkleBlockDecodeBounds(t *testing.T) {
+	hashes := make([]common.Hash, 5)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash([]byte{byte(i + 1)})
+	}
+	check := func(m *MerkleBlock) error {
+		b, err := io.ToByteArray(m)
+		require.NoError(t, err)
+		return io.FromByteArray(new(MerkleBlock), b)
+	}
+	newBlock := func() *MerkleBlock {
+		return NewMerkleBlock(&block.Header{}, hashes, []bool{true, true, true, true, true})
+	}
+	require.NoError(t, check(newBlock()))
+
+	m := newBlock()
+	m.Hashes = append(m.Hashes, common.Hash{})
+	require.Error(t, check(m))
+
+	m = newBlock()
+	m.Hashes = nil
+	require.Error(t, check(m))
+
+	m = newBlock()
+	m.Flags = append(m.Flags, 0)
+	require.Error(t, check(m))
+
+	m = &MerkleBlock{Header: &block.Header{}, Hashes: []common.Hash{{}}}
+	require.Error(t, check(m))
+	m.Hashes = nil
+	require.NoError(t, check(m))
+}
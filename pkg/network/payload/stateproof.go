@@ -0,0 +1,67 @@
+package payload
+
+import (
+	"errors"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxStateProofNodes is the maximum number of MPT nodes in the state proof,
+// it's enough for a path of the longest key.
+const MaxStateProofNodes = 2*mpt.MaxKeyLength*2 + 1
+
+// GetStateProof is the request for the proof of the key in the MPT with the
+// specified root.
+type GetStateProof struct {
+	Root common.Hash
+	Key  []byte
+}
+
+// StateProof is the response to GetStateProof. Proof is the list of serialized
+// MPT nodes on the path to the key, for the missing key it ends with the node
+// proving its absence. Proof is empty if the node doesn't have the requested
+// state.
+type StateProof struct {
+	Root  common.Hash
+	Key   []byte
+	Proof [][]byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (g *GetStateProof) DecodeBinary(br *io.BinReader) {
+	br.ReadBytes(g.Root[:])
+	g.Key = br.ReadVarBytes(mpt.MaxKeyLength)
+}
+
+// EncodeBinary implements Serializable interface.
+func (g *GetStateProof) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteBytes(g.Root[:])
+	bw.WriteVarBytes(g.Key)
+}
+
+// DecodeBinary implements Serializable interface.
+func (p *StateProof) DecodeBinary(br *io.BinReader) {
+	br.ReadBytes(p.Root[:])
+	p.Key = br.ReadVarBytes(mpt.MaxKeyLength)
+	n := br.ReadVarUint()
+	if br.Err == nil && n > MaxStateProofNodes {
+		br.Err = errors.New("too many proof nodes")
+		return
+	}
+	p.Proof = nil
+	for i := uint64(0); i < n && br.Err == nil; i++ {
+		p.Proof = append(p.Proof, br.ReadVarBytes(mpt.MaxValueLength+mpt.MaxKeyLength*2))
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (p *StateProof) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteBytes(p.Root[:])
+	bw.WriteVarBytes(p.Key)
+	bw.WriteVarUint(uint64(len(p.Proof)))
+	for _, n := range p.Proof {
+		bw.WriteVarBytes(n)
+	}
+}
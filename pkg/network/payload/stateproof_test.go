@@ -0,0 +1,30 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateProof(t *testing.T) {
+	req := &GetStateProof{Root: common.HexToHash("0x01"), Key: []byte{1, 2, 3}}
+	data, err := io.ToByteArray(req)
+	require.NoError(t, err)
+	actualReq := new(GetStateProof)
+	require.NoError(t, io.FromByteArray(actualReq, data))
+	require.Equal(t, req, actualReq)
+
+	resp := &StateProof{Root: req.Root, Key: req.Key, Proof: [][]byte{{1}, {2, 3}}}
+	data, err = io.ToByteArray(resp)
+	require.NoError(t, err)
+	actualResp := new(StateProof)
+	require.NoError(t, io.FromByteArray(actualResp, data))
+	require.Equal(t, resp, actualResp)
+
+	resp.Proof = make([][]byte, MaxStateProofNodes+1)
+	data, err = io.ToByteArray(resp)
+	require.NoError(t, err)
+	require.Error(t, io.FromByteArray(new(StateProof), data))
+}
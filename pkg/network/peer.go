@@ -2,10 +2,35 @@ package network
 
 import (
 	"net"
+	"time"
 
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
 )
 
+// PeerStats is the peer connection statistics.
+type PeerStats struct {
+	// Latency is the last measured ping/pong round-trip time.
+	Latency time.Duration
+	// SendQueue, P2PQueue and HPQueue are the numbers of messages waiting
+	// in the corresponding send queues.
+	SendQueue int
+	P2PQueue  int
+	HPQueue   int
+}
+
+// PeerInfo is the detailed information about connected peer.
+type PeerInfo struct {
+	Address      string
+	PeerAddress  string
+	ID           uint32
+	UserAgent    string
+	Capabilities capability.Capabilities
+	Height       uint32
+	Handshaked   bool
+	Stats        PeerStats
+}
+
 type Peer interface {
 	// RemoteAddr returns the remote address that we're connected to now.
 	RemoteAddr() net.Addr
@@ -51,6 +76,8 @@ type Peer interface {
 	LastBlockIndex() uint32
 	Handshaked() bool
 	IsFullNode() bool
+	// Stats returns the peer connection statistics.
+	Stats() PeerStats
 
 	// SendPing enqueues a ping message to be sent to the peer and does
 	// appropriate protocol handling like timeouts and outstanding pings
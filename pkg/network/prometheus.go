@@ -38,6 +38,111 @@ var (
 			Namespace: "neo_go_evm",
 		},
 	)
+
+	blockSyncSpeed = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Overall block download speed in blocks per second",
+			Name:      "block_sync_speed",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	blockFetchRanges = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of block ranges requested from peers",
+			Name:      "block_fetch_ranges",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	blockFetchTimeouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of block ranges not delivered in time",
+			Name:      "block_fetch_timeouts",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	compactBlocksRestored = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of blocks restored from compact blocks",
+			Name:      "compact_blocks_restored",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	compactMissingTxs = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of compact block transactions requested from peers",
+			Name:      "compact_missing_txs",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	compactFallbacks = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of compact blocks that couldn't be restored",
+			Name:      "compact_fallbacks",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	txReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of transactions received from peers",
+			Name:      "tx_received",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	txDuplicates = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of received transactions that were already known",
+			Name:      "tx_duplicates",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	txPushed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of transactions pushed to peers",
+			Name:      "tx_pushed",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	txAnnounced = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of transaction hashes announced to peers",
+			Name:      "tx_announced",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	lightProofsFetched = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of state proofs fetched from peers by the light node",
+			Name:      "light_proofs_fetched",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	lightProofFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of state proofs the light node failed to get from peers",
+			Name:      "light_proof_failures",
+			Namespace: "neo_go_evm",
+		},
+	)
+
+	droppedMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of incoming messages dropped because of peer rate limits",
+			Name:      "dropped_messages",
+			Namespace: "neo_go_evm",
+		},
+		[]string{"command", "reason"},
+	)
 )
 
 func init() {
@@ -46,6 +151,19 @@ func init() {
 		servAndNodeVersion,
 		poolCount,
 		blockQueueLength,
+		blockSyncSpeed,
+		blockFetchRanges,
+		blockFetchTimeouts,
+		compactBlocksRestored,
+		compactMissingTxs,
+		compactFallbacks,
+		txReceived,
+		txDuplicates,
+		txPushed,
+		txAnnounced,
+		lightProofsFetched,
+		lightProofFailures,
+		droppedMessages,
 	)
 }
 
@@ -53,6 +171,14 @@ func updateBlockQueueLenMetric(bqLen int) {
 	blockQueueLength.Set(float64(bqLen))
 }
 
+func updateBlockSyncSpeedMetric(speed float64) {
+	blockSyncSpeed.Set(speed)
+}
+
+func updateBlockFetchRangesMetric(n int) {
+	blockFetchRanges.Set(float64(n))
+}
+
 func updatePoolCountMetric(pCount int) {
 	poolCount.Set(float64(pCount))
 }
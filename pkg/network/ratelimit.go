@@ -0,0 +1,194 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+)
+
+// Reasons for incoming messages to be dropped.
+const (
+	dropRate     = "rate"
+	dropBytes    = "bytes"
+	dropInFlight = "inflight"
+)
+
+// errRateLimited is returned for messages exceeding peer limits.
+var errRateLimited = errors.New("rate limit exceeded")
+
+const (
+	// dropWindow is the period repeatedly dropped messages are counted for.
+	dropWindow = time.Minute
+	// maxDrops is the number of messages dropped for rate or in-flight
+	// limits within dropWindow after which the peer is penalized.
+	maxDrops = 100
+)
+
+// defaultRateLimits are the limits of commands that can be used to make the
+// node do a lot of work or send a lot of data, other commands are not limited
+// unless configured. They allow light nodes to request lightRootsWindow state
+// roots on every ping and root validated.
+var defaultRateLimits = map[CommandType]config.CommandRateLimit{
+	CMDGetAddr:         {Rate: 0.1, Burst: 3},
+	CMDPing:            {Rate: 1, Burst: 10},
+	CMDGetHeaders:      {Rate: 10, Burst: 50},
+	CMDGetBlocks:       {Rate: 10, Burst: 50},
+	CMDMempool:         {Rate: 0.1, Burst: 3},
+	CMDInv:             {Rate: 200, Burst: 1000},
+	CMDGetData:         {Rate: 20, Burst: 100},
+	CMDGetBlockByIndex: {Rate: 10, Burst: 50},
+	CMDTX:              {Rate: 1000, Burst: 5000},
+	CMDExtensible:      {Rate: 100, Burst: 500},
+	CMDGetMPTData:      {Rate: 20, Burst: 100},
+	CMDGetStateRoot:    {Rate: 200, Burst: 10 * lightRootsWindow},
+	CMDGetBlockTxn:     {Rate: 20, Burst: 100},
+	CMDGetStateProof:   {Rate: 20, Burst: 100},
+	CMDFilterLoad:      {Rate: 1, Burst: 5},
+	CMDFilterAdd:       {Rate: 10, Burst: 100},
+}
+
+// tokenBucket allows burst events at once refilling at the given rate.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// take takes n tokens from the bucket if there are enough of them. Requests
+// exceeding the burst are allowed with a full bucket leaving it in debt.
+func (b *tokenBucket) take(now time.Time, n float64) bool {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens < math.Min(n, b.burst) {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// rateLimiter limits messages received from a single peer. It's not thread
+// safe and should only be used from the peer's incoming messages handler.
+type rateLimiter struct {
+	limits      map[CommandType]config.CommandRateLimit
+	commands    map[CommandType]*tokenBucket
+	bytes       *tokenBucket
+	maxInFlight int
+
+	// drops is the number of messages dropped since dropsSince.
+	drops      int
+	dropsSince time.Time
+}
+
+// newRateLimiter returns the limiter for the given configuration or nil if
+// rate limiting is disabled.
+func newRateLimiter(cfg config.RateLimit) *rateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	l := &rateLimiter{
+		limits:      make(map[CommandType]config.CommandRateLimit, len(defaultRateLimits)),
+		commands:    make(map[CommandType]*tokenBucket),
+		maxInFlight: cfg.MaxInFlight,
+	}
+	for cmd, lim := range defaultRateLimits {
+		l.limits[cmd] = lim
+	}
+	for name, c := range cfg.Commands {
+		if cmd, ok := commandByName(name); ok {
+			l.limits[cmd] = c
+		}
+	}
+	if cfg.BytesPerSecond > 0 {
+		l.bytes = newTokenBucket(float64(cfg.BytesPerSecond), cfg.ByteBurst, time.Now())
+	}
+	return l
+}
+
+// commandByName returns the command with the given name like "CMDGetData".
+func commandByName(name string) (CommandType, bool) {
+	for i := 0; i <= math.MaxUint8; i++ {
+		cmd := CommandType(i)
+		if s := cmd.String(); s == name && !strings.HasPrefix(s, "CommandType(") {
+			return cmd, true
+		}
+	}
+	return 0, false
+}
+
+// validateRateLimit checks that the configured limits are for known commands.
+func validateRateLimit(cfg config.RateLimit) error {
+	for name := range cfg.Commands {
+		if _, ok := commandByName(name); !ok {
+			return fmt.Errorf("unknown command %q in rate limits", name)
+		}
+	}
+	return nil
+}
+
+// isRequest checks whether the command makes the node send data back.
+func isRequest(cmd CommandType) bool {
+	switch cmd {
+	case CMDGetAddr, CMDGetHeaders, CMDGetBlocks, CMDMempool, CMDGetData,
+		CMDGetBlockByIndex, CMDGetMPTData, CMDGetStateRoot, CMDGetBlockTxn,
+		CMDGetStateProof:
+		return true
+	default:
+		return false
+	}
+}
+
+// allow checks whether the message of the given size can be processed with
+// inFlight other requests received from the peer and not handled yet. It returns the reason the message
+// should be dropped for or an empty string.
+func (l *rateLimiter) allow(cmd CommandType, size int, inFlight int) string {
+	now := time.Now()
+	if l.bytes != nil && !l.bytes.take(now, float64(size)) {
+		return dropBytes
+	}
+	if lim, ok := l.limits[cmd]; ok && lim.Rate > 0 {
+		b, ok := l.commands[cmd]
+		if !ok {
+			b = newTokenBucket(lim.Rate, lim.Burst, now)
+			l.commands[cmd] = b
+		}
+		if !b.take(now, 1) {
+			return dropRate
+		}
+	}
+	if l.maxInFlight > 0 && inFlight >= l.maxInFlight && isRequest(cmd) {
+		return dropInFlight
+	}
+	return ""
+}
+
+// dropped records the message dropped for rate or in-flight limits and
+// returns true every maxDrops drops within dropWindow, so that peers
+// persistently exceeding limits are penalized.
+func (l *rateLimiter) dropped(now time.Time) bool {
+	if now.Sub(l.dropsSince) > dropWindow {
+		l.drops = 0
+		l.dropsSince = now
+	}
+	l.drops++
+	if l.drops < maxDrops {
+		return false
+	}
+	l.drops = 0
+	return true
+}
@@ -0,0 +1,224 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/statesync"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 3, now)
+	for i := 0; i < 3; i++ {
+		require.True(t, b.take(now, 1))
+	}
+	require.False(t, b.take(now, 1))
+	require.True(t, b.take(now.Add(time.Second/2), 1))
+	require.False(t, b.take(now.Add(time.Second/2), 1))
+
+	// Refill doesn't exceed the burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.True(t, b.take(now, 1))
+	}
+	require.False(t, b.take(now, 1))
+
+	// Requests exceeding the burst are allowed with a full bucket only.
+	now = now.Add(time.Hour)
+	require.True(t, b.take(now, 10))
+	require.False(t, b.take(now.Add(3*time.Second), 1))
+	require.True(t, b.take(now.Add(4*time.Second), 1))
+}
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, newRateLimiter(config.RateLimit{}))
+
+	l := newRateLimiter(config.RateLimit{
+		Enabled: true,
+		Commands: map[string]config.CommandRateLimit{
+			CMDGetData.String(): {Rate: 0.001, Burst: 2},
+			CMDBlock.String():   {Rate: 0.001, Burst: 1},
+			CMDMempool.String(): {},
+		},
+		BytesPerSecond: 1,
+		ByteBurst:      100,
+		MaxInFlight:    4,
+	})
+	require.Equal(t, "", l.allow(CMDGetData, 10, 0))
+	require.Equal(t, dropInFlight, l.allow(CMDGetData, 10, 4))
+	require.Equal(t, dropRate, l.allow(CMDGetData, 10, 0))
+	require.Equal(t, "", l.allow(CMDBlock, 10, 4))
+	require.Equal(t, dropRate, l.allow(CMDBlock, 10, 0))
+	for i := 0; i < 10; i++ {
+		require.Equal(t, "", l.allow(CMDMempool, 0, 0))
+	}
+	require.Equal(t, defaultRateLimits[CMDGetHeaders], l.limits[CMDGetHeaders])
+	// Light nodes request a window of state roots at once.
+	for i := 0; i < 2*lightRootsWindow; i++ {
+		require.Equal(t, "", l.allow(CMDGetStateRoot, 0, 0))
+	}
+	require.Equal(t, dropBytes, l.allow(CMDPong, 100, 0))
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	require.NoError(t, validateRateLimit(config.RateLimit{
+		Commands: map[string]config.CommandRateLimit{CMDGetData.String(): {}},
+	}))
+	for _, name := range []string{"GetData", "CMDUnknown", CommandType(0xff).String()} {
+		cfg := ServerConfig{RateLimit: config.RateLimit{
+			Enabled:  true,
+			Commands: map[string]config.CommandRateLimit{name: {Rate: 1, Burst: 1}},
+		}}
+		l := &testLedger{}
+		_, err := newServerFromConstructors(cfg, l, zap.NewNop(), newFakeTransp, newDefaultDiscovery)
+		require.Error(t, err, name)
+	}
+}
+
+func TestTCPPeerRateLimit(t *testing.T) {
+	s := newTestServer(t, ServerConfig{RateLimit: config.RateLimit{
+		Enabled: true,
+		Commands: map[string]config.CommandRateLimit{
+			CMDGetData.String(): {Rate: 0.001, Burst: 3},
+		},
+		MaxInFlight: 4,
+	}})
+	getData := NewMessage(CMDGetData, payload.NewInventory(payload.TXType, []common.Hash{{1}}))
+	newPeer := func() *TCPPeer {
+		conn, _ := net.Pipe()
+		p := NewTCPPeer(conn, s)
+		p.handShake = versionSent | versionReceived | verAckSent | verAckReceived
+		return p
+	}
+	// handle processes the messages by the peer and waits for it to finish.
+	handle := func(p *TCPPeer, msgs ...*Message) {
+		go p.handleIncoming()
+		for _, msg := range msgs {
+			if isRequest(msg.Command) {
+				p.requests.Inc()
+			}
+			p.incoming <- msg
+		}
+		close(p.incoming)
+		drop := <-s.unregister
+		require.Equal(t, p, drop.peer)
+		require.NoError(t, drop.reason)
+	}
+
+	// Broadcasted messages are not responses.
+	p := newPeer()
+	for i := 0; i < 4; i++ {
+		p.sendQ <- []byte{}
+	}
+	handle(p, getData)
+	require.Equal(t, 1, len(p.p2pSendQ))
+
+	// Requests are dropped if too many of them are not handled yet.
+	p = newPeer()
+	p.requests.Store(4)
+	handle(p, getData)
+	require.Equal(t, 0, len(p.p2pSendQ))
+	require.Equal(t, int32(4), p.requests.Load())
+
+	// Peer exceeding request rate is not penalized.
+	p = newPeer()
+	handle(p, getData, getData, getData, getData, getData)
+	require.Equal(t, 3, len(p.p2pSendQ))
+	require.Equal(t, int32(0), p.requests.Load())
+	s.addrBook.lock.RLock()
+	require.Empty(t, s.addrBook.hosts)
+	s.addrBook.lock.RUnlock()
+}
+
+func TestRateLimiterDropped(t *testing.T) {
+	l := newRateLimiter(config.RateLimit{Enabled: true})
+	now := time.Now()
+	for i := 0; i < maxDrops-1; i++ {
+		require.False(t, l.dropped(now))
+	}
+	require.True(t, l.dropped(now))
+	require.False(t, l.dropped(now))
+
+	// Drops are counted within the window only.
+	for i := 0; i < maxDrops-2; i++ {
+		require.False(t, l.dropped(now))
+	}
+	require.False(t, l.dropped(now.Add(dropWindow+time.Second)))
+}
+
+func TestTCPPeerFloodPenalty(t *testing.T) {
+	s := newTestServer(t, ServerConfig{RateLimit: config.RateLimit{
+		Enabled: true,
+		Commands: map[string]config.CommandRateLimit{
+			CMDGetAddr.String(): {Rate: 0.001, Burst: 1},
+		},
+	}})
+	conn, _ := net.Pipe()
+	p := NewTCPPeer(conn, s)
+	p.handShake = versionSent | versionReceived | verAckSent | verAckReceived
+	go p.handleIncoming()
+	for i := 0; i < 1+maxDrops; i++ {
+		p.requests.Inc()
+		p.incoming <- NewMessage(CMDGetAddr, payload.NewNullPayload())
+	}
+	close(p.incoming)
+	drop := <-s.unregister
+	require.NoError(t, drop.reason)
+
+	s.addrBook.lock.RLock()
+	defer s.addrBook.lock.RUnlock()
+	require.Equal(t, 1, len(s.addrBook.hosts))
+	for _, h := range s.addrBook.hosts {
+		require.Equal(t, scoreRateLimited, h.score)
+	}
+}
+
+func TestTCPPeerBlockRanges(t *testing.T) {
+	const ranges, rangeSize = 5, 100
+
+	l := &blocksLedger{testLedger: &testLedger{pool: mempool.New(100, 0, false)}}
+	l.sync = statesync.NewModule(l, nil, zap.NewNop(), nil, nil)
+	for i := 0; i < ranges*rangeSize; i++ {
+		l.blocks = append(l.blocks, &block.Block{Header: block.Header{Index: uint32(i)}})
+	}
+	l.height = uint32(len(l.blocks) - 1)
+	s, err := newServerFromConstructors(ServerConfig{RateLimit: config.RateLimit{
+		Enabled:     true,
+		MaxInFlight: 16,
+	}}, l, zap.NewNop(), newFakeTransp, newDefaultDiscovery)
+	require.NoError(t, err)
+
+	conn, _ := net.Pipe()
+	p := NewTCPPeer(conn, s)
+	p.handShake = versionSent | versionReceived | verAckSent | verAckReceived
+	received := make(chan struct{})
+	go func() {
+		for i := 0; i < ranges*rangeSize; i++ {
+			<-p.p2pSendQ
+		}
+		close(received)
+	}()
+	go p.handleIncoming()
+	// Ranges are requested one by one as blocks are received.
+	for i := 0; i < ranges; i++ {
+		p.requests.Inc()
+		p.incoming <- NewMessage(CMDGetBlockByIndex, payload.NewGetBlockByIndex(uint32(i*rangeSize), rangeSize))
+	}
+	close(p.incoming)
+	drop := <-s.unregister
+	require.NoError(t, drop.reason)
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("not all blocks were sent")
+	}
+}
@@ -1,8 +1,11 @@
 package network
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	mrand "math/rand"
@@ -14,8 +17,13 @@ import (
 
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/blockchainer"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/statesync"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/extpool"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
@@ -41,10 +49,29 @@ var (
 	errInvalidNetwork   = errors.New("invalid network")
 	errMaxPeers         = errors.New("max peers reached")
 	errServerShutdown   = errors.New("server shutdown")
+	errRemoved          = errors.New("removed by operator")
 	errInvalidInvType   = errors.New("invalid inventory type")
+	errNoFilter         = errors.New("no filter loaded")
 )
 
 type (
+	// NodeInfo is the information about the node.
+	NodeInfo struct {
+		ID              uint32
+		UserAgent       string
+		Address         string
+		Port            uint16
+		ChainID         uint64
+		Capabilities    capability.Capabilities
+		BlockHeight     uint32
+		HeaderHeight    uint32
+		Peers           int
+		HandshakedPeers int
+		// NodeKey is the hex-encoded node public key if P2P encryption is
+		// enabled.
+		NodeKey string
+	}
+
 	// Ledger is everything Server needs from the blockchain.
 	Ledger interface {
 		extpool.Ledger
@@ -55,6 +82,8 @@ type (
 		GetHeader(hash common.Hash) (*block.Header, error)
 		GetHeaderHash(int) common.Hash
 		GetMemPool() *mempool.Pool
+		GetStateModule() blockchainer.StateRoot
+		GetStateSyncModule() *statesync.Module
 		GetTransaction(common.Hash) (*transaction.Transaction, *types.Receipt, error)
 		HasBlock(common.Hash) bool
 		HeaderHeight() uint32
@@ -87,8 +116,17 @@ type (
 		discovery      Discoverer
 		chain          Ledger
 		bQueue         *blockQueue
+		fetcher        *blockFetcher
+		compact        *compactRelay
+		txRelay        *txRelay
+		light          *lightClient
 		mempool        *mempool.Pool
 		extensiblePool *extpool.Pool
+		stateSync      *statesync.Module
+		addrBook       *AddressBook
+		// tlsConfig is set if P2P encryption is enabled.
+		tlsConfig      *tls.Config
+		nodeKey        ed25519.PublicKey
 		services       []Service
 		extensHandlers map[string]func(*payload.Extensible) error
 		extensHighPrio string
@@ -100,8 +138,10 @@ type (
 		lock  sync.RWMutex
 		peers map[Peer]bool
 
-		// lastRequestedBlock contains a height of the last requested block.
-		lastRequestedBlock atomic.Uint32
+		filterLock sync.RWMutex
+		// filters are the bloom filters loaded by light nodes.
+		filters map[Peer]*payload.FilterLoad
+
 		// lastRequestedHeader contains a height of the last requested header.
 		lastRequestedHeader atomic.Uint32
 
@@ -130,7 +170,11 @@ func randomID() uint32 {
 // NewServer returns a new Server, initialized with the given configuration.
 func NewServer(config ServerConfig, chain Ledger, log *zap.Logger) (*Server, error) {
 	return newServerFromConstructors(config, chain, log, func(s *Server) Transporter {
-		return NewTCPTransport(s, net.JoinHostPort(s.ServerConfig.Address, strconv.Itoa(int(s.ServerConfig.Port))), s.log)
+		addr := net.JoinHostPort(s.ServerConfig.Address, strconv.Itoa(int(s.ServerConfig.Port)))
+		if s.tlsConfig != nil {
+			return NewTLSTransport(s, addr, s.tlsConfig, s.log)
+		}
+		return NewTCPTransport(s, addr, s.log)
 	}, newDefaultDiscovery)
 }
 
@@ -141,6 +185,9 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, log *zap.Logge
 	if log == nil {
 		return nil, errors.New("logger is a required parameter")
 	}
+	if err := validateRateLimit(config.RateLimit); err != nil {
+		return nil, err
+	}
 
 	if config.ExtensiblePoolSize <= 0 {
 		config.ExtensiblePoolSize = defaultExtensiblePoolSize
@@ -158,9 +205,14 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, log *zap.Logge
 		unregister:     make(chan peerDrop),
 		txInMap:        make(map[common.Hash]struct{}),
 		peers:          make(map[Peer]bool),
+		filters:        make(map[Peer]*payload.FilterLoad),
+		compact:        newCompactRelay(),
+		txRelay:        newTxRelay(),
 		syncReached:    atomic.NewBool(false),
 		mempool:        chain.GetMemPool(),
+		stateSync:      chain.GetStateSyncModule(),
 		extensiblePool: extpool.New(chain, config.ExtensiblePoolSize),
+		addrBook:       NewAddressBook(config.AddressBook),
 		log:            log,
 		transactions:   make(chan *transaction.Transaction, 64),
 		extensHandlers: make(map[string]func(*payload.Extensible) error),
@@ -168,6 +220,7 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, log *zap.Logge
 	s.bQueue = newBlockQueue(maxBlockBatch, chain, log, func(b *block.Block) {
 		s.tryStartServices()
 	})
+	s.fetcher = newBlockFetcher(blockRangeTimeout)
 	if s.MinPeers < 0 {
 		s.log.Info("bad MinPeers configured, using the default value",
 			zap.Int("configured", s.MinPeers),
@@ -189,6 +242,30 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, log *zap.Logge
 		s.AttemptConnPeers = defaultAttemptConnPeers
 	}
 
+	if s.P2PEncryption.Enabled {
+		var err error
+		s.tlsConfig, s.nodeKey, err = newTLSConfig(s.P2PEncryption)
+		if err != nil {
+			return nil, fmt.Errorf("P2P encryption: %w", err)
+		}
+		s.log.Info("P2P encryption enabled",
+			zap.String("nodeKey", hex.EncodeToString(s.nodeKey)),
+			zap.Int("allowedKeys", len(s.P2PEncryption.AllowedKeys)))
+	}
+
+	if s.LightNode.Enabled {
+		var err error
+		s.light, err = newLightClient(s)
+		if err != nil {
+			return nil, fmt.Errorf("light node: %w", err)
+		}
+		s.log.Info("light node mode enabled, only headers are synchronized")
+	}
+
+	if err := s.addrBook.Load(); err != nil {
+		s.log.Warn("failed to load address book", zap.Error(err))
+	}
+
 	s.transport = newTransport(s)
 	s.discovery = newDiscovery(
 		s.Seeds,
@@ -212,9 +289,13 @@ func (s *Server) Start(errChan chan error) {
 
 	s.tryStartServices()
 	s.initStaleMemPools()
+	s.discovery.BackFill(s.addrBook.Addresses()...)
 
 	go s.broadcastTxLoop()
 	go s.relayBlocksLoop()
+	if s.light != nil {
+		go s.light.run()
+	}
 	go s.bQueue.run()
 	go s.transport.Accept()
 	setServerAndNodeVersions(s.UserAgent, strconv.FormatUint(uint64(s.id), 10))
@@ -233,6 +314,9 @@ func (s *Server) Shutdown() {
 	for _, svc := range s.services {
 		svc.Shutdown()
 	}
+	if err := s.addrBook.Save(); err != nil {
+		s.log.Warn("failed to save address book", zap.Error(err))
+	}
 	close(s.quit)
 }
 
@@ -265,6 +349,123 @@ func (s *Server) BadPeers() []string {
 	return s.discovery.BadPeers()
 }
 
+// AddressBook returns address book entries of all known peers.
+func (s *Server) AddressBook() []AddressInfo {
+	return s.addrBook.List()
+}
+
+// BanPeer bans the host of the given address for the specified duration
+// (the default one is used if it's zero) and disconnects peers connected
+// from it.
+func (s *Server) BanPeer(addr string, d time.Duration) {
+	s.addrBook.Ban(addr, d)
+	host := hostOf(addr)
+	for _, p := range s.getPeers(func(p Peer) bool { return hostOf(p.RemoteAddr().String()) == host }) {
+		p.Disconnect(errBanned)
+	}
+	if err := s.addrBook.Save(); err != nil {
+		s.log.Warn("failed to save address book", zap.Error(err))
+	}
+}
+
+// UnbanPeer removes the ban of the host of the given address. It returns
+// false if it wasn't banned.
+func (s *Server) UnbanPeer(addr string) bool {
+	ok := s.addrBook.Unban(addr)
+	if err := s.addrBook.Save(); err != nil {
+		s.log.Warn("failed to save address book", zap.Error(err))
+	}
+	return ok
+}
+
+// AddPeer connects to the peer with the given address. It returns an error if
+// the address is banned, the peer is already connected or it can't be dialed.
+func (s *Server) AddPeer(addr string) error {
+	if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
+		return err
+	}
+	if s.addrBook.IsBanned(addr) {
+		return errBanned
+	}
+	if len(s.getPeers(func(p Peer) bool { return p.PeerAddr().String() == addr })) != 0 {
+		return errAlreadyConnected
+	}
+	return s.transport.Dial(addr, s.DialTimeout)
+}
+
+// RemovePeer disconnects peers connected to or from the given address, the
+// node won't try to reconnect to them automatically. It returns false if
+// there are no such peers.
+func (s *Server) RemovePeer(addr string) bool {
+	peers := s.getPeers(func(p Peer) bool {
+		return p.PeerAddr().String() == addr || p.RemoteAddr().String() == addr
+	})
+	for _, p := range peers {
+		p.Disconnect(errRemoved)
+	}
+	return len(peers) != 0
+}
+
+// penalize adds misbehaviour score for the given error to the peer host,
+// peer is disconnected if the host gets banned.
+func (s *Server) penalize(p Peer, err error) {
+	score := misbehaviourScore(err)
+	if score == 0 {
+		return
+	}
+	if s.addrBook.AddScore(p.RemoteAddr().String(), score) {
+		s.log.Warn("banning misbehaving peer",
+			zap.Stringer("addr", p.RemoteAddr()),
+			zap.Error(err))
+		p.Disconnect(errBanned)
+	}
+}
+
+// misbehaviourScore returns misbehaviour score for the error peer connection
+// failed with.
+func misbehaviourScore(err error) int {
+	switch {
+	case errors.Is(err, errInvalidBlock):
+		return scoreInvalidBlock
+	case errors.Is(err, errInvalidMessage):
+		return scoreInvalidMessage
+	case errors.Is(err, errUnrequested):
+		return scoreUnrequested
+	case errors.Is(err, errRateLimited):
+		return scoreRateLimited
+	case errors.Is(err, errInvalidInvType), errors.Is(err, errUnexpectedPong),
+		errors.Is(err, errNoFilter):
+		return scoreProtocolViolation
+	default:
+		// Other errors can be caused by the node itself or by the network,
+		// so they're not the peer fault.
+		return 0
+	}
+}
+
+// PeersInfo returns detailed information about currently connected peers.
+func (s *Server) PeersInfo() []PeerInfo {
+	peers := s.getPeers(nil)
+	res := make([]PeerInfo, 0, len(peers))
+	for _, p := range peers {
+		info := PeerInfo{
+			Address:     p.RemoteAddr().String(),
+			PeerAddress: p.PeerAddr().String(),
+			Handshaked:  p.Handshaked(),
+			Height:      p.LastBlockIndex(),
+			Stats:       p.Stats(),
+		}
+		if ver := p.Version(); ver != nil {
+			info.UserAgent = string(ver.UserAgent)
+			info.ID = ver.Nonce
+			info.Capabilities = ver.Capabilities
+		}
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Address < res[j].Address })
+	return res
+}
+
 // ConnectedPeers returns a list of currently connected peers.
 func (s *Server) ConnectedPeers() []string {
 	s.lock.RLock()
@@ -298,7 +499,10 @@ func (s *Server) run() {
 			s.lock.Unlock()
 			peerCount := s.PeerCount()
 			s.log.Info("new peer connected", zap.Stringer("addr", p.RemoteAddr()), zap.Int("peerCount", peerCount))
-			if peerCount > s.MaxPeers {
+			if s.addrBook.IsBanned(p.RemoteAddr().String()) {
+				// It will send us unregister signal.
+				go p.Disconnect(errBanned)
+			} else if peerCount > s.MaxPeers {
 				s.lock.RLock()
 				// Pick a random peer and drop connection to it.
 				for peer := range s.peers {
@@ -315,6 +519,9 @@ func (s *Server) run() {
 			if s.peers[drop.peer] {
 				delete(s.peers, drop.peer)
 				s.lock.Unlock()
+				s.fetcher.removePeer(drop.peer)
+				s.setFilter(drop.peer, nil)
+				s.requestTxs(s.txRelay.removePeer(drop.peer))
 				s.log.Warn("peer disconnected",
 					zap.Stringer("addr", drop.peer.RemoteAddr()),
 					zap.Error(drop.reason),
@@ -343,6 +550,9 @@ func (s *Server) run() {
 						s.discovery.UnregisterConnectedAddr(addr)
 						s.discovery.BackFill(addr)
 					}
+				} else if errors.Is(drop.reason, errRemoved) || errors.Is(drop.reason, errBanned) {
+					// Don't try to reconnect to it.
+					s.discovery.UnregisterConnectedAddr(addr)
 				} else {
 					s.discovery.UnregisterConnectedAddr(addr)
 					s.discovery.BackFill(addr)
@@ -360,11 +570,21 @@ func (s *Server) run() {
 // runProto is a goroutine that manages server-wide protocol events.
 func (s *Server) runProto() {
 	pingTimer := time.NewTimer(s.PingInterval)
+	saveTicker := time.NewTicker(addrBookSaveInterval)
+	defer saveTicker.Stop()
+	txRetryTicker := time.NewTicker(txRetryInterval)
+	defer txRetryTicker.Stop()
 	for {
 		prevHeight := s.chain.BlockHeight()
 		select {
 		case <-s.quit:
 			return
+		case <-saveTicker.C:
+			if err := s.addrBook.Save(); err != nil {
+				s.log.Warn("failed to save address book", zap.Error(err))
+			}
+		case <-txRetryTicker.C:
+			s.requestTxs(s.txRelay.expired())
 		case <-pingTimer.C:
 			if s.chain.BlockHeight() == prevHeight {
 				// Get a copy of s.peers to avoid holding a lock while sending.
@@ -438,7 +658,18 @@ func (s *Server) getVersionMsg() (*Message, error) {
 		return nil, err
 	}
 
-	capabilities := []capability.Capability{
+	payload := payload.NewVersion(
+		s.ChainID,
+		s.id,
+		s.UserAgent,
+		s.capabilities(port),
+	)
+	return NewMessage(CMDVersion, payload), nil
+}
+
+// capabilities returns node capabilities announced in the version message.
+func (s *Server) capabilities(port uint16) capability.Capabilities {
+	capabilities := capability.Capabilities{
 		{
 			Type: capability.TCPServer,
 			Data: &capability.Server{
@@ -446,7 +677,7 @@ func (s *Server) getVersionMsg() (*Message, error) {
 			},
 		},
 	}
-	if s.Relay {
+	if s.Relay && s.light == nil {
 		capabilities = append(capabilities, capability.Capability{
 			Type: capability.FullNode,
 			Data: &capability.Node{
@@ -454,13 +685,39 @@ func (s *Server) getVersionMsg() (*Message, error) {
 			},
 		})
 	}
-	payload := payload.NewVersion(
-		s.ChainID,
-		s.id,
-		s.UserAgent,
-		capabilities,
-	)
-	return NewMessage(CMDVersion, payload), nil
+	if s.CompactBlocks {
+		capabilities = append(capabilities, capability.Capability{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{
+				Version: compactBlockVersion,
+			},
+		})
+	}
+	return capabilities
+}
+
+// NodeInfo returns information about the node.
+func (s *Server) NodeInfo() (NodeInfo, error) {
+	port, err := s.Port()
+	if err != nil {
+		return NodeInfo{}, err
+	}
+	info := NodeInfo{
+		ID:              s.id,
+		UserAgent:       s.UserAgent,
+		Address:         s.transport.Address(),
+		Port:            port,
+		ChainID:         s.ChainID,
+		Capabilities:    s.capabilities(port),
+		BlockHeight:     s.chain.BlockHeight(),
+		HeaderHeight:    s.chain.HeaderHeight(),
+		Peers:           s.PeerCount(),
+		HandshakedPeers: s.HandshakedPeersCount(),
+	}
+	if s.nodeKey != nil {
+		info.NodeKey = hex.EncodeToString(s.nodeKey)
+	}
+	return info, nil
 }
 
 // IsInSync answers the question of whether the server is in sync with the
@@ -531,7 +788,41 @@ func (s *Server) handleVersionCmd(p Peer, version *payload.Version) error {
 
 // handleBlockCmd processes the received block received from its peer.
 func (s *Server) handleBlockCmd(p Peer, block *block.Block) error {
-	return s.bQueue.putBlock(block)
+	if s.stateSync.IsActive() || s.light != nil {
+		// Blocks are processed after the state jump only, light node
+		// doesn't process them at all.
+		return nil
+	}
+	if h := s.chain.GetHeaderHash(int(block.Index)); h != (common.Hash{}) && h != block.Hash() {
+		return fmt.Errorf("%w: block %d doesn't match the header", errInvalidBlock, block.Index)
+	}
+	if block.MerkleRoot != block.ComputeMerkleRoot() {
+		return fmt.Errorf("%w: block %d merkle root mismatch", errInvalidBlock, block.Index)
+	}
+	if block.Index > s.chain.BlockHeight()+blockCacheSize {
+		s.penalize(p, fmt.Errorf("%w: block %d", errUnrequested, block.Index))
+		return nil
+	}
+	if s.CompactBlocks && block.Index == s.chain.BlockHeight()+1 {
+		// Transactions missing in the mempool are prefilled when the block
+		// is relayed, it's done before the block is added and these are
+		// removed from the mempool.
+		var unknown []common.Hash
+		for _, tx := range block.Transactions {
+			if !s.mempool.ContainsKey(tx.Hash()) {
+				unknown = append(unknown, tx.Hash())
+			}
+		}
+		s.compact.setPrefill(block.Hash(), unknown)
+	}
+	err := s.bQueue.putBlock(block)
+	if err != nil {
+		return err
+	}
+	if s.fetcher.delivered(p, block.Index) && s.chain.BlockHeight() < p.LastBlockIndex() {
+		return s.requestBlocks(s.chain, p)
+	}
+	return nil
 }
 
 // handlePing processes ping request.
@@ -548,6 +839,31 @@ func (s *Server) handlePing(p Peer, ping *payload.Ping) error {
 }
 
 func (s *Server) requestBlocksOrHeaders(p Peer) error {
+	if s.light != nil {
+		if s.chain.HeaderHeight() < p.LastBlockIndex() {
+			if err := s.requestHeaders(p); err != nil {
+				return err
+			}
+		}
+		return s.light.requestRoots(p)
+	}
+	if s.stateSync.NeedHeaders() {
+		if s.chain.HeaderHeight() < p.LastBlockIndex() {
+			return s.requestHeaders(p)
+		}
+		return nil
+	}
+	if s.stateSync.IsActive() {
+		switch {
+		case s.stateSync.NeedStateRoot():
+			if p.LastBlockIndex() >= s.stateSync.SyncPoint() {
+				return p.EnqueueP2PMessage(NewMessage(CMDGetStateRoot, payload.NewGetStateRoot(s.stateSync.SyncPoint())))
+			}
+		case s.stateSync.NeedMPTNodes():
+			return s.requestMPTNodes(p, s.stateSync.GetUnknownMPTNodesBatch(payload.MaxMPTHashesCount))
+		}
+		return nil
+	}
 	bq := s.chain
 	if bq.HeaderHeight() < p.LastBlockIndex() {
 		err := s.requestHeaders(p)
@@ -571,6 +887,17 @@ func (s *Server) requestHeaders(p Peer) error {
 	return p.EnqueueP2PMessage(NewMessage(CMDGetHeaders, pl))
 }
 
+// requestMPTNodes requests specified MPT nodes from the peer.
+func (s *Server) requestMPTNodes(p Peer, itms []common.Hash) error {
+	if len(itms) == 0 {
+		return nil
+	}
+	if len(itms) > payload.MaxMPTHashesCount {
+		itms = itms[:payload.MaxMPTHashesCount]
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDGetMPTData, payload.NewMPTInventory(itms)))
+}
+
 // handlePing processes pong request.
 func (s *Server) handlePong(p Peer, pong *payload.Ping) error {
 	err := p.HandlePong(pong)
@@ -582,6 +909,12 @@ func (s *Server) handlePong(p Peer, pong *payload.Ping) error {
 
 // handleInvCmd processes the received inventory.
 func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
+	if inv.Type == payload.BlockType && s.stateSync.IsActive() || s.light != nil {
+		return nil
+	}
+	if inv.Type == payload.TXType {
+		s.txRelay.addKnown(p, inv.Hashes...)
+	}
 	reqHashes := make([]common.Hash, 0)
 	var typExists = map[payload.InventoryType]func(common.Hash) bool{
 		payload.TXType:    s.mempool.ContainsKey,
@@ -598,6 +931,11 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 			}
 		}
 	}
+	if inv.Type == payload.TXType {
+		// Transactions are fetched from one peer announcing them at a time,
+		// others are asked if it doesn't send them.
+		reqHashes = s.txRelay.request(p, reqHashes)
+	}
 	if len(reqHashes) > 0 {
 		msg := NewMessage(CMDGetData, payload.NewInventory(inv.Type, reqHashes))
 		pkt, err := msg.Bytes()
@@ -615,6 +953,15 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 // handleMempoolCmd handles getmempool command.
 func (s *Server) handleMempoolCmd(p Peer) error {
 	txs := s.mempool.GetVerifiedTransactions()
+	if f := s.getFilter(p); f != nil {
+		var filtered []*transaction.Transaction
+		for _, tx := range txs {
+			if txMatches(f, tx, nil) {
+				filtered = append(filtered, tx)
+			}
+		}
+		txs = filtered
+	}
 	hs := make([]common.Hash, 0, payload.MaxHashesCount)
 	for i := range txs {
 		hs = append(hs, txs[i].Hash())
@@ -631,9 +978,29 @@ func (s *Server) handleMempoolCmd(p Peer) error {
 	return nil
 }
 
+// handleFilterLoadCmd sets the bloom filter of the peer, only matching
+// transactions are announced to it after that and blocks are sent as
+// MerkleBlock.
+func (s *Server) handleFilterLoadCmd(p Peer, f *payload.FilterLoad) error {
+	s.setFilter(p, f)
+	return nil
+}
+
+// handleFilterAddCmd adds the element to the filter loaded by the peer.
+func (s *Server) handleFilterAddCmd(p Peer, fa *payload.FilterAdd) error {
+	return s.addToFilter(p, fa.Data)
+}
+
+// handleFilterClearCmd removes the filter of the peer.
+func (s *Server) handleFilterClearCmd(p Peer) error {
+	s.setFilter(p, nil)
+	return nil
+}
+
 // handleInvCmd processes the received inventory.
 func (s *Server) handleGetDataCmd(p Peer, inv *payload.Inventory) error {
 	var notFound []common.Hash
+	f := s.getFilter(p)
 	for _, hash := range inv.Hashes {
 		var msg *Message
 
@@ -642,15 +1009,21 @@ func (s *Server) handleGetDataCmd(p Peer, inv *payload.Inventory) error {
 			tx, _, err := s.chain.GetTransaction(hash)
 			if err == nil {
 				msg = NewMessage(CMDTX, tx)
+				s.txRelay.addKnown(p, hash)
 			} else {
 				notFound = append(notFound, hash)
 			}
 		case payload.BlockType:
 			b, _, err := s.chain.GetBlock(hash, true)
-			if err == nil {
-				msg = NewMessage(CMDBlock, b)
-			} else {
+			if err != nil {
 				notFound = append(notFound, hash)
+			} else if f != nil {
+				// Filtered peers get MerkleBlock instead of the block.
+				if err := s.enqueueMerkleBlock(p, f, b); err != nil {
+					return err
+				}
+			} else {
+				msg = NewMessage(CMDBlock, b)
 			}
 		case payload.ExtensibleType:
 			if cp := s.extensiblePool.Get(hash); cp != nil {
@@ -710,6 +1083,7 @@ func (s *Server) handleGetBlockByIndexCmd(p Peer, gbd *payload.GetBlockByIndex)
 	if gbd.Count < 0 || gbd.Count > payload.MaxHashesCount {
 		count = payload.MaxHashesCount
 	}
+	f := s.getFilter(p)
 	for i := gbd.IndexStart; i < gbd.IndexStart+uint32(count); i++ {
 		hash := s.chain.GetHeaderHash(int(i))
 		if hash == (common.Hash{}) {
@@ -719,6 +1093,12 @@ func (s *Server) handleGetBlockByIndexCmd(p Peer, gbd *payload.GetBlockByIndex)
 		if err != nil {
 			break
 		}
+		if f != nil {
+			if err = s.enqueueMerkleBlock(p, f, b); err != nil {
+				return err
+			}
+			continue
+		}
 		msg := NewMessage(CMDBlock, b)
 		if err = p.EnqueueP2PMessage(msg); err != nil {
 			return err
@@ -758,9 +1138,114 @@ func (s *Server) handleGetHeadersCmd(p Peer, gh *payload.GetBlockByIndex) error
 
 // handleHeadersCmd processes headers payload.
 func (s *Server) handleHeadersCmd(p Peer, h *payload.Headers) error {
+	if s.stateSync.NeedHeaders() {
+		return s.stateSync.AddHeaders(h.Hdrs...)
+	}
 	return s.chain.AddHeaders(h.Hdrs...)
 }
 
+// handleGetMPTDataCmd processes the received MPT inventory.
+func (s *Server) handleGetMPTDataCmd(p Peer, inv *payload.MPTInventory) error {
+	if !s.config.P2PStateExchangeExtensions {
+		return errors.New("GetMPTDataCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	if s.config.KeepOnlyLatestState {
+		return errors.New("GetMPTDataCMD was received, but only latest MPT state is supported")
+	}
+	resp := payload.MPTData{}
+	capLeft := payload.MaxSize - 8 // max(io.GetVarSize(len(resp.Nodes)))
+	added := make(map[common.Hash]struct{})
+	for _, h := range inv.Hashes {
+		if capLeft <= 2 { // at least 1 byte for len(nodeBytes) and 1 byte for node type
+			break
+		}
+		err := s.stateSync.Traverse(h,
+			func(n mpt.Node, node []byte) bool {
+				if _, ok := added[n.Hash()]; ok {
+					return false
+				}
+				l := len(node)
+				size := l + io.GetVarSize(l)
+				if size > capLeft {
+					return true
+				}
+				resp.Nodes = append(resp.Nodes, node)
+				added[n.Hash()] = struct{}{}
+				capLeft -= size
+				return false
+			})
+		if err != nil {
+			return fmt.Errorf("failed to traverse MPT starting from %s: %w", h.String(), err)
+		}
+	}
+	if len(resp.Nodes) > 0 {
+		return p.EnqueueP2PMessage(NewMessage(CMDMPTData, &resp))
+	}
+	return nil
+}
+
+// handleMPTDataCmd processes the received MPT nodes.
+func (s *Server) handleMPTDataCmd(p Peer, data *payload.MPTData) error {
+	if !s.config.P2PStateExchangeExtensions {
+		return errors.New("MPTDataCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	return s.stateSync.AddMPTNodes(data.Nodes)
+}
+
+// handleGetStateRootCmd sends signed state root for the requested height
+// if it's known.
+func (s *Server) handleGetStateRootCmd(p Peer, gsr *payload.GetStateRoot) error {
+	if !s.config.P2PStateExchangeExtensions {
+		return errors.New("GetStateRootCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	index := gsr.Index
+	if index == payload.LatestStateRoot {
+		index = s.chain.GetStateModule().CurrentValidatedHeight()
+	}
+	sr, err := s.chain.GetStateModule().GetStateRoot(index)
+	if err != nil || len(sr.Witness.VerificationScript) == 0 {
+		// Not yet validated roots are useless for state sync.
+		return nil
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDStateRoot, sr))
+}
+
+// handleStateRootCmd processes the received state root.
+func (s *Server) handleStateRootCmd(p Peer, sr *state.MPTRoot) error {
+	if !s.config.P2PStateExchangeExtensions {
+		return errors.New("StateRootCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	if s.light != nil {
+		return s.light.addRoot(sr)
+	}
+	return s.stateSync.AddStateRoot(sr)
+}
+
+// handleGetStateProofCmd sends the proof of the key in the MPT with the
+// requested root, empty proof is sent if the state is not known.
+func (s *Server) handleGetStateProofCmd(p Peer, req *payload.GetStateProof) error {
+	if !s.config.P2PStateExchangeExtensions {
+		return errors.New("GetStateProofCMD was received, but P2PStateExchangeExtensions are disabled")
+	}
+	proof, err := s.chain.GetStateModule().GetStateProof(req.Root, req.Key)
+	if err != nil && !errors.Is(err, mpt.ErrNotFound) {
+		proof = nil
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDStateProof, &payload.StateProof{
+		Root:  req.Root,
+		Key:   req.Key,
+		Proof: proof,
+	}))
+}
+
+// handleStateProofCmd processes the state proof requested by the light node.
+func (s *Server) handleStateProofCmd(p Peer, sp *payload.StateProof) error {
+	if s.light == nil {
+		return fmt.Errorf("%w: state proof", errUnrequested)
+	}
+	return s.light.addProof(sp)
+}
+
 // handleExtensibleCmd processes received extensible payload.
 func (s *Server) handleExtensibleCmd(e *payload.Extensible) error {
 	if !s.syncReached.Load() {
@@ -797,16 +1282,21 @@ func (s *Server) advertiseExtensible(e *payload.Extensible) {
 
 // handleTxCmd processes received transaction.
 // It never returns an error.
-func (s *Server) handleTxCmd(tx *transaction.Transaction) error {
+func (s *Server) handleTxCmd(p Peer, tx *transaction.Transaction) error {
+	h := tx.Hash()
+	s.txRelay.addKnown(p, h)
+	s.txRelay.received(h)
+	txReceived.Inc()
 	// It's OK for it to fail for various reasons like tx already existing
 	// in the pool.
 	s.txInLock.Lock()
-	_, ok := s.txInMap[tx.Hash()]
-	if ok || s.mempool.ContainsKey(tx.Hash()) {
+	_, ok := s.txInMap[h]
+	if ok || s.mempool.ContainsKey(h) {
 		s.txInLock.Unlock()
+		txDuplicates.Inc()
 		return nil
 	}
-	s.txInMap[tx.Hash()] = struct{}{}
+	s.txInMap[h] = struct{}{}
 	s.txInLock.Unlock()
 	if s.txCallback != nil {
 		s.txCallback(tx)
@@ -815,7 +1305,7 @@ func (s *Server) handleTxCmd(tx *transaction.Transaction) error {
 		s.broadcastTX(tx, nil)
 	}
 	s.txInLock.Lock()
-	delete(s.txInMap, tx.Hash())
+	delete(s.txInMap, h)
 	s.txInLock.Unlock()
 	return nil
 }
@@ -828,7 +1318,7 @@ func (s *Server) handleAddrCmd(p Peer, addrs *payload.AddressList) error {
 	dups := make(map[string]bool)
 	for _, a := range addrs.Addrs {
 		addr, err := a.GetTCPAddress()
-		if err == nil && !dups[addr] {
+		if err == nil && !dups[addr] && !s.addrBook.IsBanned(addr) {
 			dups[addr] = true
 			s.discovery.BackFill(addr)
 		}
@@ -852,35 +1342,25 @@ func (s *Server) handleGetAddrCmd(p Peer) error {
 	return p.EnqueueP2PMessage(NewMessage(CMDAddr, alist))
 }
 
-// requestBlocks sends a CMDGetBlockByIndex message to the peer
-// to sync up in blocks. A maximum of maxBlockBatch will
-// send at once. Two things we need to take care of:
-//  1. If possible, blocks should be fetched in parallel.
-//     height..+500 to one peer, height+500..+1000 to another etc.
-//  2. Every block must eventually be fetched even if peer sends no answer.
-//
-// Thus the following algorithm is used:
-// 1. Block range is divided into chunks of payload.MaxHashesCount.
-// 2. Send requests for chunk in increasing order.
-// 3. After all requests were sent, request random height.
+// requestBlocks sends CMDGetBlockByIndex messages to the peer to sync up in
+// blocks. Block ranges are assigned to peers by the blockFetcher, so that
+// different ranges are fetched from several peers in parallel.
 func (s *Server) requestBlocks(bq Blockqueuer, p Peer) error {
-	h := bq.BlockHeight()
-	pl := getRequestBlocksPayload(p, h, &s.lastRequestedBlock)
-	lq := s.bQueue.lastQueued()
-	if lq > pl.IndexStart {
-		c := int16(h + blockCacheSize - lq)
-		if c < payload.MaxHashesCount {
-			pl.Count = c
+	pls := s.fetcher.request(p, bq.BlockHeight(), s.bQueue.lastQueued(), p.LastBlockIndex())
+	for _, pl := range pls {
+		if err := p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, pl)); err != nil {
+			return err
 		}
-		pl.IndexStart = lq + 1
 	}
-	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, pl))
+	return nil
 }
 
+// getRequestBlocksPayload returns the payload for the next headers request,
+// blocks are requested via blockFetcher.
 func getRequestBlocksPayload(p Peer, currHeight uint32, lastRequestedHeight *atomic.Uint32) *payload.GetBlockByIndex {
 	var peerHeight = p.LastBlockIndex()
 	var needHeight uint32
-	// lastRequestedBlock can only be increased.
+	// lastRequestedHeight can only be increased.
 	for {
 		old := lastRequestedHeight.Load()
 		if old <= currHeight {
@@ -939,12 +1419,54 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 		case CMDHeaders:
 			h := msg.Payload.(*payload.Headers)
 			return s.handleHeadersCmd(peer, h)
+		case CMDGetMPTData:
+			inv := msg.Payload.(*payload.MPTInventory)
+			return s.handleGetMPTDataCmd(peer, inv)
+		case CMDMPTData:
+			data := msg.Payload.(*payload.MPTData)
+			return s.handleMPTDataCmd(peer, data)
+		case CMDGetStateRoot:
+			gsr := msg.Payload.(*payload.GetStateRoot)
+			return s.handleGetStateRootCmd(peer, gsr)
+		case CMDStateRoot:
+			sr := msg.Payload.(*state.MPTRoot)
+			return s.handleStateRootCmd(peer, sr)
+		case CMDGetStateProof:
+			req := msg.Payload.(*payload.GetStateProof)
+			return s.handleGetStateProofCmd(peer, req)
+		case CMDStateProof:
+			sp := msg.Payload.(*payload.StateProof)
+			return s.handleStateProofCmd(peer, sp)
 		case CMDInv:
 			inventory := msg.Payload.(*payload.Inventory)
 			return s.handleInvCmd(peer, inventory)
+		case CMDNotFound:
+			inv := msg.Payload.(*payload.Inventory)
+			return s.handleNotFoundCmd(peer, inv)
 		case CMDMempool:
 			// no payload
 			return s.handleMempoolCmd(peer)
+		case CMDCompactBlock:
+			cb := msg.Payload.(*payload.CompactBlock)
+			return s.handleCompactBlockCmd(peer, cb)
+		case CMDGetBlockTxn:
+			req := msg.Payload.(*payload.GetBlockTxn)
+			return s.handleGetBlockTxnCmd(peer, req)
+		case CMDBlockTxn:
+			resp := msg.Payload.(*payload.BlockTxn)
+			return s.handleBlockTxnCmd(peer, resp)
+		case CMDFilterLoad:
+			f := msg.Payload.(*payload.FilterLoad)
+			return s.handleFilterLoadCmd(peer, f)
+		case CMDFilterAdd:
+			fa := msg.Payload.(*payload.FilterAdd)
+			return s.handleFilterAddCmd(peer, fa)
+		case CMDFilterClear:
+			// no payload
+			return s.handleFilterClearCmd(peer)
+		case CMDMerkleBlock:
+			// Full node never requests filtered blocks.
+			return nil
 		case CMDBlock:
 			block := msg.Payload.(*block.Block)
 			return s.handleBlockCmd(peer, block)
@@ -953,7 +1475,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 			return s.handleExtensibleCmd(cp)
 		case CMDTX:
 			tx := msg.Payload.(*transaction.Transaction)
-			return s.handleTxCmd(tx)
+			return s.handleTxCmd(peer, tx)
 		case CMDPing:
 			ping := msg.Payload.(*payload.Ping)
 			return s.handlePing(peer, ping)
@@ -985,6 +1507,10 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 }
 
 func (s *Server) tryInitStateSync() {
+	if !s.stateSync.IsActive() || s.stateSync.IsInitialized() {
+		return
+	}
+
 	var peersNumber int
 	s.lock.RLock()
 	heights := make([]uint32, 0)
@@ -1003,6 +1529,18 @@ func (s *Server) tryInitStateSync() {
 		}
 	}
 	s.lock.RUnlock()
+	if peersNumber >= s.MinPeers && len(heights) > 0 {
+		// choose the height of the median peer as the current chain's height
+		h := heights[len(heights)/2]
+		err := s.stateSync.Init(h)
+		if err != nil {
+			s.log.Fatal("failed to init state sync module",
+				zap.Uint32("evaluated chain's blockHeight", h),
+				zap.Uint32("blockHeight", s.chain.BlockHeight()),
+				zap.Uint32("headerHeight", s.chain.HeaderHeight()),
+				zap.Error(err))
+		}
+	}
 }
 
 // BroadcastExtensible add locally-generated Extensible payload to the pool
@@ -1112,12 +1650,7 @@ func (s *Server) relayBlocksLoop() {
 			s.chain.UnsubscribeFromBlocks(ch)
 			return
 		case b := <-ch:
-			msg := NewMessage(CMDInv, payload.NewInventory(payload.BlockType, []common.Hash{b.Hash()}))
-			// Filter out nodes that are more current (avoid spamming the network
-			// during initial sync).
-			s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
-				return p.Handshaked() && p.LastBlockIndex() < b.Index
-			})
+			s.relayBlock(b)
 			s.extensiblePool.RemoveStale(b.Index)
 		}
 	}
@@ -1136,7 +1669,7 @@ func (s *Server) RelayTxn(t *transaction.Transaction) error {
 	return err
 }
 
-// broadcastTX broadcasts an inventory message about new transaction.
+// broadcastTX queues the new transaction for relaying to peers.
 func (s *Server) broadcastTX(t *transaction.Transaction, _ interface{}) {
 	select {
 	case s.transactions <- t:
@@ -1144,14 +1677,6 @@ func (s *Server) broadcastTX(t *transaction.Transaction, _ interface{}) {
 	}
 }
 
-func (s *Server) broadcastTxHashes(hs []common.Hash) {
-	msg := NewMessage(CMDInv, payload.NewInventory(payload.TXType, hs))
-
-	// We need to filter out non-relaying nodes, so plain broadcast
-	// functions don't fit here.
-	s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, Peer.IsFullNode)
-}
-
 // initStaleMemPools initializes mempools for stale tx/payload processing.
 func (s *Server) initStaleMemPools() {
 	threshold := 5
@@ -1172,7 +1697,7 @@ func (s *Server) broadcastTxLoop() {
 		batchSize = 32
 	)
 
-	txs := make([]common.Hash, 0, batchSize)
+	txs := make([]*transaction.Transaction, 0, batchSize)
 	var timer *time.Timer
 
 	timerCh := func() <-chan time.Time {
@@ -1183,7 +1708,7 @@ func (s *Server) broadcastTxLoop() {
 	}
 
 	broadcast := func() {
-		s.broadcastTxHashes(txs)
+		s.broadcastTxs(txs)
 		txs = txs[:0]
 		if timer != nil {
 			timer.Stop()
@@ -1211,7 +1736,7 @@ func (s *Server) broadcastTxLoop() {
 				timer = time.NewTimer(batchTime)
 			}
 
-			txs = append(txs, tx.Hash())
+			txs = append(txs, tx)
 			if len(txs) == batchSize {
 				broadcast()
 			}
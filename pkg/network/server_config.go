@@ -41,6 +41,9 @@ type (
 		// Relay determines whether the server is forwarding its inventory.
 		Relay bool
 
+		// CompactBlocks enables compact block relay.
+		CompactBlocks bool
+
 		// Seeds are a list of initial nodes used to establish connectivity.
 		Seeds []string
 
@@ -70,6 +73,18 @@ type (
 
 		// ExtensiblePoolSize is size of the pool for extensible payloads from a single sender.
 		ExtensiblePoolSize int
+
+		// AddressBook is the peer address book configuration.
+		AddressBook config.AddressBook
+
+		// P2PEncryption is the encrypted transport configuration.
+		P2PEncryption config.P2PEncryption
+
+		// LightNode is the headers-only node configuration.
+		LightNode config.LightNode
+
+		// RateLimit is the incoming messages rate limiting configuration.
+		RateLimit config.RateLimit
 	}
 )
 
@@ -91,6 +106,7 @@ func NewServerConfig(cfg config.Config) ServerConfig {
 		Port:               appConfig.NodePort,
 		ChainID:            protoConfig.ChainID,
 		Relay:              appConfig.Relay,
+		CompactBlocks:      appConfig.CompactBlocks,
 		Seeds:              protoConfig.SeedList,
 		DialTimeout:        time.Duration(appConfig.DialTimeout) * time.Second,
 		ProtoTickInterval:  time.Duration(appConfig.ProtoTickInterval) * time.Second,
@@ -103,5 +119,9 @@ func NewServerConfig(cfg config.Config) ServerConfig {
 		TimePerBlock:       time.Duration(protoConfig.SecondsPerBlock) * time.Second,
 		StateRootCfg:       appConfig.StateRoot,
 		ExtensiblePoolSize: appConfig.ExtensiblePoolSize,
+		AddressBook:        appConfig.AddressBook,
+		P2PEncryption:      appConfig.P2PEncryption,
+		LightNode:          appConfig.LightNode,
+		RateLimit:          appConfig.RateLimit,
 	}
 }
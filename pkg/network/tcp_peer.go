@@ -62,10 +62,21 @@ type TCPPeer struct {
 
 	// track outstanding getaddr requests.
 	getAddrSent atomic.Int32
+	// requests is the number of requests received from the peer and not
+	// handled yet.
+	requests atomic.Int32
+
+	// limiter drops messages exceeding rate limits, it's nil if they're
+	// disabled.
+	limiter *rateLimiter
 
 	// number of sent pings.
 	pingSent  int
 	pingTimer *time.Timer
+	// pingSentAt is the time of the last ping sent.
+	pingSentAt time.Time
+	// latency is the last measured ping/pong round-trip time.
+	latency time.Duration
 }
 
 // NewTCPPeer returns a TCPPeer structure based on the given connection.
@@ -78,6 +89,7 @@ func NewTCPPeer(conn net.Conn, s *Server) *TCPPeer {
 		p2pSendQ: make(chan []byte, p2pMsgQueueSize),
 		hpSendQ:  make(chan []byte, hpRequestQueueSize),
 		incoming: make(chan *Message, incomingQueueSize),
+		limiter:  newRateLimiter(s.RateLimit),
 	}
 }
 
@@ -174,8 +186,12 @@ func (p *TCPPeer) handleConn() {
 				p.server.log.Warn("not all headers were processed")
 				r.Err = nil
 			} else if err != nil {
+				p.server.penalize(p, err)
 				break
 			}
+			if isRequest(msg.Command) {
+				p.requests.Inc()
+			}
 			p.incoming <- msg
 		}
 	}
@@ -186,10 +202,16 @@ func (p *TCPPeer) handleConn() {
 func (p *TCPPeer) handleIncoming() {
 	var err error
 	for msg := range p.incoming {
+		if !p.allow(msg) {
+			p.requestDone(msg)
+			continue
+		}
 		err = p.server.handleMessage(p, msg)
+		p.requestDone(msg)
 		if err != nil {
 			if p.Handshaked() {
 				err = fmt.Errorf("handling %s message: %w", msg.Command.String(), err)
+				p.server.penalize(p, err)
 			}
 			break
 		}
@@ -197,6 +219,39 @@ func (p *TCPPeer) handleIncoming() {
 	p.Disconnect(err)
 }
 
+// allow checks the message against the peer rate limits, penalizing the peer
+// flooding the node.
+func (p *TCPPeer) allow(msg *Message) bool {
+	if p.limiter == nil || !p.Handshaked() {
+		return true
+	}
+	reason := p.limiter.allow(msg.Command, len(msg.compressedPayload), p.inFlight())
+	if reason == "" {
+		return true
+	}
+	droppedMessages.WithLabelValues(msg.Command.String(), reason).Inc()
+	// Peer sending requests too often or too many of them at once is
+	// throttled only, it can be just eager, flooding the node with data or
+	// exceeding limits persistently is penalized.
+	if reason == dropBytes || p.limiter.dropped(time.Now()) {
+		p.server.penalize(p, fmt.Errorf("%w: %s %s", errRateLimited, msg.Command, reason))
+	}
+	return false
+}
+
+// requestDone marks the request as handled.
+func (p *TCPPeer) requestDone(msg *Message) {
+	if isRequest(msg.Command) {
+		p.requests.Dec()
+	}
+}
+
+// inFlight returns the number of requests received from the peer and not
+// handled yet excluding the one being processed.
+func (p *TCPPeer) inFlight() int {
+	return int(p.requests.Load()) - 1
+}
+
 // handleQueues is a goroutine that is started automatically to handle
 // send queues.
 func (p *TCPPeer) handleQueues() {
@@ -267,6 +322,7 @@ func (p *TCPPeer) StartProtocol() {
 		zap.Uint32("id", p.Version().Nonce))
 
 	p.server.discovery.RegisterGoodAddr(p.PeerAddr().String(), p.version.Capabilities)
+	p.server.addrBook.RegisterGood(p.PeerAddr().String(), p.version.Capabilities)
 	err = p.server.requestBlocksOrHeaders(p)
 	if err != nil {
 		p.Disconnect(err)
@@ -451,6 +507,7 @@ func (p *TCPPeer) SendPing(msg *Message) error {
 	p.lock.Lock()
 	p.pingSent++
 	if p.pingTimer == nil {
+		p.pingSentAt = time.Now()
 		p.pingTimer = time.AfterFunc(p.server.PingTimeout, func() {
 			p.Disconnect(errPingPong)
 		})
@@ -481,9 +538,23 @@ func (p *TCPPeer) HandlePong(pong *payload.Ping) error {
 		return errUnexpectedPong
 	}
 	p.lastBlockIndex = pong.LastBlockIndex
+	p.latency = time.Since(p.pingSentAt)
+	p.server.addrBook.UpdateLatency(p.PeerAddr().String(), p.latency)
 	return nil
 }
 
+// Stats implements the Peer interface.
+func (p *TCPPeer) Stats() PeerStats {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return PeerStats{
+		Latency:   p.latency,
+		SendQueue: len(p.sendQ),
+		P2PQueue:  len(p.p2pSendQ),
+		HPQueue:   len(p.hpSendQ),
+	}
+}
+
 // AddGetAddrSent increments internal outstanding getaddr requests counter. The
 // peer can only send then one addr reply per getaddr request.
 func (p *TCPPeer) AddGetAddrSent() {
@@ -1,6 +1,8 @@
 package network
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"sync"
@@ -9,14 +11,16 @@ import (
 	"go.uber.org/zap"
 )
 
-// TCPTransport allows network communication over TCP.
+// TCPTransport allows network communication over TCP, connections are
+// encrypted if TLS configuration is set.
 type TCPTransport struct {
-	log      *zap.Logger
-	server   *Server
-	listener net.Listener
-	bindAddr string
-	lock     sync.RWMutex
-	quit     bool
+	log       *zap.Logger
+	server    *Server
+	listener  net.Listener
+	bindAddr  string
+	tlsConfig *tls.Config
+	lock      sync.RWMutex
+	quit      bool
 }
 
 // NewTCPTransport returns a new TCPTransport that will listen for
@@ -29,17 +33,59 @@ func NewTCPTransport(s *Server, bindAddr string, log *zap.Logger) *TCPTransport
 	}
 }
 
+// NewTLSTransport returns a new TCPTransport that uses TLS with the given
+// configuration for all connections.
+func NewTLSTransport(s *Server, bindAddr string, cfg *tls.Config, log *zap.Logger) *TCPTransport {
+	t := NewTCPTransport(s, bindAddr, log)
+	t.tlsConfig = cfg
+	return t
+}
+
 // Dial implements the Transporter interface.
 func (t *TCPTransport) Dial(addr string, timeout time.Duration) error {
 	conn, err := net.DialTimeout("tcp", addr, timeout)
 	if err != nil {
 		return err
 	}
+	if t.tlsConfig != nil {
+		tlsConn := tls.Client(conn, t.tlsConfig)
+		if err := t.handshake(tlsConn, timeout); err != nil {
+			return err
+		}
+		conn = tlsConn
+	}
 	p := NewTCPPeer(conn, t.server)
 	go p.handleConn()
 	return nil
 }
 
+// handshake performs TLS handshake closing the connection on failure.
+func (t *TCPTransport) handshake(conn *tls.Conn, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := conn.HandshakeContext(ctx)
+	if err != nil {
+		conn.Close()
+	}
+	return err
+}
+
+// handleAccepted starts protocol handling for the incoming connection.
+func (t *TCPTransport) handleAccepted(conn net.Conn) {
+	if t.tlsConfig != nil {
+		tlsConn := tls.Server(conn, t.tlsConfig)
+		if err := t.handshake(tlsConn, tlsHandshakeTimeout); err != nil {
+			t.log.Info("TLS handshake failed",
+				zap.Stringer("addr", conn.RemoteAddr()),
+				zap.Error(err))
+			return
+		}
+		conn = tlsConn
+	}
+	p := NewTCPPeer(conn, t.server)
+	p.handleConn()
+}
+
 // Accept implements the Transporter interface.
 func (t *TCPTransport) Accept() {
 	l, err := net.Listen("tcp", t.bindAddr)
@@ -69,8 +115,7 @@ func (t *TCPTransport) Accept() {
 			t.log.Warn("TCP accept error", zap.Error(err))
 			continue
 		}
-		p := NewTCPPeer(conn, t.server)
-		go p.handleConn()
+		go t.handleAccepted(conn)
 	}
 }
 
@@ -86,6 +131,9 @@ func (t *TCPTransport) Close() {
 
 // Proto implements the Transporter interface.
 func (t *TCPTransport) Proto() string {
+	if t.tlsConfig != nil {
+		return "tls"
+	}
 	return "tcp"
 }
 
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+)
+
+// tlsHandshakeTimeout is the time TLS handshake of a new connection may take.
+const tlsHandshakeTimeout = 10 * time.Second
+
+var errNotAllowedKey = errors.New("peer key is not allowed")
+
+// LoadNodeKey reads hex-encoded ed25519 node key seed from the file. New key
+// is generated and saved if the file doesn't exist.
+func LoadNodeKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid node key: %w", err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid node key length: %d", len(seed))
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key.Seed())), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newTLSConfig creates TLS 1.3 configuration for the encrypted transport
+// using the node key from the given configuration. It's used for both sides
+// of the connection, certificates are self-signed and only peer keys are
+// checked against the allowed list (if it's not empty).
+func newTLSConfig(cfg config.P2PEncryption) (*tls.Config, ed25519.PublicKey, error) {
+	if cfg.KeyFile == "" {
+		return nil, nil, errors.New("node key file is not set")
+	}
+	key, err := LoadNodeKey(cfg.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't load node key: %w", err)
+	}
+	allowed := make([]ed25519.PublicKey, 0, len(cfg.AllowedKeys))
+	for _, s := range cfg.AllowedKeys {
+		pub, err := hex.DecodeString(s)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return nil, nil, fmt.Errorf("invalid allowed key %s", s)
+		}
+		allowed = append(allowed, pub)
+	}
+	cert, err := newNodeCertificate(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// There are no CAs, peers are authenticated by their keys.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyPeerKey(rawCerts, allowed)
+		},
+	}, key.Public().(ed25519.PublicKey), nil
+}
+
+// newNodeCertificate creates self-signed certificate for the node key.
+func newNodeCertificate(key ed25519.PrivateKey) (tls.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hex.EncodeToString(key.Public().(ed25519.PublicKey))},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("can't create node certificate: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// verifyPeerKey checks that the peer certificate has ed25519 key from the
+// allowed list, any key is accepted if the list is empty. TLS handshake
+// ensures that the peer has the corresponding private key.
+func verifyPeerKey(rawCerts [][]byte, allowed []ed25519.PublicKey) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no peer certificate")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return errors.New("peer key is not ed25519")
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, k := range allowed {
+		if bytes.Equal(k, pub) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", errNotAllowedKey, hex.EncodeToString(pub))
+}
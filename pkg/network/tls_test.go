@@ -0,0 +1,116 @@
+package network
+
+import (
+	"crypto/ed25519"
+	"crypto/tls"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadNodeKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node", "key")
+	key, err := LoadNodeKey(path)
+	require.NoError(t, err)
+	loaded, err := LoadNodeKey(path)
+	require.NoError(t, err)
+	require.Equal(t, key, loaded)
+}
+
+func newTestTLSConfig(t *testing.T, allowed ...ed25519.PublicKey) (*tls.Config, ed25519.PublicKey) {
+	cfg := config.P2PEncryption{
+		Enabled: true,
+		KeyFile: filepath.Join(t.TempDir(), "key"),
+	}
+	for _, k := range allowed {
+		cfg.AllowedKeys = append(cfg.AllowedKeys, hex.EncodeToString(k))
+	}
+	tlsCfg, pub, err := newTLSConfig(cfg)
+	require.NoError(t, err)
+	return tlsCfg, pub
+}
+
+// tlsExchange sends the message from the client to the server over loopback
+// TLS connection and returns the message received by the server.
+func tlsExchange(t *testing.T, serverCfg, clientCfg *tls.Config, msg *Message) (*Message, error) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverCfg)
+	require.NoError(t, err)
+	defer l.Close()
+
+	type result struct {
+		msg *Message
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			ch <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		m := &Message{}
+		err = m.Decode(io.NewBinReaderFromIO(conn))
+		ch <- result{msg: m, err: err}
+	}()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), clientCfg)
+	if err != nil {
+		<-ch
+		return nil, err
+	}
+	defer conn.Close()
+	data, err := msg.Bytes()
+	require.NoError(t, err)
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+	res := <-ch
+	return res.msg, res.err
+}
+
+func TestTLSTransportMessages(t *testing.T) {
+	serverCfg, _ := newTestTLSConfig(t)
+	clientCfg, _ := newTestTLSConfig(t)
+
+	// The payload is big enough to be compressed.
+	nodes := [][]byte{make([]byte, 2*CompressionMinSize), {1, 2, 3}}
+	msg := NewMessage(CMDMPTData, &payload.MPTData{Nodes: nodes})
+	res, err := tlsExchange(t, serverCfg, clientCfg, msg)
+	require.NoError(t, err)
+	require.Equal(t, CMDMPTData, res.Command)
+	require.NotZero(t, res.Flags&Compressed)
+	require.Equal(t, nodes, res.Payload.(*payload.MPTData).Nodes)
+}
+
+func TestTLSTransportAllowedKeys(t *testing.T) {
+	allowedCfg, allowedKey := newTestTLSConfig(t)
+	otherCfg, _ := newTestTLSConfig(t)
+	serverCfg, _ := newTestTLSConfig(t, allowedKey)
+
+	msg := NewMessage(CMDGetAddr, payload.NewNullPayload())
+	res, err := tlsExchange(t, serverCfg, allowedCfg, msg)
+	require.NoError(t, err)
+	require.Equal(t, CMDGetAddr, res.Command)
+
+	// Server rejects unknown client, TLS 1.3 client learns about it
+	// on the first read only.
+	_, err = tlsExchange(t, serverCfg, otherCfg, msg)
+	require.Error(t, err)
+
+	// Client checks the server key too.
+	clientCfg, _ := newTestTLSConfig(t, allowedKey)
+	_, err = tlsExchange(t, serverCfg, clientCfg, msg)
+	require.ErrorIs(t, err, errNotAllowedKey)
+
+	// Only TLS 1.3 is accepted.
+	oldCfg := clientCfg.Clone()
+	oldCfg.MinVersion = tls.VersionTLS12
+	oldCfg.MaxVersion = tls.VersionTLS12
+	_, err = tlsExchange(t, serverCfg, oldCfg, msg)
+	require.Error(t, err)
+}
@@ -0,0 +1,309 @@
+package network
+
+import (
+	"math"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	// maxKnownTxs is the number of transaction hashes remembered per peer.
+	maxKnownTxs = 8192
+	// txRequestTimeout is the time after which the transaction requested
+	// from one peer is requested from the next one announcing it.
+	txRequestTimeout = 5 * time.Second
+	// txRetryInterval is the interval of checking requests for timeouts.
+	txRetryInterval = time.Second
+	// maxRequestedTxs is the number of requested transactions after which
+	// expired requests are cleaned up.
+	maxRequestedTxs = 4096
+	// maxTxAnnouncers is the number of peers remembered per requested
+	// transaction in addition to the one it's requested from.
+	maxTxAnnouncers = 8
+)
+
+// txRelay tracks transactions known to peers (sent by us, received or
+// announced by them) and transactions requested from peers.
+type txRelay struct {
+	lock      sync.Mutex
+	known     map[Peer]*lru.Cache
+	requested map[common.Hash]*txRequest
+}
+
+// txRequest is the transaction requested from the peer, other peers announcing
+// it are asked for it if the peer doesn't send it in time.
+type txRequest struct {
+	peer       Peer
+	at         time.Time
+	announcers []Peer
+}
+
+func newTxRelay() *txRelay {
+	return &txRelay{
+		known:     make(map[Peer]*lru.Cache),
+		requested: make(map[common.Hash]*txRequest),
+	}
+}
+
+// addKnown marks the transactions as known to the peer.
+func (r *txRelay) addKnown(p Peer, hashes ...common.Hash) {
+	r.lock.Lock()
+	known, ok := r.known[p]
+	if !ok {
+		known, _ = lru.New(maxKnownTxs)
+		r.known[p] = known
+	}
+	r.lock.Unlock()
+	for _, h := range hashes {
+		known.Add(h, struct{}{})
+	}
+}
+
+// isKnown checks whether the peer knows the transaction.
+func (r *txRelay) isKnown(p Peer, h common.Hash) bool {
+	r.lock.Lock()
+	known, ok := r.known[p]
+	r.lock.Unlock()
+	return ok && known.Contains(h)
+}
+
+// removePeer drops the state of the disconnected peer and returns the
+// transactions requested from it to be requested from other announcers.
+func (r *txRelay) removePeer(p Peer) map[Peer][]common.Hash {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.known, p)
+	res := make(map[Peer][]common.Hash)
+	now := time.Now()
+	for h, req := range r.requested {
+		if req.peer == p {
+			r.next(h, req, now, res)
+			continue
+		}
+		for i := range req.announcers {
+			if req.announcers[i] == p {
+				req.announcers = append(req.announcers[:i], req.announcers[i+1:]...)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// request returns the transactions announced by the peer that should be
+// requested from it, that is the ones not requested from other peers recently.
+// The peer is remembered as the announcer of the other ones.
+func (r *txRelay) request(p Peer, hashes []common.Hash) []common.Hash {
+	now := time.Now()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.requested) >= maxRequestedTxs {
+		for h, req := range r.requested {
+			if now.Sub(req.at) > txRequestTimeout {
+				delete(r.requested, h)
+			}
+		}
+	}
+	res := hashes[:0]
+	for _, h := range hashes {
+		req, ok := r.requested[h]
+		if ok && now.Sub(req.at) <= txRequestTimeout {
+			if req.peer != p && len(req.announcers) < maxTxAnnouncers &&
+				!containsPeer(req.announcers, p) {
+				req.announcers = append(req.announcers, p)
+			}
+			continue
+		}
+		if !ok {
+			req = &txRequest{}
+			r.requested[h] = req
+		}
+		req.peer = p
+		req.at = now
+		res = append(res, h)
+	}
+	return res
+}
+
+// notFound returns the transactions the peer doesn't have to be requested
+// from the next announcers.
+func (r *txRelay) notFound(p Peer, hashes []common.Hash) map[Peer][]common.Hash {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make(map[Peer][]common.Hash)
+	now := time.Now()
+	for _, h := range hashes {
+		if req, ok := r.requested[h]; ok && req.peer == p {
+			r.next(h, req, now, res)
+		}
+	}
+	return res
+}
+
+// expired returns the transactions not received in time to be requested from
+// the next announcers.
+func (r *txRelay) expired() map[Peer][]common.Hash {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make(map[Peer][]common.Hash)
+	now := time.Now()
+	for h, req := range r.requested {
+		if now.Sub(req.at) > txRequestTimeout {
+			r.next(h, req, now, res)
+		}
+	}
+	return res
+}
+
+// next moves the request to the next announcer adding it to res or forgets it
+// if there are no announcers left. It must be called with the lock held.
+func (r *txRelay) next(h common.Hash, req *txRequest, now time.Time, res map[Peer][]common.Hash) {
+	if len(req.announcers) == 0 {
+		delete(r.requested, h)
+		return
+	}
+	req.peer = req.announcers[0]
+	req.announcers = req.announcers[1:]
+	req.at = now
+	res[req.peer] = append(res[req.peer], h)
+}
+
+// received removes the transaction from the requested ones.
+func (r *txRelay) received(h common.Hash) {
+	r.lock.Lock()
+	delete(r.requested, h)
+	r.lock.Unlock()
+}
+
+func containsPeer(peers []Peer, p Peer) bool {
+	for i := range peers {
+		if peers[i] == p {
+			return true
+		}
+	}
+	return false
+}
+
+// txPushCount returns the number of peers receiving full transactions, others
+// get announcements only.
+func txPushCount(peers int) int {
+	return int(math.Sqrt(float64(peers)))
+}
+
+// broadcastTxs relays the transactions to peers that don't know them yet:
+// square root of peers get the transactions, others get the inventory and
+// fetch them if needed. Filtered peers only get the inventory of matching
+// transactions.
+func (s *Server) broadcastTxs(txs []*transaction.Transaction) {
+	hs := make([]common.Hash, len(txs))
+	for i := range txs {
+		hs[i] = txs[i].Hash()
+	}
+	txPkts := make([][]byte, len(txs))
+
+	// We need to filter out non-relaying nodes, so plain broadcast
+	// functions don't fit here.
+	peers := s.getPeers(func(p Peer) bool {
+		return p.Handshaked() && p.IsFullNode() && !s.isFiltered(p)
+	})
+	mrand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	push := txPushCount(len(peers))
+	for i, p := range peers {
+		var unknown []int
+		for j := range hs {
+			if !s.txRelay.isKnown(p, hs[j]) {
+				unknown = append(unknown, j)
+			}
+		}
+		if len(unknown) == 0 {
+			continue
+		}
+		if i < push {
+			for _, j := range unknown {
+				if txPkts[j] == nil {
+					pkt, err := NewMessage(CMDTX, txs[j]).Bytes()
+					if err != nil {
+						continue
+					}
+					txPkts[j] = pkt
+				}
+				if p.EnqueuePacket(false, txPkts[j]) == nil {
+					s.txRelay.addKnown(p, hs[j])
+					txPushed.Inc()
+				}
+			}
+			continue
+		}
+		announce := make([]common.Hash, len(unknown))
+		for k, j := range unknown {
+			announce[k] = hs[j]
+		}
+		if s.enqueueTxInv(p, announce) == nil {
+			s.txRelay.addKnown(p, announce...)
+			txAnnounced.Add(float64(len(announce)))
+		}
+	}
+
+	// Filtered peers only get matching transactions.
+	for _, p := range s.getPeers(Peer.Handshaked) {
+		f := s.getFilter(p)
+		if f == nil {
+			continue
+		}
+		var matched []common.Hash
+		for _, h := range filterTxHashes(f, txs) {
+			if !s.txRelay.isKnown(p, h) {
+				matched = append(matched, h)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		if s.enqueueTxInv(p, matched) == nil {
+			s.txRelay.addKnown(p, matched...)
+		}
+	}
+}
+
+// enqueueTxInv sends the inventory of transactions to the peer.
+func (s *Server) enqueueTxInv(p Peer, hashes []common.Hash) error {
+	pkt, err := NewMessage(CMDInv, payload.NewInventory(payload.TXType, hashes)).Bytes()
+	if err != nil {
+		return err
+	}
+	return p.EnqueuePacket(false, pkt)
+}
+
+// requestTxs sends the requests of transactions to the peers.
+func (s *Server) requestTxs(reqs map[Peer][]common.Hash) {
+	for p, hs := range reqs {
+		for len(hs) > 0 {
+			n := len(hs)
+			if n > payload.MaxHashesCount {
+				n = payload.MaxHashesCount
+			}
+			msg := NewMessage(CMDGetData, payload.NewInventory(payload.TXType, hs[:n]))
+			if err := p.EnqueueP2PMessage(msg); err != nil {
+				break
+			}
+			hs = hs[n:]
+		}
+	}
+}
+
+// handleNotFoundCmd requests the transactions the peer doesn't have from other
+// peers announcing them.
+func (s *Server) handleNotFoundCmd(p Peer, inv *payload.Inventory) error {
+	if inv.Type == payload.TXType {
+		s.requestTxs(s.txRelay.notFound(p, inv.Hashes))
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package network
+
+import (
+	"container/heap"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/payload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// simPeer is the peer of the simulated network, only its identity is used.
+type simPeer struct {
+	Peer
+	id int
+}
+
+func TestTxRelayKnown(t *testing.T) {
+	r := newTxRelay()
+	p1, p2 := &simPeer{id: 1}, &simPeer{id: 2}
+	h := common.HexToHash("0x01")
+	r.addKnown(p1, h)
+	require.True(t, r.isKnown(p1, h))
+	require.False(t, r.isKnown(p2, h))
+	r.removePeer(p1)
+	require.False(t, r.isKnown(p1, h))
+}
+
+func TestTxRelayRequest(t *testing.T) {
+	r := newTxRelay()
+	p1, p2, p3 := &simPeer{id: 1}, &simPeer{id: 2}, &simPeer{id: 3}
+	h1, h2 := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	hs := []common.Hash{h1, h2}
+	require.Equal(t, hs, r.request(p1, append([]common.Hash{}, hs...)))
+	require.Empty(t, r.request(p1, append([]common.Hash{}, hs...)))
+	require.Empty(t, r.request(p2, append([]common.Hash{}, hs...)))
+	require.Empty(t, r.request(p2, []common.Hash{h1}))
+	require.Empty(t, r.request(p3, []common.Hash{h1}))
+	r.received(h2)
+	require.Equal(t, hs[1:], r.request(p3, append([]common.Hash{}, hs...)))
+
+	// Only the peer the transaction is requested from can refuse it.
+	require.Empty(t, r.notFound(p3, []common.Hash{h1}))
+	require.Equal(t, map[Peer][]common.Hash{p2: {h1}}, r.notFound(p1, []common.Hash{h1}))
+
+	// Every announcer is asked once.
+	r.requested[h1].at = time.Now().Add(-2 * txRequestTimeout)
+	require.Equal(t, map[Peer][]common.Hash{p3: {h1}}, r.expired())
+	require.Empty(t, r.expired())
+	r.requested[h1].at = time.Now().Add(-2 * txRequestTimeout)
+	require.Empty(t, r.expired())
+	require.NotContains(t, r.requested, h1)
+
+	// Expired request is sent to the new announcer.
+	r.requested[h2].at = time.Now().Add(-2 * txRequestTimeout)
+	require.Equal(t, hs[1:], r.request(p1, []common.Hash{h2}))
+
+	// Transactions requested from the disconnected peer are requested from
+	// others, it's not asked after disconnection.
+	require.Empty(t, r.request(p2, []common.Hash{h2}))
+	require.Empty(t, r.request(p3, []common.Hash{h2}))
+	require.Empty(t, r.removePeer(p2))
+	require.Equal(t, map[Peer][]common.Hash{p3: {h2}}, r.removePeer(p1))
+	require.Empty(t, r.notFound(p3, []common.Hash{h2}))
+	require.Empty(t, r.requested)
+}
+
+func newTxRelayTestPeers(s *Server, n int) []*testPeer {
+	peers := make([]*testPeer, n)
+	for i := range peers {
+		peers[i] = newTestPeer(fmt.Sprintf("1.2.3.%d:20333", i+1), nil)
+	}
+	addPeers(s, peers...)
+	return peers
+}
+
+func TestServerBroadcastTxs(t *testing.T) {
+	s := newTestServer(t, ServerConfig{})
+	peers := newTxRelayTestPeers(s, 9)
+	txs := []*transaction.Transaction{
+		newFilterTestTx(1, common.Address{1}, nil),
+		newFilterTestTx(2, common.Address{1}, nil),
+	}
+	hs := []common.Hash{txs[0].Hash(), txs[1].Hash()}
+
+	// The transaction announced by the peer is not relayed to it.
+	announcer := peers[0]
+	require.NoError(t, s.handleInvCmd(announcer, payload.NewInventory(payload.TXType, hs[1:])))
+	require.Equal(t, []CommandType{CMDGetData}, announcer.commands())
+
+	s.broadcastTxs(txs)
+	var pushed, announced int
+	for _, p := range peers {
+		msgs := p.getMessages()
+		var got []common.Hash
+		for _, msg := range msgs {
+			switch msg.Command {
+			case CMDTX:
+				got = append(got, msg.Payload.(*transaction.Transaction).Hash())
+				pushed++
+			case CMDInv:
+				inv := msg.Payload.(*payload.Inventory)
+				require.Equal(t, payload.TXType, inv.Type)
+				got = append(got, inv.Hashes...)
+				announced++
+			default:
+				t.Fatalf("unexpected %s", msg.Command)
+			}
+		}
+		if p == announcer {
+			require.Equal(t, hs[:1], got)
+		} else {
+			require.ElementsMatch(t, hs, got)
+		}
+	}
+	// Square root of peers gets transactions (the announcer only gets one if
+	// it's among them), others get a single inventory.
+	push := txPushCount(len(peers))
+	require.Contains(t, []int{2*push - 1, 2 * push}, pushed)
+	require.Equal(t, len(peers)-push, announced)
+
+	// Known transactions are not relayed again, only the new peer gets them.
+	newPeer := newTestPeer("1.2.3.100:20333", nil)
+	addPeers(s, newPeer)
+	s.broadcastTxs(txs)
+	for _, p := range peers {
+		require.Empty(t, p.getMessages())
+	}
+	require.NotEmpty(t, newPeer.getMessages())
+	s.broadcastTxs(txs)
+	require.Empty(t, newPeer.getMessages())
+}
+
+func TestServerTxRequestRetry(t *testing.T) {
+	s := newTestServer(t, ServerConfig{})
+	peers := newTxRelayTestPeers(s, 3)
+	h := common.HexToHash("0x01")
+	inv := payload.NewInventory(payload.TXType, []common.Hash{h})
+	requested := func(p *testPeer) bool {
+		msgs := p.getMessages()
+		if len(msgs) != 1 || msgs[0].Command != CMDGetData {
+			return false
+		}
+		return assert.ObjectsAreEqual([]common.Hash{h}, msgs[0].Payload.(*payload.Inventory).Hashes)
+	}
+
+	// The transaction is requested from the first announcer only.
+	for _, p := range peers {
+		require.NoError(t, s.handleMessage(p, NewMessage(CMDInv, inv)))
+	}
+	require.True(t, requested(peers[0]))
+	require.Empty(t, peers[1].getMessages())
+	require.Empty(t, peers[2].getMessages())
+
+	// The next announcer is asked if the peer doesn't have it.
+	require.NoError(t, s.handleMessage(peers[0], NewMessage(CMDNotFound, inv)))
+	require.True(t, requested(peers[1]))
+	require.Empty(t, peers[2].getMessages())
+
+	// And if the peer doesn't respond in time.
+	s.txRelay.lock.Lock()
+	s.txRelay.requested[h].at = time.Now().Add(-2 * txRequestTimeout)
+	s.txRelay.lock.Unlock()
+	s.requestTxs(s.txRelay.expired())
+	require.True(t, requested(peers[2]))
+	require.Empty(t, peers[0].getMessages())
+	require.Empty(t, peers[1].getMessages())
+}
+
+// simEvent is the message delivered in the simulated network.
+type simEvent struct {
+	at       int
+	from, to int
+	kind     CommandType
+	// pulled is set for the transaction sent in response to getdata.
+	pulled bool
+}
+
+type simQueue []simEvent
+
+func (q simQueue) Len() int            { return len(q) }
+func (q simQueue) Less(i, j int) bool  { return q[i].at < q[j].at }
+func (q simQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *simQueue) Push(x interface{}) { *q = append(*q, x.(simEvent)) }
+func (q *simQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// simulateTxRelay relays the transaction from the first node of the network
+// with random link latencies and returns the number of transactions received,
+// the number of duplicates among them and the number of transactions fetched
+// with getdata. Nodes call relay on the first
+// receipt of the transaction and onInv on every announcement, both return the
+// messages to be sent.
+func simulateTxRelay(t *testing.T, links []map[int]*simPeer, seed int64,
+	relay func(i, from int) []simEvent, onInv func(i, from int, has bool) []simEvent) (int, int, int) {
+	r := rand.New(rand.NewSource(seed))
+	has := make([]bool, len(links))
+	q := &simQueue{}
+	send := func(now int, evs []simEvent) {
+		for _, e := range evs {
+			e.at = now + 10 + r.Intn(90)
+			heap.Push(q, e)
+		}
+	}
+	has[0] = true
+	send(0, relay(0, -1))
+	var received, duplicates, pulled int
+	for q.Len() > 0 {
+		e := heap.Pop(q).(simEvent)
+		switch e.kind {
+		case CMDTX:
+			received++
+			if e.pulled {
+				pulled++
+			}
+			if has[e.to] {
+				duplicates++
+				continue
+			}
+			has[e.to] = true
+			send(e.at, relay(e.to, e.from))
+		case CMDInv:
+			send(e.at, onInv(e.to, e.from, has[e.to]))
+		case CMDGetData:
+			send(e.at, []simEvent{{from: e.to, to: e.from, kind: CMDTX, pulled: true}})
+		}
+	}
+	for i := range has {
+		require.True(t, has[i])
+	}
+	return received, duplicates, pulled
+}
+
+// TestTxRelayDuplicates compares the number of duplicate transactions received
+// in the simulated network when announcing to 2/3 of peers that fetch it from
+// every announcer (as it was done before) and with per-peer known sets, pushing
+// to square root of peers and fetching from a single announcer. Every node
+// fetches the transaction at most once now, pushed transactions still can be
+// duplicated as the pushing node doesn't know whether the peer got it from
+// someone else.
+func TestTxRelayDuplicates(t *testing.T) {
+	const (
+		nodes  = 200
+		degree = 8
+	)
+	r := rand.New(rand.NewSource(42))
+	links := make([]map[int]*simPeer, nodes)
+	for i := range links {
+		links[i] = make(map[int]*simPeer)
+	}
+	for i := range links {
+		for len(links[i]) < degree {
+			j := r.Intn(nodes)
+			if j != i {
+				links[i][j] = &simPeer{id: j}
+				links[j][i] = &simPeer{id: i}
+			}
+		}
+	}
+	neighbours := func(i int) []int {
+		res := make([]int, 0, len(links[i]))
+		for j := range links[i] {
+			res = append(res, j)
+		}
+		r.Shuffle(len(res), func(a, b int) { res[a], res[b] = res[b], res[a] })
+		return res
+	}
+	h := common.HexToHash("0x01")
+
+	oldReceived, oldDuplicates, oldPulled := simulateTxRelay(t, links, 1,
+		func(i, _ int) []simEvent {
+			ns := neighbours(i)
+			var res []simEvent
+			for _, j := range ns[:(2*len(ns)+2)/3] {
+				res = append(res, simEvent{from: i, to: j, kind: CMDInv})
+			}
+			return res
+		},
+		func(i, from int, has bool) []simEvent {
+			if has {
+				return nil
+			}
+			return []simEvent{{from: i, to: from, kind: CMDGetData}}
+		})
+
+	relays := make([]*txRelay, nodes)
+	for i := range relays {
+		relays[i] = newTxRelay()
+	}
+	newReceived, newDuplicates, newPulled := simulateTxRelay(t, links, 1,
+		func(i, from int) []simEvent {
+			if from >= 0 {
+				relays[i].addKnown(links[i][from], h)
+			}
+			var res []simEvent
+			push := txPushCount(len(links[i]))
+			for _, j := range neighbours(i) {
+				if relays[i].isKnown(links[i][j], h) {
+					continue
+				}
+				relays[i].addKnown(links[i][j], h)
+				kind := CMDInv
+				if push > 0 {
+					kind = CMDTX
+					push--
+				}
+				res = append(res, simEvent{from: i, to: j, kind: kind})
+			}
+			return res
+		},
+		func(i, from int, has bool) []simEvent {
+			relays[i].addKnown(links[i][from], h)
+			if has || len(relays[i].request(links[i][from], []common.Hash{h})) == 0 {
+				return nil
+			}
+			return []simEvent{{from: i, to: from, kind: CMDGetData}}
+		})
+
+	t.Logf("duplicates: before %d of %d (%.1f%%), after %d of %d (%.1f%%)",
+		oldDuplicates, oldReceived, 100*float64(oldDuplicates)/float64(oldReceived),
+		newDuplicates, newReceived, 100*float64(newDuplicates)/float64(newReceived))
+	require.Greater(t, oldPulled, nodes-1)
+	require.LessOrEqual(t, newPulled, nodes-1)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
@@ -28,6 +29,14 @@ func (c *Client) IsBlocked(address common.Address) (bool, error) {
 	return resp, nil
 }
 
+func (c *Client) IsDeployAllowed(address common.Address) (bool, error) {
+	resp := false
+	if err := c.performRequest("isdeployallowed", request.NewRawParams(address.String()), &resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
 func (c *Client) CalculateGas(tx *transaction.NeoTx) (uint64, error) {
 	b, err := tx.Bytes()
 	if err != nil {
@@ -227,26 +236,50 @@ func (c *Client) GetNextValidators() (keys.PublicKeys, error) {
 	return *resp, nil
 }
 
+// GetDesignatedByRole returns nodes designated for the role at the given index.
+func (c *Client) GetDesignatedByRole(role noderoles.Role, index uint32) (keys.PublicKeys, error) {
+	var (
+		params = request.NewRawParams(byte(role), index)
+		resp   = new(keys.PublicKeys)
+	)
+	if err := c.performRequest("getdesignatedbyrole", params, resp); err != nil {
+		return nil, err
+	}
+	return *resp, nil
+}
+
 // GetContractStateByHash queries contract information, according to the contract script hash.
 func (c *Client) GetContractStateByHash(hash common.Address) (*state.Contract, error) {
-	return c.getContractState(hash.String())
+	return c.getContract(hash.String())
 }
 
 // GetContractStateByAddressOrName queries contract information, according to the contract address or name.
 func (c *Client) GetContractStateByAddressOrName(addressOrName string) (*state.Contract, error) {
-	return c.getContractState(addressOrName)
+	return c.getContract(addressOrName)
 }
 
 // GetContractStateByID queries contract information, according to the contract ID.
 func (c *Client) GetContractStateByID(id int32) (*state.Contract, error) {
-	return c.getContractState(id)
+	return c.getContract(id)
 }
 
-// getContractState is an internal representation of GetContractStateBy* methods.
-func (c *Client) getContractState(param interface{}) (*state.Contract, error) {
+// GetContractStateWithMetadata queries contract information along with its
+// creator and metadata, according to the contract address or name.
+func (c *Client) GetContractStateWithMetadata(addressOrName string) (*result.ContractState, error) {
+	return c.getContractState(addressOrName)
+}
+
+// getContract is an internal representation of GetContractStateBy* methods.
+func (c *Client) getContract(param interface{}) (*state.Contract, error) {
+	resp, err := c.getContractState(param)
+	return &resp.Contract, err
+}
+
+// getContractState performs getcontractstate request.
+func (c *Client) getContractState(param interface{}) (*result.ContractState, error) {
 	var (
 		params = request.NewRawParams(param)
-		resp   = &state.Contract{}
+		resp   = &result.ContractState{}
 	)
 	if err := c.performRequest("getcontractstate", params, resp); err != nil {
 		return resp, err
@@ -254,6 +287,19 @@ func (c *Client) getContractState(param interface{}) (*state.Contract, error) {
 	return resp, nil
 }
 
+// FindContracts returns contracts with metadata names starting with the given
+// prefix.
+func (c *Client) FindContracts(name string) ([]*result.ContractState, error) {
+	var (
+		params = request.NewRawParams(name)
+		resp   = []*result.ContractState{}
+	)
+	if err := c.performRequest("findcontracts", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (c *Client) GetFeePerByte() (uint64, error) {
 	var (
 		params = request.NewRawParams()
@@ -604,6 +650,12 @@ func (c *Client) SubmitBlock(b block.Block) (common.Hash, error) {
 	return resp.Hash, nil
 }
 
+// SubmitRawOracleResponse submits raw oracle response to the oracle node.
+// Raw params are used to avoid excessive marshalling.
+func (c *Client) SubmitRawOracleResponse(ps request.RawParams) error {
+	return c.performRequest("submitoracleresponse", ps, new(bool))
+}
+
 // SignAndPushTx signs given transaction using given wif and cosigners and pushes
 // it to the chain. It returns a hash of the transaction and an error. If one of
 // the cosigners accounts is neither contract-based nor unlocked an error is
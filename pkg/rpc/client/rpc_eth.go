@@ -351,3 +351,47 @@ func (c *Client) Eth_GetLogs(filter *filters.LogFilter) ([]*types.Log, error) {
 	}
 	return resp, nil
 }
+
+func (c *Client) TxPool_Content() (*result.TxPool, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.TxPool)
+	)
+	if err := c.performRequest("txpool_content", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) TxPool_ContentFrom(address common.Address) (*result.TxPoolContentFrom, error) {
+	var (
+		params = request.NewRawParams(address)
+		resp   = new(result.TxPoolContentFrom)
+	)
+	if err := c.performRequest("txpool_contentFrom", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) TxPool_Inspect() (*result.TxPoolInspect, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.TxPoolInspect)
+	)
+	if err := c.performRequest("txpool_inspect", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) TxPool_Status() (*result.TxPoolStatus, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.TxPoolStatus)
+	)
+	if err := c.performRequest("txpool_status", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -30,6 +30,14 @@ var (
 	ErrValidationFailed = NewSubmitError(-504, "Block or transaction validation failed.")
 	// ErrPolicyFail represents SubmitError with code -505.
 	ErrPolicyFail = NewSubmitError(-505, "One of the Policy filters failed.")
+	// ErrReplaceUnderpriced represents SubmitError with code -506.
+	ErrReplaceUnderpriced = NewSubmitError(-506, "Replacement transaction underpriced.")
+	// ErrSenderLimit represents SubmitError with code -507.
+	ErrSenderLimit = NewSubmitError(-507, "Too many transactions from sender in the memory pool.")
+	// ErrQueueFull represents SubmitError with code -508.
+	ErrQueueFull = NewSubmitError(-508, "The memory pool queue is full and no more future nonce transactions can be sent.")
+	// ErrNonceTooLow represents SubmitError with code -509.
+	ErrNonceTooLow = NewSubmitError(-509, "Transaction nonce is too low.")
 	// ErrUnknown represents SubmitError with code -500.
 	ErrUnknown = NewSubmitError(-500, "Unknown error.")
 )
@@ -76,6 +84,12 @@ func NewInternalServerError(data string, cause error) *Error {
 	return NewError(-32603, http.StatusInternalServerError, "Internal error", data, cause)
 }
 
+// NewUnauthorizedError creates a new error with
+// code -32001.
+func NewUnauthorizedError(data string, cause error) *Error {
+	return NewError(-32001, http.StatusUnauthorized, "Unauthorized", data, cause)
+}
+
 // NewRPCError creates a new error with
 // code -100.
 func NewRPCError(message string, data string, cause error) *Error {
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"math/big"
 
-	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
@@ -19,6 +18,7 @@ type (
 		BlockHeight() uint32
 		GetHeaderHash(int) common.Hash
 		GetGasPrice() *big.Int
+		GetMaxBlockGas() uint64
 	}
 
 	transactionsObj struct {
@@ -51,7 +51,7 @@ type (
 )
 
 // NewBlock creates a new Block wrapper.
-func NewBlock(chain LedgerAux, b *block.Block, receipt *types.Receipt, sr *state.MPTRoot, miner common.Address, full bool, cfg config.ProtocolConfiguration) *Block {
+func NewBlock(chain LedgerAux, b *block.Block, receipt *types.Receipt, sr *state.MPTRoot, miner common.Address, full bool) *Block {
 	res := &Block{
 		Header: b.Header,
 		BlockMetadata: BlockMetadata{
@@ -59,7 +59,7 @@ func NewBlock(chain LedgerAux, b *block.Block, receipt *types.Receipt, sr *state
 			Size:      hexutil.Uint(io.GetVarSize(b)),
 			StateRoot: sr.Root,
 			GasUsed:   hexutil.Uint64(receipt.GasUsed),
-			GasLimit:  hexutil.Uint64(cfg.MaxBlockGas),
+			GasLimit:  hexutil.Uint64(chain.GetMaxBlockGas()),
 			Uncles:    []common.Hash{},
 		},
 		transactionsObj: transactionsObj{
@@ -0,0 +1,14 @@
+package result
+
+import (
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ContractState is a result of `getcontractstate` and `findcontracts` RPC
+// calls, it's a contract along with its creator and metadata if they're known.
+type ContractState struct {
+	state.Contract
+	Creator  *common.Address         `json:"creator,omitempty"`
+	Metadata *state.ContractMetadata `json:"metadata,omitempty"`
+}
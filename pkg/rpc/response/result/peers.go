@@ -20,6 +20,50 @@ type (
 		Address string `json:"address"`
 		Port    string `json:"port"`
 	}
+
+	// AddressBookEntry represents the peer address book entry in
+	// `admin_addressBook` RPC call. Times are unix timestamps in seconds,
+	// latency is in milliseconds.
+	AddressBookEntry struct {
+		Address     string `json:"address"`
+		FullNode    bool   `json:"fullnode"`
+		LastSeen    int64  `json:"lastseen"`
+		Latency     int64  `json:"latency"`
+		Score       int    `json:"score"`
+		BannedUntil int64  `json:"banneduntil,omitempty"`
+	}
+
+	// NodeInfo represents the node information in `admin_nodeInfo` RPC call.
+	NodeInfo struct {
+		ID              uint32   `json:"id"`
+		UserAgent       string   `json:"useragent"`
+		Address         string   `json:"address"`
+		Port            uint16   `json:"port"`
+		ChainID         uint64   `json:"chainid"`
+		Capabilities    []string `json:"capabilities"`
+		BlockHeight     uint32   `json:"blockheight"`
+		HeaderHeight    uint32   `json:"headerheight"`
+		Peers           int      `json:"peers"`
+		HandshakedPeers int      `json:"handshakedpeers"`
+		NodeKey         string   `json:"nodekey,omitempty"`
+	}
+
+	// PeerInfo represents the connected peer in `admin_peers` RPC call.
+	// Latency is in milliseconds, queues are the numbers of messages
+	// waiting to be sent.
+	PeerInfo struct {
+		Address      string   `json:"address"`
+		PeerAddress  string   `json:"peeraddress"`
+		ID           uint32   `json:"id"`
+		UserAgent    string   `json:"useragent"`
+		Capabilities []string `json:"capabilities"`
+		Height       uint32   `json:"height"`
+		Handshaked   bool     `json:"handshaked"`
+		Latency      int64    `json:"latency"`
+		SendQueue    int      `json:"sendqueue"`
+		P2PQueue     int      `json:"p2pqueue"`
+		HPQueue      int      `json:"hpqueue"`
+	}
 )
 
 // NewGetPeers creates a new GetPeers structure.
@@ -1,12 +1,15 @@
 package result
 
 import (
+	"fmt"
+
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
-type poolTx struct {
+// PoolTx is a mempool transaction representation compatible with geth txpool API.
+type PoolTx struct {
 	BlockHash        common.Hash     `json:"blockHash"`
 	BlockNumber      *hexutil.Big    `json:"blockNumber"`
 	From             common.Address  `json:"from"`
@@ -24,42 +27,115 @@ type poolTx struct {
 }
 
 type TxPool struct {
-	Pending map[string]map[uint64]poolTx `json:"pending"`
-	Queued  map[string]map[uint64]poolTx `json:"queued"`
+	Pending map[string]map[uint64]PoolTx `json:"pending"`
+	Queued  map[string]map[uint64]PoolTx `json:"queued"`
+}
+
+// TxPoolStatus is the number of pending and queued transactions in mempool.
+type TxPoolStatus struct {
+	Pending hexutil.Uint `json:"pending"`
+	Queued  hexutil.Uint `json:"queued"`
 }
 
-func NewTxPool(txes []*transaction.Transaction) TxPool {
+func NewTxPool(pending []*transaction.Transaction, queued []*transaction.Transaction) TxPool {
 	tp := TxPool{
-		Pending: make(map[string]map[uint64]poolTx),
-		Queued:  make(map[string]map[uint64]poolTx),
+		Pending: make(map[string]map[uint64]PoolTx),
+		Queued:  make(map[string]map[uint64]PoolTx),
+	}
+	addPoolTxes(tp.Pending, pending)
+	addPoolTxes(tp.Queued, queued)
+	return tp
+}
+
+// TxPoolInspect is a textual summary of pending and queued transactions.
+type TxPoolInspect struct {
+	Pending map[string]map[uint64]string `json:"pending"`
+	Queued  map[string]map[uint64]string `json:"queued"`
+}
+
+// TxPoolContentFrom is pending and queued transactions of a single sender.
+type TxPoolContentFrom struct {
+	Pending map[uint64]PoolTx `json:"pending"`
+	Queued  map[uint64]PoolTx `json:"queued"`
+}
+
+func NewTxPoolInspect(pending []*transaction.Transaction, queued []*transaction.Transaction) TxPoolInspect {
+	tp := TxPoolInspect{
+		Pending: make(map[string]map[uint64]string),
+		Queued:  make(map[string]map[uint64]string),
 	}
+	addInspectTxes(tp.Pending, pending)
+	addInspectTxes(tp.Queued, queued)
+	return tp
+}
+
+func NewTxPoolContentFrom(pending []*transaction.Transaction, queued []*transaction.Transaction) TxPoolContentFrom {
+	tp := TxPoolContentFrom{
+		Pending: make(map[uint64]PoolTx),
+		Queued:  make(map[uint64]PoolTx),
+	}
+	for _, tx := range pending {
+		tp.Pending[tx.Nonce()] = NewPoolTx(tx)
+	}
+	for _, tx := range queued {
+		tp.Queued[tx.Nonce()] = NewPoolTx(tx)
+	}
+	return tp
+}
+
+// NewPoolTx creates PoolTx from mempool transaction.
+func NewPoolTx(tx *transaction.Transaction) PoolTx {
+	pooltx := PoolTx{
+		BlockHash:        common.Hash{},
+		BlockNumber:      nil,
+		From:             tx.From(),
+		Gas:              hexutil.Uint64(tx.Gas()),
+		GasPrice:         hexutil.Big(*tx.GasPrice()),
+		Hash:             tx.Hash(),
+		Input:            tx.Data(),
+		Nonce:            hexutil.Uint64(tx.Nonce()),
+		To:               tx.To(),
+		TransactionIndex: nil,
+		Value:            hexutil.Big(*tx.Value()),
+	}
+	if tx.Type == transaction.EthTxType {
+		r, s, v := tx.EthTx.RawSignatureValues()
+		pooltx.R = hexutil.Big(*r)
+		pooltx.S = hexutil.Big(*s)
+		pooltx.V = hexutil.Big(*v)
+	}
+	return pooltx
+}
+
+func addPoolTxes(table map[string]map[uint64]PoolTx, txes []*transaction.Transaction) {
 	for _, tx := range txes {
 		from := tx.From().String()
-		pooltx := poolTx{
-			BlockHash:        common.Hash{},
-			BlockNumber:      nil,
-			From:             tx.From(),
-			Gas:              hexutil.Uint64(tx.Gas()),
-			GasPrice:         hexutil.Big(*tx.GasPrice()),
-			Hash:             tx.Hash(),
-			Input:            tx.Data(),
-			Nonce:            hexutil.Uint64(tx.Nonce()),
-			To:               tx.To(),
-			TransactionIndex: nil,
-			Value:            hexutil.Big(*tx.Value()),
-		}
-		if tx.Type == transaction.EthTxType {
-			r, s, v := tx.EthTx.RawSignatureValues()
-			pooltx.R = hexutil.Big(*r)
-			pooltx.S = hexutil.Big(*s)
-			pooltx.V = hexutil.Big(*v)
+		noncetable, ok := table[from]
+		if !ok {
+			noncetable = make(map[uint64]PoolTx)
+			table[from] = noncetable
 		}
-		noncetable, ok := tp.Pending[from]
+		noncetable[tx.Nonce()] = NewPoolTx(tx)
+	}
+}
+
+func addInspectTxes(table map[string]map[uint64]string, txes []*transaction.Transaction) {
+	for _, tx := range txes {
+		from := tx.From().String()
+		noncetable, ok := table[from]
 		if !ok {
-			noncetable = make(map[uint64]poolTx)
-			tp.Pending[from] = noncetable
+			noncetable = make(map[uint64]string)
+			table[from] = noncetable
 		}
-		noncetable[tx.Nonce()] = pooltx
+		noncetable[tx.Nonce()] = inspectTx(tx)
 	}
-	return tp
+}
+
+// inspectTx formats transaction summary the same way geth does.
+func inspectTx(tx *transaction.Transaction) string {
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().String()
+	}
+	return fmt.Sprintf("%s: %v wei + %v gas × %v wei", to, tx.Value(), tx.Gas(), tx.GasPrice())
 }
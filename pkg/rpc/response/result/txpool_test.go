@@ -2,21 +2,38 @@ package result
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestTxPool(t *testing.T) {
-	tp := NewTxPool(nil)
+	tp := NewTxPool(nil, nil)
 	b, err := json.Marshal(tp)
 	assert.NoError(t, err)
 	t.Log(string(b))
 }
 
 func TestPoolTx(t *testing.T) {
-	pt := poolTx{}
+	pt := PoolTx{}
 	b, err := json.Marshal(pt)
 	assert.NoError(t, err)
 	t.Log(string(b))
 }
+
+func TestTxPoolInspect(t *testing.T) {
+	tx := transaction.NewTx(&transaction.EthTx{
+		Transaction: *types.NewTx(&types.LegacyTx{
+			Nonce:    1,
+			GasPrice: big.NewInt(2),
+			Gas:      21000,
+			Value:    big.NewInt(3),
+		}),
+	})
+	tp := NewTxPoolInspect(nil, []*transaction.Transaction{tx})
+	assert.Equal(t, 0, len(tp.Pending))
+	assert.Equal(t, "contract creation: 3 wei + 21000 gas × 2 wei", tp.Queued[tx.From().String()][1])
+}
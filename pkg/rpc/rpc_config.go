@@ -10,6 +10,14 @@ type (
 		Address              string `yaml:"Address"`
 		Enabled              bool   `yaml:"Enabled"`
 		EnableCORSWorkaround bool   `yaml:"EnableCORSWorkaround"`
+		// EnableAdminAPI enables admin_* methods, they allow to control
+		// the node and must never be exposed publicly.
+		EnableAdminAPI bool `yaml:"EnableAdminAPI"`
+		// AdminAuthToken is required for admin_* method calls, they must be
+		// authorized with "Authorization: Bearer <token>" HTTP header
+		// (checked on upgrade for websocket connections). All admin calls
+		// are denied if it's empty.
+		AdminAuthToken string `yaml:"AdminAuthToken"`
 		// MaxGasInvoke is a maximum amount of gas which
 		// can be spent during RPC call.
 		MaxGasInvoke           uint64    `yaml:"MaxGasInvoke"`
@@ -18,14 +18,21 @@ func incCounter(name string) {
 
 func init() {
 	for call := range rpcHandlers {
-		ctr := prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Help:      fmt.Sprintf("Number of calls to %s rpc endpoint", call),
-				Name:      fmt.Sprintf("%s_called", call),
-				Namespace: "neo_go_evm",
-			},
-		)
-		prometheus.MustRegister(ctr)
-		rpcCounter[call] = ctr
+		registerCounter(call)
 	}
+	for call := range adminHandlers {
+		registerCounter(call)
+	}
+}
+
+func registerCounter(call string) {
+	ctr := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      fmt.Sprintf("Number of calls to %s rpc endpoint", call),
+			Name:      fmt.Sprintf("%s_called", call),
+			Namespace: "neo_go_evm",
+		},
+	)
+	prometheus.MustRegister(ctr)
+	rpcCounter[call] = ctr
 }
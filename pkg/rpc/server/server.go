@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -23,16 +24,21 @@ import (
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/filters"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mpt"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/storage"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/network/capability"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/request"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/response"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/response/result"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/oracle/broadcaster"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/util/logging"
 	"github.com/DigitalLabs-web3/neo-go-evm/pkg/wallet"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -42,6 +48,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type (
@@ -67,7 +74,14 @@ type (
 		notificationCh   chan *types.Log
 		transactionCh    chan *transaction.Transaction
 
-		accounts []*wallet.Account
+		accounts  []*wallet.Account
+		oracle    OracleHandler
+		logLevels *logging.Levels
+	}
+
+	// OracleHandler is the interface oracle service needs to provide for the Server.
+	OracleHandler interface {
+		AddResponse(pub *keys.PublicKey, id uint64, txSig []byte)
 	}
 )
 
@@ -139,7 +153,10 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	// -- end trace api
 
 	// -- start gether api
-	"txpool_content": (*Server).txpool_content,
+	"txpool_content":     (*Server).txpool_content,
+	"txpool_status":      (*Server).txpool_status,
+	"txpool_inspect":     (*Server).txpool_inspect,
+	"txpool_contentFrom": (*Server).txpool_contentFrom,
 	// -- end gether api
 
 	// -- start bridge api
@@ -149,6 +166,7 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	// -- start neo api
 	"getversion":           (*Server).getVersion,
 	"calculategas":         (*Server).calculateGas,
+	"findcontracts":        (*Server).findContracts,
 	"findstates":           (*Server).findStates,
 	"getbestblockhash":     (*Server).getBestBlockHash,
 	"getblock":             (*Server).getBlock,
@@ -160,6 +178,7 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	"getconsensusaddress":  (*Server).getConsensusAddress,
 	"getconnectioncount":   (*Server).getConnectionCount,
 	"getcontractstate":     (*Server).getContractState,
+	"getdesignatedbyrole":  (*Server).getDesignatedByRole,
 	"getfeeperbyte":        (*Server).getFeePerByte,
 	"getnativecontracts":   (*Server).getNativeContracts,
 	"getpeers":             (*Server).getPeers,
@@ -174,9 +193,24 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	"getvalidators":        (*Server).getValidators,
 	"getnextvalidators":    (*Server).getNextValidators,
 	"sendrawtransaction":   (*Server).sendrawtransaction,
+	"submitoracleresponse": (*Server).submitOracleResponse,
 	"validateaddress":      (*Server).validateAddress,
 	"verifyproof":          (*Server).verifyProof,
 	"isblocked":            (*Server).isBlocked,
+	"isdeployallowed":      (*Server).isDeployAllowed,
+}
+
+// adminHandlers are only available if admin API is enabled in configuration.
+var adminHandlers = map[string]func(*Server, request.Params) (interface{}, *response.Error){
+	"admin_backup":      (*Server).adminBackup,
+	"admin_addressBook": (*Server).adminAddressBook,
+	"admin_banPeer":     (*Server).adminBanPeer,
+	"admin_unbanPeer":   (*Server).adminUnbanPeer,
+	"admin_addPeer":     (*Server).adminAddPeer,
+	"admin_removePeer":  (*Server).adminRemovePeer,
+	"admin_nodeInfo":    (*Server).adminNodeInfo,
+	"admin_peers":       (*Server).adminPeers,
+	"admin_setLogLevel": (*Server).adminSetLogLevel,
 }
 
 var rpcWsHandlers = map[string]func(*Server, request.Params, *subscriber) (interface{}, *response.Error){
@@ -193,7 +227,7 @@ var invalidBlockHeightError = func(index int, height int) *response.Error {
 var upgrader = websocket.Upgrader{}
 
 // New creates a new Server struct.
-func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.Server, wall *config.Wallet, log *zap.Logger) Server {
+func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.Server, orc OracleHandler, wall *config.Wallet, log *zap.Logger, levels *logging.Levels) Server {
 	httpServer := &http.Server{
 		Addr: conf.Address + ":" + strconv.FormatUint(uint64(conf.Port), 10),
 	}
@@ -222,7 +256,9 @@ func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.S
 		notificationCh: make(chan *types.Log),
 		transactionCh:  make(chan *transaction.Transaction),
 
-		accounts: getAccounts(wall),
+		accounts:  getAccounts(wall),
+		oracle:    orc,
+		logLevels: levels,
 	}
 }
 
@@ -264,6 +300,9 @@ func (s *Server) Start(errChan chan error) {
 	}
 	s.Handler = http.HandlerFunc(s.handleHTTPRequest)
 	s.log.Info("starting rpc-server", zap.String("endpoint", s.Addr))
+	if s.config.EnableAdminAPI && s.config.AdminAuthToken == "" {
+		s.log.Warn("admin API is enabled, but AdminAuthToken is not set, admin methods are denied")
+	}
 
 	go s.handleSubEvents()
 	if cfg := s.config.TLSConfig; cfg.Enabled {
@@ -352,7 +391,7 @@ func (s *Server) handleHTTPRequest(w http.ResponseWriter, httpRequest *http.Requ
 		}
 		resChan := make(chan response.AbstractResult) // response.Abstract or response.AbstractBatch
 		subChan := make(chan *websocket.PreparedMessage, notificationBufSize)
-		subscr := &subscriber{writer: subChan, ws: ws}
+		subscr := &subscriber{writer: subChan, ws: ws, admin: s.isAdminAllowed(httpRequest)}
 		s.subsLock.Lock()
 		s.subscribers[subscr] = true
 		s.subsLock.Unlock()
@@ -378,22 +417,36 @@ func (s *Server) handleHTTPRequest(w http.ResponseWriter, httpRequest *http.Requ
 		return
 	}
 
-	resp := s.handleRequest(req, nil)
+	resp := s.handleRequest(req, nil, s.isAdminAllowed(httpRequest))
 	s.writeHTTPServerResponse(req, w, resp)
 }
 
-func (s *Server) handleRequest(req *request.Request, sub *subscriber) response.AbstractResult {
+// isAdminAllowed checks whether admin_* methods can be called by the given
+// HTTP request. They're never allowed without AdminAuthToken configured.
+func (s *Server) isAdminAllowed(httpRequest *http.Request) bool {
+	if !s.config.EnableAdminAPI || s.config.AdminAuthToken == "" {
+		return false
+	}
+	auth := httpRequest.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminAuthToken)) == 1
+}
+
+func (s *Server) handleRequest(req *request.Request, sub *subscriber, admin bool) response.AbstractResult {
 	if req.In != nil {
-		return s.handleIn(req.In, sub)
+		return s.handleIn(req.In, sub, admin)
 	}
 	resp := make(response.AbstractBatch, len(req.Batch))
 	for i, in := range req.Batch {
-		resp[i] = s.handleIn(&in, sub)
+		resp[i] = s.handleIn(&in, sub, admin)
 	}
 	return resp
 }
 
-func (s *Server) handleIn(req *request.In, sub *subscriber) response.Abstract {
+func (s *Server) handleIn(req *request.In, sub *subscriber, admin bool) response.Abstract {
 	var res interface{}
 	var resErr *response.Error
 	if req.JSONRPC != request.JSONRPCVersion {
@@ -410,6 +463,12 @@ func (s *Server) handleIn(req *request.In, sub *subscriber) response.Abstract {
 
 	resErr = response.NewMethodNotFoundError(fmt.Sprintf("Method '%s' not supported", req.Method), nil)
 	handler, ok := rpcHandlers[req.Method]
+	if !ok && s.config.EnableAdminAPI {
+		handler, ok = adminHandlers[req.Method]
+		if ok && !admin {
+			return s.packResponse(req, nil, response.NewUnauthorizedError("admin API authorization required", nil))
+		}
+	}
 	if ok {
 		res, resErr = handler(s, reqParams)
 	} else if sub != nil {
@@ -485,7 +544,7 @@ requestloop:
 		if err != nil {
 			break
 		}
-		res := s.handleRequest(req, subscr)
+		res := s.handleRequest(req, subscr, subscr.admin)
 		res.RunForErrors(func(jsonErr *response.Error) {
 			s.logRequestError(req, jsonErr)
 		})
@@ -595,6 +654,9 @@ func (s *Server) eth_getBalance(params request.Params) (interface{}, *response.E
 	if err != nil {
 		return nil, response.NewInvalidParamsError(err.Error(), err)
 	}
+	if s.isLightNode() {
+		return s.lightGetBalance(addr)
+	}
 	balance := s.chain.GetUtilityTokenBalance(addr)
 	return hexutil.EncodeBig(balance), nil
 }
@@ -617,10 +679,59 @@ func (s *Server) eth_getStorageAt(params request.Params) (interface{}, *response
 		return nil, response.ErrInvalidParams
 	}
 	hashKey := common.HexToHash(key)
-	si := s.chain.GetStorageItem(addr, hashKey.Bytes())
+	var si []byte
+	if s.isLightNode() {
+		var respErr *response.Error
+		si, respErr = s.lightGetStorageItem(addr, hashKey.Bytes())
+		if respErr != nil {
+			return nil, respErr
+		}
+	} else {
+		si = s.chain.GetStorageItem(addr, hashKey.Bytes())
+	}
 	return hexutil.Encode(common.BytesToHash(si).Bytes()), nil
 }
 
+// isLightNode checks whether the state is to be requested from peers.
+func (s *Server) isLightNode() bool {
+	return s.coreServer != nil && s.coreServer.IsLightNode()
+}
+
+// lightGetStorageItem returns the storage item at the latest state validated
+// by the light node, nil is returned for the missing item.
+func (s *Server) lightGetStorageItem(contract common.Address, key []byte) ([]byte, *response.Error) {
+	sm := s.chain.GetStateModule()
+	sr, err := sm.GetStateRoot(sm.CurrentValidatedHeight())
+	if err != nil || len(sr.Witness.VerificationScript) == 0 {
+		return nil, response.NewInternalServerError("no validated state root yet", err)
+	}
+	si, err := s.coreServer.GetVerifiedState(sr.Root, contract, key)
+	if errors.Is(err, mpt.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to get verified state", err)
+	}
+	return si, nil
+}
+
+// lightGetBalance returns GAS balance at the latest state validated by the
+// light node.
+func (s *Server) lightGetBalance(addr common.Address) (interface{}, *response.Error) {
+	si, respErr := s.lightGetStorageItem(native.GASAddress, native.MakeAccountKey(addr))
+	if respErr != nil {
+		return nil, respErr
+	}
+	if si == nil {
+		return hexutil.EncodeBig(big.NewInt(0)), nil
+	}
+	acc := new(native.GasState)
+	if err := io.FromByteArray(acc, si); err != nil {
+		return nil, response.NewInternalServerError("invalid balance state", err)
+	}
+	return hexutil.EncodeBig(acc.Balance), nil
+}
+
 func (s *Server) eth_getTransactionCount(params request.Params) (interface{}, *response.Error) {
 	param := params.Value(0)
 	if param == nil {
@@ -1096,7 +1207,7 @@ func (s *Server) eth_getBlock(hash common.Hash, full bool) (*result.Block, *resp
 	if err != nil {
 		return nil, response.NewInternalServerError("can't get validators", err)
 	}
-	return result.NewBlock(s.chain, block, receipt, sr, validators[block.PrimaryIndex].Address(), full, s.chain.GetConfig()), nil
+	return result.NewBlock(s.chain, block, receipt, sr, validators[block.PrimaryIndex].Address(), full), nil
 }
 
 func (s *Server) eth_getTransactionByHash(params request.Params) (interface{}, *response.Error) {
@@ -1318,7 +1429,220 @@ func (s *Server) trace_call(params request.Params) (interface{}, *response.Error
 
 func (s *Server) txpool_content(_ request.Params) (interface{}, *response.Error) {
 	mempool := s.chain.GetMemPool()
-	return result.NewTxPool(mempool.GetVerifiedTransactions()), nil
+	return result.NewTxPool(mempool.GetVerifiedTransactions(), mempool.GetQueuedTransactions()), nil
+}
+
+func (s *Server) txpool_status(_ request.Params) (interface{}, *response.Error) {
+	mempool := s.chain.GetMemPool()
+	return result.TxPoolStatus{
+		Pending: hexutil.Uint(mempool.Count()),
+		Queued:  hexutil.Uint(mempool.QueuedCount()),
+	}, nil
+}
+
+// adminBackup writes a backup of the node database to the specified path on
+// the node side.
+func (s *Server) adminBackup(reqParams request.Params) (interface{}, *response.Error) {
+	path, err := reqParams.Value(0).GetString()
+	if err != nil || path == "" {
+		return nil, response.ErrInvalidParams
+	}
+	m, err := s.chain.Backup(path)
+	if err != nil {
+		return nil, response.NewInternalServerError("can't create backup", err)
+	}
+	return m, nil
+}
+
+// adminAddressBook returns all known peers along with their misbehaviour
+// scores and bans.
+func (s *Server) adminAddressBook(_ request.Params) (interface{}, *response.Error) {
+	list := s.coreServer.AddressBook()
+	res := make([]result.AddressBookEntry, 0, len(list))
+	for _, info := range list {
+		e := result.AddressBookEntry{
+			Address: info.Address,
+			Latency: info.Latency.Milliseconds(),
+			Score:   info.Score,
+		}
+		for _, c := range info.Capabilities {
+			if c.Type == capability.FullNode {
+				e.FullNode = true
+			}
+		}
+		if !info.LastSeen.IsZero() {
+			e.LastSeen = info.LastSeen.Unix()
+		}
+		if !info.BannedUntil.IsZero() {
+			e.BannedUntil = info.BannedUntil.Unix()
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+// adminBanPeer bans peer host for the given number of seconds (the default
+// ban duration is used if it's not specified) and disconnects it.
+func (s *Server) adminBanPeer(reqParams request.Params) (interface{}, *response.Error) {
+	addr, err := reqParams.Value(0).GetString()
+	if err != nil || addr == "" {
+		return nil, response.ErrInvalidParams
+	}
+	var d time.Duration
+	if len(reqParams) > 1 {
+		secs, err := reqParams.Value(1).GetInt()
+		if err != nil || secs < 0 {
+			return nil, response.NewInvalidParamsError("invalid ban duration", err)
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	s.coreServer.BanPeer(addr, d)
+	return true, nil
+}
+
+// adminUnbanPeer removes peer host ban, it returns false if the host wasn't
+// banned.
+func (s *Server) adminUnbanPeer(reqParams request.Params) (interface{}, *response.Error) {
+	addr, err := reqParams.Value(0).GetString()
+	if err != nil || addr == "" {
+		return nil, response.ErrInvalidParams
+	}
+	return s.coreServer.UnbanPeer(addr), nil
+}
+
+// adminAddPeer connects to the peer with the given address.
+func (s *Server) adminAddPeer(reqParams request.Params) (interface{}, *response.Error) {
+	addr, err := reqParams.Value(0).GetString()
+	if err != nil || addr == "" {
+		return nil, response.ErrInvalidParams
+	}
+	if err := s.coreServer.AddPeer(addr); err != nil {
+		return nil, response.NewRPCError("can't add peer", err.Error(), err)
+	}
+	return true, nil
+}
+
+// adminRemovePeer disconnects the peer with the given address, it returns
+// false if there is no such peer.
+func (s *Server) adminRemovePeer(reqParams request.Params) (interface{}, *response.Error) {
+	addr, err := reqParams.Value(0).GetString()
+	if err != nil || addr == "" {
+		return nil, response.ErrInvalidParams
+	}
+	return s.coreServer.RemovePeer(addr), nil
+}
+
+// adminNodeInfo returns information about the node.
+func (s *Server) adminNodeInfo(_ request.Params) (interface{}, *response.Error) {
+	info, err := s.coreServer.NodeInfo()
+	if err != nil {
+		return nil, response.NewInternalServerError("can't get node info", err)
+	}
+	return result.NodeInfo{
+		ID:              info.ID,
+		UserAgent:       info.UserAgent,
+		Address:         info.Address,
+		Port:            info.Port,
+		ChainID:         info.ChainID,
+		Capabilities:    capabilityNames(info.Capabilities),
+		BlockHeight:     info.BlockHeight,
+		HeaderHeight:    info.HeaderHeight,
+		Peers:           info.Peers,
+		HandshakedPeers: info.HandshakedPeers,
+		NodeKey:         info.NodeKey,
+	}, nil
+}
+
+// adminPeers returns detailed information about connected peers.
+func (s *Server) adminPeers(_ request.Params) (interface{}, *response.Error) {
+	peers := s.coreServer.PeersInfo()
+	res := make([]result.PeerInfo, 0, len(peers))
+	for _, p := range peers {
+		res = append(res, result.PeerInfo{
+			Address:      p.Address,
+			PeerAddress:  p.PeerAddress,
+			ID:           p.ID,
+			UserAgent:    p.UserAgent,
+			Capabilities: capabilityNames(p.Capabilities),
+			Height:       p.Height,
+			Handshaked:   p.Handshaked,
+			Latency:      p.Stats.Latency.Milliseconds(),
+			SendQueue:    p.Stats.SendQueue,
+			P2PQueue:     p.Stats.P2PQueue,
+			HPQueue:      p.Stats.HPQueue,
+		})
+	}
+	return res, nil
+}
+
+// capabilityNames returns the names of the given capabilities.
+func capabilityNames(caps capability.Capabilities) []string {
+	res := make([]string, 0, len(caps))
+	for _, c := range caps {
+		switch c.Type {
+		case capability.TCPServer:
+			res = append(res, "tcpserver")
+		case capability.WSServer:
+			res = append(res, "wsserver")
+		case capability.FullNode:
+			res = append(res, "fullnode")
+		case capability.CompactBlocks:
+			res = append(res, "compactblocks")
+		default:
+			res = append(res, strconv.Itoa(int(c.Type)))
+		}
+	}
+	return res
+}
+
+// adminSetLogLevel sets the logging level for the given module (the default
+// one if the module isn't specified), it returns all configured levels.
+func (s *Server) adminSetLogLevel(reqParams request.Params) (interface{}, *response.Error) {
+	if s.logLevels == nil {
+		return nil, response.NewInternalServerError("runtime log levels are not supported", nil)
+	}
+	lvlStr, err := reqParams.Value(0).GetString()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(lvlStr)); err != nil {
+		return nil, response.NewInvalidParamsError("invalid log level", err)
+	}
+	var module string
+	if len(reqParams) > 1 {
+		module, err = reqParams.Value(1).GetString()
+		if err != nil {
+			return nil, response.NewInvalidParamsError("invalid module", err)
+		}
+	}
+	s.logLevels.SetLevel(module, lvl)
+	levels := s.logLevels.List()
+	res := make(map[string]string, len(levels))
+	for m, l := range levels {
+		if m == "" {
+			m = "default"
+		}
+		res[m] = l.String()
+	}
+	return res, nil
+}
+
+func (s *Server) txpool_inspect(_ request.Params) (interface{}, *response.Error) {
+	mempool := s.chain.GetMemPool()
+	return result.NewTxPoolInspect(mempool.GetVerifiedTransactions(), mempool.GetQueuedTransactions()), nil
+}
+
+func (s *Server) txpool_contentFrom(reqParams request.Params) (interface{}, *response.Error) {
+	para1 := reqParams.Value(0)
+	if para1 == nil {
+		return nil, response.ErrInvalidParams
+	}
+	addr, err := para1.GetAddressFromHex()
+	if err != nil {
+		return nil, response.NewInvalidParamsError("invalid address", err)
+	}
+	return result.NewTxPoolContentFrom(s.chain.GetMemPool().GetSenderTransactions(addr)), nil
 }
 
 // -- end gether api
@@ -1426,7 +1750,7 @@ func (s *Server) getVersion(_ request.Params) (interface{}, *response.Error) {
 			ChainID:                   cfg.ChainID,
 			MillisecondsPerBlock:      cfg.SecondsPerBlock * 1000,
 			MaxTraceableBlocks:        cfg.MaxTraceableBlocks,
-			MaxTransactionsPerBlock:   cfg.MaxTransactionsPerBlock,
+			MaxTransactionsPerBlock:   s.chain.GetMaxTransactionsPerBlock(),
 			MemoryPoolMaxTransactions: cfg.MemPoolSize,
 			ValidatorsCount:           byte(len(validators)),
 			InitialGasDistribution:    cfg.InitialGASSupply,
@@ -1639,8 +1963,7 @@ func (s *Server) getState(ps request.Params) (interface{}, *response.Error) {
 	if respErr != nil {
 		return nil, respErr
 	}
-	sKey := makeStorageKey(cs.Address, key)
-	res, err := s.chain.GetStateModule().GetState(root, sKey)
+	res, err := s.getStateItem(root, cs.Address, key)
 	if err != nil {
 		return nil, response.NewInternalServerError("failed to get historical item state", err)
 	}
@@ -1742,8 +2065,7 @@ func (s *Server) findStates(ps request.Params) (interface{}, *response.Error) {
 }
 
 func (s *Server) getHistoricalContractState(root common.Hash, csHash common.Address) (*state.Contract, *response.Error) {
-	csKey := makeStorageKey(native.ManagementAddress, native.MakeContractKey(csHash))
-	csBytes, err := s.chain.GetStateModule().GetState(root, csKey)
+	csBytes, err := s.getStateItem(root, native.ManagementAddress, native.MakeContractKey(csHash))
 	if err != nil {
 		return nil, response.NewInternalServerError("failed to get historical contract state", err)
 	}
@@ -1752,6 +2074,15 @@ func (s *Server) getHistoricalContractState(root common.Hash, csHash common.Addr
 	return contract, response.NewRPCError("Failed get contract state", "", err)
 }
 
+// getStateItem returns the contract storage item at the state with the
+// specified root, light node gets it from peers with the proof.
+func (s *Server) getStateItem(root common.Hash, contract common.Address, key []byte) ([]byte, error) {
+	if s.isLightNode() {
+		return s.coreServer.GetVerifiedState(root, contract, key)
+	}
+	return s.chain.GetStateModule().GetState(root, makeStorageKey(contract, key))
+}
+
 func (s *Server) getStateHeight(_ request.Params) (interface{}, *response.Error) {
 	var height = s.chain.BlockHeight()
 	var stateHeight = s.chain.GetStateModule().CurrentValidatedHeight()
@@ -1858,13 +2189,47 @@ func (s *Server) getContractState(reqParams request.Params) (interface{}, *respo
 	if err != nil {
 		return nil, err
 	}
-	cs := s.chain.GetContractState(scriptHash)
+	cs := s.getContractInfo(scriptHash)
 	if cs == nil {
 		return nil, response.NewRPCError("Unknown contract", "", nil)
 	}
 	return cs, nil
 }
 
+func (s *Server) getContractInfo(hash common.Address) *result.ContractState {
+	cs := s.chain.GetContractState(hash)
+	if cs == nil {
+		return nil
+	}
+	res := &result.ContractState{
+		Contract: *cs,
+		Metadata: s.chain.GetContractMetadata(hash),
+	}
+	if creator, ok := s.chain.GetContractCreator(hash); ok {
+		res.Creator = &creator
+	}
+	return res
+}
+
+func (s *Server) findContracts(reqParams request.Params) (interface{}, *response.Error) {
+	param := reqParams.Value(0)
+	if param == nil {
+		return nil, response.ErrInvalidParams
+	}
+	name, err := param.GetString()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, err)
+	}
+	addrs := s.chain.FindContracts(name)
+	res := make([]*result.ContractState, 0, len(addrs))
+	for _, addr := range addrs {
+		if cs := s.getContractInfo(addr); cs != nil {
+			res = append(res, cs)
+		}
+	}
+	return res, nil
+}
+
 func (s *Server) getFeePerByte(_ request.Params) (interface{}, *response.Error) {
 	return s.chain.GetFeePerByte(), nil
 }
@@ -1950,6 +2315,30 @@ func (s *Server) getValidators(params request.Params) (interface{}, *response.Er
 	return validators, nil
 }
 
+func (s *Server) getDesignatedByRole(params request.Params) (interface{}, *response.Error) {
+	p := params.Value(0)
+	if p == nil {
+		return nil, response.ErrInvalidParams
+	}
+	role, err := p.GetIntStrict()
+	if err != nil || role < 0 || role > math.MaxUint8 || !noderoles.IsValid(noderoles.Role(role)) {
+		return nil, response.NewInvalidParamsError("invalid role", err)
+	}
+	index := s.chain.BlockHeight() + 1
+	if p = params.Value(1); p != nil {
+		i, err := p.GetIntStrict()
+		if err != nil || i < 0 {
+			return nil, response.NewInvalidParamsError("invalid index", err)
+		}
+		index = uint32(i)
+	}
+	nodes, _, err := s.chain.GetDesignatedByRole(noderoles.Role(role), index)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to get designated nodes", err)
+	}
+	return nodes, nil
+}
+
 func (s *Server) getConsensusAddress(_ request.Params) (interface{}, *response.Error) {
 	addr, err := s.chain.GetConsensusAddress()
 	if err != nil {
@@ -1971,6 +2360,51 @@ func (s *Server) isBlocked(reqParams request.Params) (interface{}, *response.Err
 	return r, nil
 }
 
+func (s *Server) submitOracleResponse(ps request.Params) (interface{}, *response.Error) {
+	if s.oracle == nil {
+		return nil, response.NewInternalServerError("oracle is not enabled", nil)
+	}
+	pubBytes, err := ps.Value(0).GetBytesHex()
+	if err != nil {
+		return nil, response.NewInvalidParamsError("public key is missing", err)
+	}
+	pub := new(keys.PublicKey)
+	if err := pub.DecodeBytes(pubBytes); err != nil {
+		return nil, response.NewInvalidParamsError("public key is invalid", err)
+	}
+	reqID, err := ps.Value(1).GetIntStrict()
+	if err != nil || reqID < 0 {
+		return nil, response.NewInvalidParamsError("request ID is invalid", err)
+	}
+	txSig, err := ps.Value(2).GetBytesHex()
+	if err != nil {
+		return nil, response.NewInvalidParamsError("tx signature is missing", err)
+	}
+	msgSig, err := ps.Value(3).GetBytesHex()
+	if err != nil {
+		return nil, response.NewInvalidParamsError("msg signature is missing", err)
+	}
+	data := broadcaster.GetMessage(pubBytes, uint64(reqID), txSig)
+	h := hash.Sha256(data)
+	if !pub.Verify(msgSig, h[:]) {
+		return nil, response.NewRPCError("Invalid sign", "invalid request signature", nil)
+	}
+	s.oracle.AddResponse(pub, uint64(reqID), txSig)
+	return true, nil
+}
+
+func (s *Server) isDeployAllowed(reqParams request.Params) (interface{}, *response.Error) {
+	para1 := reqParams.Value(0)
+	if para1 == nil {
+		return nil, response.ErrInvalidParams
+	}
+	addr, err := para1.GetAddressFromHex()
+	if err != nil {
+		return nil, response.NewInvalidParamsError("invalid address", err)
+	}
+	return s.chain.IsDeployAllowed(addr), nil
+}
+
 // getRelayResult returns successful relay result or an error.
 func getRelayResult(err error, hash common.Hash) (interface{}, *response.Error) {
 	switch {
@@ -1982,6 +2416,14 @@ func getRelayResult(err error, hash common.Hash) (interface{}, *response.Error)
 		return nil, response.WrapErrorWithData(response.ErrOutOfMemory, err)
 	case errors.Is(err, core.ErrPolicy):
 		return nil, response.WrapErrorWithData(response.ErrPolicyFail, err)
+	case errors.Is(err, core.ErrReplaceUnderpriced):
+		return nil, response.WrapErrorWithData(response.ErrReplaceUnderpriced, err)
+	case errors.Is(err, core.ErrSenderLimit):
+		return nil, response.WrapErrorWithData(response.ErrSenderLimit, err)
+	case errors.Is(err, core.ErrQueueFull):
+		return nil, response.WrapErrorWithData(response.ErrQueueFull, err)
+	case errors.Is(err, core.ErrNonceTooLow):
+		return nil, response.WrapErrorWithData(response.ErrNonceTooLow, err)
 	default:
 		return nil, response.WrapErrorWithData(response.ErrValidationFailed, err)
 	}
@@ -1 +1,80 @@
 package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/request"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/response"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetDesignatedByRoleInvalid(t *testing.T) {
+	s := &Server{}
+	for _, role := range []string{"-1", "5", "256", "258"} {
+		_, respErr := s.getDesignatedByRole(request.Params{{RawMessage: json.RawMessage(role)}})
+		require.NotNil(t, respErr, role)
+		require.Equal(t, int64(-32602), respErr.Code, role)
+	}
+}
+
+func TestIsDeployAllowedInvalidAddress(t *testing.T) {
+	s := &Server{}
+	_, respErr := s.isDeployAllowed(request.Params{{RawMessage: json.RawMessage(`"0x1234"`)}})
+	require.NotNil(t, respErr)
+	require.Equal(t, int64(-32602), respErr.Code)
+}
+
+func TestGetRelayResultQueueErrors(t *testing.T) {
+	for err, expected := range map[error]*response.Error{
+		core.ErrQueueFull:   response.ErrQueueFull,
+		core.ErrNonceTooLow: response.ErrNonceTooLow,
+	} {
+		_, respErr := getRelayResult(fmt.Errorf("mempool: %w", err), common.Hash{})
+		require.NotNil(t, respErr)
+		require.Equal(t, expected.Code, respErr.Code)
+	}
+}
+
+func TestAdminAPIGating(t *testing.T) {
+	newReq := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return r
+	}
+	s := &Server{log: zap.NewNop()}
+	require.False(t, s.isAdminAllowed(newReq("")))
+	require.False(t, s.isAdminAllowed(newReq("secret")))
+	s.config.AdminAuthToken = "secret"
+	require.False(t, s.isAdminAllowed(newReq("secret")))
+
+	s.config.EnableAdminAPI = true
+	require.True(t, s.isAdminAllowed(newReq("secret")))
+	require.False(t, s.isAdminAllowed(newReq("")))
+	require.False(t, s.isAdminAllowed(newReq("bad")))
+	r := newReq("")
+	r.Header.Set("Authorization", "secret")
+	require.False(t, s.isAdminAllowed(r))
+
+	// Admin API can't be used without token.
+	s.config.AdminAuthToken = ""
+	require.False(t, s.isAdminAllowed(newReq("")))
+	require.False(t, s.isAdminAllowed(newReq("secret")))
+
+	call := func(admin bool) *response.Error {
+		resp := s.handleIn(&request.In{JSONRPC: request.JSONRPCVersion, Method: "admin_nodeInfo", RawID: json.RawMessage("1")}, nil, admin)
+		return resp.Error
+	}
+	s.config.EnableAdminAPI = false
+	require.Equal(t, int64(-32601), call(true).Code)
+	s.config.EnableAdminAPI = true
+	require.Equal(t, int64(-32001), call(false).Code)
+}
@@ -16,6 +16,8 @@ type (
 		writer    chan<- *websocket.PreparedMessage
 		ws        *websocket.Conn
 		overflown atomic.Bool
+		// admin is true if admin_* methods are allowed for the subscriber.
+		admin bool
 		// These work like slots as there is not a lot of them (it's
 		// cheaper doing it this way rather than creating a map),
 		// pointing to EventID is an obvious overkill at the moment, but
@@ -0,0 +1,61 @@
+package broadcaster
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/client"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/rpc/request"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/helpers/rpcbroadcaster"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+type oracleBroadcaster struct {
+	rpcbroadcaster.RPCBroadcaster
+}
+
+const (
+	defaultSendTimeout = time.Second * 4
+
+	defaultChanCapacity = 16
+)
+
+// New returns new struct capable of broadcasting oracle responses.
+func New(cfg config.OracleConfiguration, log *zap.Logger) *oracleBroadcaster {
+	if cfg.RequestTimeout == 0 {
+		cfg.RequestTimeout = defaultSendTimeout
+	}
+	r := &oracleBroadcaster{
+		RPCBroadcaster: *rpcbroadcaster.NewRPCBroadcaster(log, cfg.RequestTimeout),
+	}
+	for i := range cfg.Nodes {
+		r.Clients[cfg.Nodes[i]] = r.NewRPCClient(cfg.Nodes[i], (*client.Client).SubmitRawOracleResponse,
+			cfg.RequestTimeout, make(chan request.RawParams, defaultChanCapacity))
+	}
+	return r
+}
+
+// SendResponse implements oracle.Broadcaster interface.
+func (r *oracleBroadcaster) SendResponse(priv *keys.PrivateKey, id uint64, txSig []byte) {
+	pub := priv.PublicKey().Bytes()
+	msgSig := priv.Sign(GetMessage(pub, id, txSig))
+	params := request.NewRawParams(
+		hexutil.Encode(pub),
+		id,
+		hexutil.Encode(txSig),
+		hexutil.Encode(msgSig),
+	)
+	r.Responses <- params
+}
+
+// GetMessage returns data which is signed upon sending response by RPC.
+func GetMessage(pubBytes []byte, id uint64, txSig []byte) []byte {
+	data := make([]byte, len(pubBytes)+8+len(txSig))
+	copy(data, pubBytes)
+	binary.LittleEndian.PutUint64(data[len(pubBytes):], id)
+	copy(data[len(pubBytes)+8:], txSig)
+	return data
+}
@@ -0,0 +1,38 @@
+package oracle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/services/oracle/jsonpath"
+)
+
+func filter(value []byte, path string) ([]byte, error) {
+	if !utf8.Valid(value) {
+		return nil, errors.New("not an UTF-8")
+	}
+
+	d := json.NewDecoder(bytes.NewReader(value))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	result, ok := jsonpath.Get(path, v)
+	if !ok {
+		return nil, errors.New("invalid filter")
+	}
+	return json.Marshal(result)
+}
+
+func filterRequest(result []byte, req *state.OracleRequest) ([]byte, error) {
+	if req.Filter != "" {
+		return filter(result, req.Filter)
+	}
+	return result, nil
+}
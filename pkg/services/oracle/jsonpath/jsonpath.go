@@ -0,0 +1,268 @@
+// Package jsonpath implements a subset of JSONPath used by oracle filters.
+// Supported selectors are `$`, `.name`, `.*`, `..name`, `..*`, `[n]`,
+// `[n,m]`, `['a','b']`, `[*]` and `[start:end]`. Values are expected in the
+// form produced by encoding/json (map[string]interface{} and []interface{}).
+package jsonpath
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxNestingDepth = 6
+	maxObjects      = 1024
+)
+
+type pathParser struct {
+	s string
+	i int
+}
+
+// Get returns substructures of value selected by path. Object members are
+// traversed in the order of sorted keys, so the result is deterministic.
+func Get(path string, value interface{}) ([]interface{}, bool) {
+	if path == "" {
+		return []interface{}{value}, true
+	}
+	if path[0] != '$' {
+		return nil, false
+	}
+	p := &pathParser{s: path, i: 1}
+	objs := []interface{}{value}
+	for p.i < len(p.s) {
+		var ok bool
+		switch p.s[p.i] {
+		case '.':
+			objs, ok = p.processDot(objs)
+		case '[':
+			objs, ok = p.processBracket(objs)
+		default:
+			return nil, false
+		}
+		if !ok || len(objs) > maxObjects {
+			return nil, false
+		}
+	}
+	return objs, true
+}
+
+func (p *pathParser) processDot(objs []interface{}) ([]interface{}, bool) {
+	p.i++
+	recursive := false
+	if p.i < len(p.s) && p.s[p.i] == '.' {
+		recursive = true
+		p.i++
+	}
+	if p.i >= len(p.s) {
+		return nil, false
+	}
+	var name string
+	if p.s[p.i] == '*' {
+		p.i++
+		name = "*"
+	} else {
+		var ok bool
+		name, ok = p.parseIdent()
+		if !ok {
+			return nil, false
+		}
+	}
+	if recursive {
+		var res []interface{}
+		for _, obj := range objs {
+			var ok bool
+			res, ok = descendRecursive(res, obj, name, maxNestingDepth)
+			if !ok {
+				return nil, false
+			}
+		}
+		return res, true
+	}
+	if name == "*" {
+		return children(objs), true
+	}
+	return byNames(objs, name), true
+}
+
+func (p *pathParser) parseIdent() (string, bool) {
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] != '.' && p.s[p.i] != '[' {
+		c := p.s[p.i]
+		if !(c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return "", false
+		}
+		p.i++
+	}
+	return p.s[start:p.i], p.i > start
+}
+
+func (p *pathParser) processBracket(objs []interface{}) ([]interface{}, bool) {
+	end := strings.IndexByte(p.s[p.i:], ']')
+	if end < 0 {
+		return nil, false
+	}
+	inner := strings.TrimSpace(p.s[p.i+1 : p.i+end])
+	p.i += end + 1
+	if inner == "*" {
+		return children(objs), true
+	}
+	if len(inner) == 0 {
+		return nil, false
+	}
+	if inner[0] == '\'' {
+		var names []string
+		for _, part := range strings.Split(inner, ",") {
+			part = strings.TrimSpace(part)
+			if len(part) < 2 || part[0] != '\'' || part[len(part)-1] != '\'' {
+				return nil, false
+			}
+			names = append(names, part[1:len(part)-1])
+		}
+		return byNames(objs, names...), true
+	}
+	if strings.Contains(inner, ":") {
+		parts := strings.Split(inner, ":")
+		if len(parts) != 2 {
+			return nil, false
+		}
+		return slice(objs, strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	var indices []int
+	for _, part := range strings.Split(inner, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, false
+		}
+		indices = append(indices, n)
+	}
+	var res []interface{}
+	for _, obj := range objs {
+		arr, ok := obj.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, n := range indices {
+			if n < 0 {
+				n += len(arr)
+			}
+			if n >= 0 && n < len(arr) {
+				res = append(res, arr[n])
+			}
+		}
+	}
+	return res, true
+}
+
+func slice(objs []interface{}, from, to string) ([]interface{}, bool) {
+	var (
+		start, end int
+		err        error
+	)
+	if from != "" {
+		if start, err = strconv.Atoi(from); err != nil {
+			return nil, false
+		}
+	}
+	if to != "" {
+		if end, err = strconv.Atoi(to); err != nil {
+			return nil, false
+		}
+	}
+	var res []interface{}
+	for _, obj := range objs {
+		arr, ok := obj.([]interface{})
+		if !ok {
+			continue
+		}
+		s, e := normalizeIndex(start, len(arr)), len(arr)
+		if to != "" {
+			e = normalizeIndex(end, len(arr))
+		}
+		if s < e {
+			res = append(res, arr[s:e]...)
+		}
+	}
+	return res, true
+}
+
+func normalizeIndex(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func childrenOf(res []interface{}, obj interface{}) []interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(v) {
+			res = append(res, v[k])
+		}
+	case []interface{}:
+		res = append(res, v...)
+	}
+	return res
+}
+
+func children(objs []interface{}) []interface{} {
+	var res []interface{}
+	for _, obj := range objs {
+		res = childrenOf(res, obj)
+	}
+	return res
+}
+
+func byNames(objs []interface{}, names ...string) []interface{} {
+	var res []interface{}
+	for _, obj := range objs {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			if v, ok := m[name]; ok {
+				res = append(res, v)
+			}
+		}
+	}
+	return res
+}
+
+func descendRecursive(res []interface{}, obj interface{}, name string, depth int) ([]interface{}, bool) {
+	if name == "*" {
+		res = childrenOf(res, obj)
+	} else {
+		res = append(res, byNames([]interface{}{obj}, name)...)
+	}
+	if len(res) > maxObjects {
+		return nil, false
+	}
+	if depth == 0 {
+		return res, true
+	}
+	var ok bool
+	for _, child := range childrenOf(nil, obj) {
+		res, ok = descendRecursive(res, child, name, depth-1)
+		if !ok {
+			return nil, false
+		}
+	}
+	return res, true
+}
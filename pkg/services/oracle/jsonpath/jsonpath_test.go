@@ -0,0 +1,50 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	data := `{"store":{"book":[{"title":"a","price":8},{"title":"b","price":12},{"title":"c","price":9}],"name":"shop"},"x":1}`
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &v))
+
+	testCases := []struct {
+		path   string
+		result string
+	}{
+		{"", data},
+		{"$", data},
+		{"$.x", `[1]`},
+		{"$.store.name", `["shop"]`},
+		{"$.store.book[0].title", `["a"]`},
+		{"$.store.book[-1].title", `["c"]`},
+		{"$.store.book[0,2].price", `[8,9]`},
+		{"$.store.book[1:].title", `["b","c"]`},
+		{"$.store.book[:2].title", `["a","b"]`},
+		{"$.store.book[*].price", `[8,12,9]`},
+		{"$['x','store']['name']", `["shop"]`},
+		{"$..title", `["a","b","c"]`},
+		{"$.store.*", `[[{"price":8,"title":"a"},{"price":12,"title":"b"},{"price":9,"title":"c"}],"shop"]`},
+		{"$.missing", `null`},
+	}
+	for _, tc := range testCases {
+		res, ok := Get(tc.path, v)
+		require.True(t, ok, tc.path)
+		if tc.path == "" || tc.path == "$" {
+			require.Equal(t, 1, len(res))
+			continue
+		}
+		b, err := json.Marshal(res)
+		require.NoError(t, err)
+		require.Equal(t, tc.result, string(b), tc.path)
+	}
+
+	for _, path := range []string{"x", "$.", "$[", "$[a]", "$['a]", "$[1:2:3]", "$.a b"} {
+		_, ok := Get(path, v)
+		require.False(t, ok, path)
+	}
+}
@@ -0,0 +1,120 @@
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+)
+
+const (
+	defaultRequestTimeout = 5 * time.Second
+	maxRedirections       = 2
+)
+
+// ErrRestrictedRedirect is returned when redirection to forbidden address occurs.
+var ErrRestrictedRedirect = errors.New("oracle request redirection error")
+
+// reservedCIDRs is a list of ip addresses for private networks.
+// https://tools.ietf.org/html/rfc6890
+var reservedCIDRs = []string{
+	// IPv4
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	// IPv6
+	"fc00::/7",
+}
+
+var privateNets = make([]net.IPNet, 0, len(reservedCIDRs))
+
+func init() {
+	for i := range reservedCIDRs {
+		_, ipNet, err := net.ParseCIDR(reservedCIDRs[i])
+		if err != nil {
+			panic(err)
+		}
+		privateNets = append(privateNets, *ipNet)
+	}
+}
+
+func isReserved(ip net.IP) bool {
+	if !ip.IsGlobalUnicast() {
+		return true
+	}
+	for i := range privateNets {
+		if privateNets[i].Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedHost checks host against configured allowlist, host matches
+// allowed entry if it's equal to it or is its subdomain.
+func isAllowedHost(allowed []string, host string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSuffix(a, "."))
+		if host == a || strings.HasSuffix(host, "."+a) {
+			return true
+		}
+	}
+	return false
+}
+
+func getDefaultClient(cfg config.OracleConfiguration) *http.Client {
+	d := &net.Dialer{}
+	if !cfg.AllowPrivateHost {
+		// Control is called after address resolution, so it checks the
+		// actual IP the connection is being established to.
+		d.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: failed to split address %s: %v", ErrRestrictedRedirect, address, err)
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: failed to parse IP address %s", ErrRestrictedRedirect, address)
+			}
+			if isReserved(ip) {
+				return fmt.Errorf("%w: IP is not global unicast", ErrRestrictedRedirect)
+			}
+			return nil
+		}
+	}
+	var client http.Client
+	client.Transport = &http.Transport{
+		DisableKeepAlives: true,
+		DialContext:       d.DialContext,
+	}
+	client.Timeout = cfg.RequestTimeout
+	if client.Timeout == 0 {
+		client.Timeout = defaultRequestTimeout
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirections {
+			return fmt.Errorf("%w: %d redirections are reached", ErrRestrictedRedirect, maxRedirections)
+		}
+		if len(via) > 0 && via[0].URL.Scheme == "https" && req.URL.Scheme != "https" {
+			lastHop := via[len(via)-1].URL
+			return fmt.Errorf("%w: redirected from secure URL %s to insecure URL %s", ErrRestrictedRedirect, lastHop, req.URL)
+		}
+		if !isAllowedHost(cfg.AllowedHosts, req.URL.Hostname()) {
+			return fmt.Errorf("%w: host %s is not allowed", ErrRestrictedRedirect, req.URL.Hostname())
+		}
+		return nil
+	}
+	return &client
+}
@@ -0,0 +1,371 @@
+package oracle
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/block"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native/noderoles"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type (
+	// Ledger is the interface to Blockchain sufficient for Oracle.
+	Ledger interface {
+		BlockHeight() uint32
+		Contracts() *native.Contracts
+		GetConfig() config.ProtocolConfiguration
+		GetDesignatedByRole(r noderoles.Role, index uint32) (keys.PublicKeys, uint32, error)
+		GetFeePerByte() uint64
+		GetGasPrice() *big.Int
+		GetNonce(addr common.Address) uint64
+		GetOracleRequests() (map[uint64]*state.OracleRequest, error)
+		SubscribeForBlocks(ch chan<- *block.Block)
+		UnsubscribeFromBlocks(ch chan<- *block.Block)
+	}
+
+	// Oracle represents oracle module capable of talking
+	// with the external world.
+	Oracle struct {
+		Config
+
+		chainID uint64
+
+		// accMtx protects current oracle node account and oracle nodes data.
+		accMtx       sync.RWMutex
+		acc          *wallet.Account
+		oracleNodes  keys.PublicKeys
+		oracleScript []byte
+		oracleAddr   common.Address
+
+		wallet *wallet.Wallet
+
+		respMtx   sync.Mutex
+		responses map[uint64]*incompleteTx
+
+		requestCh chan request
+		blockCh   chan *block.Block
+		close     chan struct{}
+	}
+
+	// Config contains oracle module parameters.
+	Config struct {
+		Log             *zap.Logger
+		MainCfg         config.OracleConfiguration
+		Client          HTTPClient
+		Chain           Ledger
+		ResponseHandler Broadcaster
+		OnTransaction   TxCallback
+	}
+
+	// HTTPClient is an interface capable of doing oracle requests.
+	HTTPClient interface {
+		Do(*http.Request) (*http.Response, error)
+	}
+
+	// Broadcaster broadcasts oracle responses.
+	Broadcaster interface {
+		SendResponse(priv *keys.PrivateKey, id uint64, txSig []byte)
+		Run()
+		Shutdown()
+	}
+
+	// TxCallback executes on new transactions when they are ready to be pooled.
+	TxCallback = func(tx *transaction.Transaction) error
+)
+
+const requestChanCapacity = 1024
+
+// New returns new oracle instance.
+func New(cfg Config) (*Oracle, error) {
+	o := &Oracle{
+		Config: cfg,
+
+		chainID:   cfg.Chain.GetConfig().ChainID,
+		responses: make(map[uint64]*incompleteTx),
+		requestCh: make(chan request, requestChanCapacity),
+		blockCh:   make(chan *block.Block),
+		close:     make(chan struct{}),
+	}
+
+	var err error
+	w := cfg.MainCfg.UnlockWallet
+	if o.wallet, err = wallet.NewWalletFromFile(w.Path); err != nil {
+		return nil, err
+	}
+
+	haveAccount := false
+	for _, acc := range o.wallet.Accounts {
+		if err := acc.Decrypt(w.Password, o.wallet.Scrypt); err == nil {
+			haveAccount = true
+			break
+		}
+	}
+	if !haveAccount {
+		return nil, errors.New("no wallet account could be unlocked")
+	}
+
+	if o.Client == nil {
+		o.Client = getDefaultClient(o.MainCfg)
+	}
+	if o.ResponseHandler == nil {
+		o.ResponseHandler = defaultResponseHandler{}
+	}
+	if o.OnTransaction == nil {
+		o.OnTransaction = func(*transaction.Transaction) error { return nil }
+	}
+	return o, nil
+}
+
+// Start runs the oracle service in a separate goroutine.
+func (o *Oracle) Start() {
+	o.Log.Info("starting oracle service")
+	workers := o.MainCfg.MaxConcurrentRequests
+	if workers <= 0 {
+		workers = defaultMaxConcurrentRequests
+	}
+	for i := 0; i < workers; i++ {
+		go o.runRequestWorker()
+	}
+	go o.ResponseHandler.Run()
+	o.Chain.SubscribeForBlocks(o.blockCh)
+	go o.run()
+}
+
+// Shutdown shutdowns Oracle.
+func (o *Oracle) Shutdown() {
+	o.Chain.UnsubscribeFromBlocks(o.blockCh)
+	close(o.close)
+	o.ResponseHandler.Shutdown()
+}
+
+func (o *Oracle) run() {
+	o.processRequests()
+	for {
+		select {
+		case <-o.close:
+			return
+		case <-o.blockCh:
+			o.processRequests()
+		}
+	}
+}
+
+// updateNodes updates oracle nodes list and current node account for the
+// next block.
+func (o *Oracle) updateNodes() error {
+	nodes, _, err := o.Chain.GetDesignatedByRole(noderoles.Oracle, o.Chain.BlockHeight()+1)
+	if err != nil {
+		return err
+	}
+	o.accMtx.Lock()
+	defer o.accMtx.Unlock()
+	if nodes.Len() == o.oracleNodes.Len() {
+		same := true
+		for i := range nodes {
+			if !nodes[i].Equal(o.oracleNodes[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return nil
+		}
+	}
+	o.oracleNodes = nodes
+	o.oracleScript = nil
+	o.oracleAddr = common.Address{}
+	o.acc = nil
+	if nodes.Len() == 0 {
+		return nil
+	}
+	o.oracleScript, err = nodes.CreateDefaultMultiSigRedeemScript()
+	if err != nil {
+		return err
+	}
+	o.oracleAddr = hash.Hash160(o.oracleScript)
+	for i := range nodes {
+		if acc := o.wallet.GetAccount(nodes[i].Address()); acc != nil {
+			err := acc.Decrypt(o.MainCfg.UnlockWallet.Password, o.wallet.Scrypt)
+			if err == nil {
+				o.acc = acc
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (o *Oracle) getAccount() *wallet.Account {
+	o.accMtx.RLock()
+	defer o.accMtx.RUnlock()
+	return o.acc
+}
+
+// processRequests syncs local state with pending requests from the chain,
+// schedules new requests for processing and (re)builds response transactions.
+func (o *Oracle) processRequests() {
+	if err := o.updateNodes(); err != nil {
+		o.Log.Error("can't get oracle nodes", zap.Error(err))
+		return
+	}
+	if o.getAccount() == nil {
+		return
+	}
+	reqs, err := o.Chain.GetOracleRequests()
+	if err != nil {
+		o.Log.Error("can't get oracle requests", zap.Error(err))
+		return
+	}
+	o.respMtx.Lock()
+	defer o.respMtx.Unlock()
+	for id := range o.responses {
+		if _, ok := reqs[id]; !ok {
+			delete(o.responses, id)
+		}
+	}
+	for id, req := range reqs {
+		itx, ok := o.responses[id]
+		if ok && itx.request != nil {
+			continue
+		}
+		select {
+		case o.requestCh <- request{ID: id, Req: req}:
+		default:
+			// Queue is full, request will be scheduled on the next block.
+			continue
+		}
+		if !ok {
+			itx = newIncompleteTx()
+			o.responses[id] = itx
+		}
+		itx.request = req
+	}
+	o.updateResponses(reqs)
+}
+
+// setResult saves fetched result and sends response.
+func (o *Oracle) setResult(id uint64, code state.OracleResponseCode, result []byte) {
+	reqs, err := o.Chain.GetOracleRequests()
+	if err != nil {
+		o.Log.Error("can't get oracle requests", zap.Error(err))
+		return
+	}
+	o.respMtx.Lock()
+	defer o.respMtx.Unlock()
+	itx, ok := o.responses[id]
+	if !ok {
+		return
+	}
+	itx.isDone = true
+	itx.code = code
+	itx.result = result
+	o.updateResponses(reqs)
+}
+
+// updateResponses (re)builds response transactions for all pending requests
+// with fetched results, signs and broadcasts them and sends complete ones.
+// Nonces are assigned sequentially to finished requests in the order of
+// request ids, so every oracle node creates the same transactions for the
+// same chain state and results. It must be called with respMtx held.
+func (o *Oracle) updateResponses(reqs map[uint64]*state.OracleRequest) {
+	acc := o.getAccount()
+	if acc == nil {
+		return
+	}
+	o.accMtx.RLock()
+	script := o.oracleScript
+	addr := o.oracleAddr
+	o.accMtx.RUnlock()
+
+	ids := make([]uint64, 0, len(reqs))
+	for id := range reqs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var (
+		nonce      = o.Chain.GetNonce(addr)
+		gasPrice   = o.Chain.GetGasPrice()
+		feePerByte = o.Chain.GetFeePerByte()
+		priv       = acc.PrivateKey()
+	)
+	created := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		itx, ok := o.responses[id]
+		if !ok || !itx.isDone {
+			continue
+		}
+		tx, err := o.createResponseTx(id, itx, nonce, gasPrice, feePerByte)
+		if err != nil {
+			o.Log.Error("can't create response transaction", zap.Uint64("id", id), zap.Error(err))
+			continue
+		}
+		nonce++
+		created = append(created, id)
+		if itx.tx == nil || itx.tx.Hash() != tx.Hash() {
+			itx.tx = tx
+			sig := priv.SignHashable(o.chainID, tx)
+			itx.addSignature(priv.PublicKey(), sig)
+			o.ResponseHandler.SendResponse(priv, id, sig)
+		}
+	}
+	// Transactions are sent in nonce order, the first failure means that
+	// all the subsequent ones can't be pooled too.
+	for _, id := range created {
+		tx, ok := o.responses[id].finalize(o.chainID, script)
+		if !ok {
+			break
+		}
+		if err := o.OnTransaction(tx); err != nil {
+			o.Log.Debug("can't send oracle response transaction", zap.Uint64("id", id), zap.Error(err))
+			break
+		}
+	}
+}
+
+// AddResponse processes oracle response signature from node pub.
+func (o *Oracle) AddResponse(pub *keys.PublicKey, id uint64, txSig []byte) {
+	o.accMtx.RLock()
+	isOracle := o.oracleNodes.Contains(pub)
+	o.accMtx.RUnlock()
+	if !isOracle {
+		o.Log.Debug("signature from unknown node", zap.String("pub", pub.String()))
+		return
+	}
+	reqs, err := o.Chain.GetOracleRequests()
+	if err != nil {
+		o.Log.Error("can't get oracle requests", zap.Error(err))
+		return
+	}
+	if _, ok := reqs[id]; !ok {
+		return
+	}
+	o.respMtx.Lock()
+	defer o.respMtx.Unlock()
+	itx, ok := o.responses[id]
+	if !ok {
+		itx = newIncompleteTx()
+		o.responses[id] = itx
+	}
+	itx.addSignature(pub, txSig)
+	o.updateResponses(reqs)
+}
+
+type defaultResponseHandler struct{}
+
+func (defaultResponseHandler) SendResponse(*keys.PrivateKey, uint64, []byte) {}
+
+func (defaultResponseHandler) Run() {}
+
+func (defaultResponseHandler) Shutdown() {}
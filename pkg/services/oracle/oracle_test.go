@@ -0,0 +1,127 @@
+package oracle
+
+import (
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/hash"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProcessRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"a":{"b":1},"c":"d"}`))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html></html>`))
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	o := &Oracle{Config: Config{
+		Log:     zap.NewNop(),
+		Client:  srv.Client(),
+		MainCfg: config.OracleConfiguration{MaxResponseSize: 64},
+	}}
+	check := func(t *testing.T, url, filter string, code state.OracleResponseCode, result string) {
+		c, res := o.processRequest(&state.OracleRequest{URL: url, Filter: filter})
+		require.Equal(t, code, c)
+		require.Equal(t, result, string(res))
+	}
+	t.Run("success", func(t *testing.T) {
+		check(t, srv.URL+"/json", "", state.Success, `{"a":{"b":1},"c":"d"}`)
+	})
+	t.Run("filter", func(t *testing.T) {
+		check(t, srv.URL+"/json", "$.a.b", state.Success, `[1]`)
+		check(t, srv.URL+"/json", "$..*", state.Success, `[{"b":1},"d",1]`)
+		check(t, srv.URL+"/json", "a.b", state.Error, "")
+	})
+	t.Run("codes", func(t *testing.T) {
+		check(t, srv.URL+"/html", "", state.ContentTypeNotSupported, "")
+		check(t, srv.URL+"/large", "", state.ResponseTooLarge, "")
+		check(t, srv.URL+"/forbidden", "", state.Forbidden, "")
+		check(t, srv.URL+"/missing", "", state.NotFound, "")
+		check(t, "ftp://example.com", "", state.ProtocolNotSupported, "")
+		check(t, "not a url", "", state.Forbidden, "")
+	})
+	t.Run("allowed hosts", func(t *testing.T) {
+		o.MainCfg.AllowedHosts = []string{"example.com"}
+		defer func() { o.MainCfg.AllowedHosts = nil }()
+		check(t, srv.URL+"/json", "", state.Forbidden, "")
+		require.True(t, isAllowedHost(o.MainCfg.AllowedHosts, "api.example.com"))
+		require.False(t, isAllowedHost(o.MainCfg.AllowedHosts, "badexample.com"))
+	})
+}
+
+func TestIsReserved(t *testing.T) {
+	require.True(t, isReserved(net.ParseIP("127.0.0.1")))
+	require.True(t, isReserved(net.ParseIP("192.168.0.1")))
+	require.True(t, isReserved(net.ParseIP("10.1.2.3")))
+	require.True(t, isReserved(net.ParseIP("fc00::1")))
+	require.False(t, isReserved(net.ParseIP("8.8.8.8")))
+}
+
+func TestIncompleteTxFinalize(t *testing.T) {
+	const chainID = 42
+	privs := make([]*keys.PrivateKey, 4)
+	pubs := make(keys.PublicKeys, 4)
+	for i := range privs {
+		var err error
+		privs[i], err = keys.NewPrivateKey()
+		require.NoError(t, err)
+		pubs[i] = privs[i].PublicKey()
+	}
+	script, err := pubs.CreateDefaultMultiSigRedeemScript()
+	require.NoError(t, err)
+
+	to := native.OracleAddress
+	itx := newIncompleteTx()
+	itx.tx = &transaction.NeoTx{
+		Nonce:    1,
+		GasPrice: big.NewInt(1),
+		Gas:      100000,
+		From:     hash.Hash160(script),
+		To:       &to,
+		Value:    big.NewInt(0),
+		Data:     []byte{1, 2, 3},
+		Witness:  transaction.Witness{VerificationScript: script},
+	}
+	_, ok := itx.finalize(chainID, script)
+	assert.False(t, ok)
+
+	itx.addSignature(privs[0].PublicKey(), privs[0].SignHashable(chainID, itx.tx))
+	// Signature for the other network is ignored.
+	itx.addSignature(privs[1].PublicKey(), privs[1].SignHashable(chainID+1, itx.tx))
+	_, ok = itx.finalize(chainID, script)
+	assert.False(t, ok)
+
+	itx.addSignature(privs[2].PublicKey(), privs[2].SignHashable(chainID, itx.tx))
+	_, ok = itx.finalize(chainID, script)
+	assert.False(t, ok)
+
+	itx.addSignature(privs[3].PublicKey(), privs[3].SignHashable(chainID, itx.tx))
+	tx, ok := itx.finalize(chainID, script)
+	require.True(t, ok)
+	assert.Equal(t, itx.tx.Hash(), tx.Hash())
+	require.NoError(t, tx.Verify(chainID))
+}
@@ -0,0 +1,129 @@
+package oracle
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxConcurrentRequests = 10
+	defaultMaxResponseSize       = native.MaxOracleResultSize
+	userAgent                    = "NeoGoEvmOracle/1.0"
+)
+
+type request struct {
+	ID  uint64
+	Req *state.OracleRequest
+}
+
+func (o *Oracle) runRequestWorker() {
+	for {
+		select {
+		case <-o.close:
+			return
+		case req := <-o.requestCh:
+			code, result := o.processRequest(req.Req)
+			o.Log.Debug("oracle request processed",
+				zap.Uint64("id", req.ID),
+				zap.String("url", req.Req.URL),
+				zap.Uint8("code", uint8(code)))
+			o.setResult(req.ID, code, result)
+		}
+	}
+}
+
+// processRequest fetches data for the request and returns response code
+// along with (filtered) result.
+func (o *Oracle) processRequest(req *state.OracleRequest) (state.OracleResponseCode, []byte) {
+	u, err := url.ParseRequestURI(req.URL)
+	if err != nil {
+		return state.Forbidden, nil
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return state.ProtocolNotSupported, nil
+	}
+	if !isAllowedHost(o.MainCfg.AllowedHosts, u.Hostname()) {
+		return state.Forbidden, nil
+	}
+	httpReq, err := http.NewRequest("GET", req.URL, nil)
+	if err != nil {
+		return state.Forbidden, nil
+	}
+	httpReq.Header.Set("User-Agent", userAgent)
+	httpReq.Header.Set("Content-Type", "application/json")
+	r, err := o.Client.Do(httpReq)
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, ErrRestrictedRedirect):
+			return state.Forbidden, nil
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return state.Timeout, nil
+		default:
+			o.Log.Warn("oracle request failed", zap.String("url", req.URL), zap.Error(err))
+			return state.Error, nil
+		}
+	}
+	defer r.Body.Close()
+	switch r.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return state.Forbidden, nil
+	case http.StatusNotFound:
+		return state.NotFound, nil
+	case http.StatusRequestTimeout:
+		return state.Timeout, nil
+	default:
+		return state.Error, nil
+	}
+	if !checkMediaType(r.Header.Get("Content-Type")) {
+		return state.ContentTypeNotSupported, nil
+	}
+	maxSize := o.MainCfg.MaxResponseSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxResponseSize
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return state.Timeout, nil
+		}
+		return state.Error, nil
+	}
+	if int64(len(data)) > maxSize {
+		return state.ResponseTooLarge, nil
+	}
+	result, err := filterRequest(data, req)
+	if err != nil {
+		o.Log.Warn("oracle result filtering failed", zap.String("url", req.URL), zap.Error(err))
+		return state.Error, nil
+	}
+	if len(result) > native.MaxOracleResultSize {
+		return state.ResponseTooLarge, nil
+	}
+	return state.Success, result
+}
+
+// checkMediaType accepts JSON and plain text responses (or responses
+// without content type).
+func checkMediaType(hdr string) bool {
+	if hdr == "" {
+		return true
+	}
+	typ, _, err := mime.ParseMediaType(hdr)
+	if err != nil {
+		return false
+	}
+	return typ == "application/json" || typ == "text/plain"
+}
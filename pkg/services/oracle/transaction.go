@@ -0,0 +1,91 @@
+package oracle
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/native"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/state"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+)
+
+// incompleteTx is a response transaction for a single request which is
+// waiting for signatures of other oracle nodes.
+type incompleteTx struct {
+	request *state.OracleRequest
+	// isDone is set when the result is fetched.
+	isDone bool
+	code   state.OracleResponseCode
+	result []byte
+	// tx is a response transaction built for current chain state.
+	tx *transaction.NeoTx
+	// sigs contains signatures of oracle nodes by hex-encoded public key.
+	sigs map[string][]byte
+}
+
+func newIncompleteTx() *incompleteTx {
+	return &incompleteTx{
+		sigs: make(map[string][]byte),
+	}
+}
+
+// createResponseTx builds response transaction. It's deterministic for the
+// same chain state, so every oracle node creates the same one.
+func (o *Oracle) createResponseTx(id uint64, itx *incompleteTx, nonce uint64, gasPrice *big.Int, feePerByte uint64) (*transaction.NeoTx, error) {
+	data, err := o.Chain.Contracts().Oracle.Abi.Pack("finish", id, uint8(itx.code), itx.result)
+	if err != nil {
+		return nil, err
+	}
+	to := native.OracleAddress
+	tx := &transaction.NeoTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		From:     o.oracleAddr,
+		To:       &to,
+		Value:    big.NewInt(0),
+		Data:     data,
+		Witness: transaction.Witness{
+			VerificationScript: o.oracleScript,
+		},
+	}
+	netFee := transaction.CalculateNetworkFee(transaction.NewTx(tx), feePerByte)
+	tx.Gas = netFee + native.OracleResponseGas(itx.request.GasForResponse)
+	return tx, nil
+}
+
+// finalize returns transaction with complete witness if there are enough
+// valid signatures.
+func (t *incompleteTx) finalize(chainID uint64, script []byte) (*transaction.Transaction, bool) {
+	if t.tx == nil {
+		return nil, false
+	}
+	pks, m, err := crypto.ParseMultiVerificationScript(script)
+	if err != nil {
+		return nil, false
+	}
+	sigs := make([][]byte, 0, m)
+	for _, pk := range pks {
+		if len(sigs) == m {
+			break
+		}
+		sig, ok := t.sigs[hex.EncodeToString(pk.Bytes())]
+		if ok && pk.VerifyHashable(sig, chainID, t.tx) {
+			sigs = append(sigs, sig)
+		}
+	}
+	if len(sigs) < m {
+		return nil, false
+	}
+	tx := *t.tx
+	tx.Witness = transaction.Witness{
+		InvocationScript:   crypto.CreateMultiInvocationScript(sigs),
+		VerificationScript: script,
+	}
+	return transaction.NewTx(&tx), true
+}
+
+func (t *incompleteTx) addSignature(pub *keys.PublicKey, sig []byte) {
+	t.sigs[hex.EncodeToString(pub.Bytes())] = sig
+}
@@ -0,0 +1,298 @@
+package txjournal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	gio "io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/io"
+	"go.uber.org/zap"
+)
+
+type (
+	// Ledger is the interface to Blockchain sufficient for Journal.
+	Ledger interface {
+		GetMemPool() *mempool.Pool
+		PoolTx(t *transaction.Transaction, pools ...*mempool.Pool) error
+	}
+
+	// Journal keeps transactions accepted into the mempool on disk, so that
+	// they can be restored after node restart.
+	Journal struct {
+		log   *zap.Logger
+		cfg   config.MempoolJournal
+		chain Ledger
+
+		// lock protects the journal file.
+		lock   sync.Mutex
+		file   *os.File
+		writer *bufio.Writer
+		// pending is the number of transactions written but not flushed yet.
+		pending int
+
+		txs  chan *transaction.Transaction
+		done chan struct{}
+		wg   sync.WaitGroup
+	}
+)
+
+const (
+	defaultRewriteInterval = time.Hour
+	// queueSize is the number of accepted transactions waiting to be written.
+	queueSize = 4096
+	// flushInterval is the maximum time written transactions can stay in the
+	// write buffer.
+	flushInterval = time.Second
+	// flushBatch is the number of written transactions triggering flush.
+	flushBatch = 256
+)
+
+// New returns new mempool journal instance, it returns nil if journal is
+// disabled.
+func New(cfg config.MempoolJournal, chain Ledger, log *zap.Logger) (*Journal, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Path == "" {
+		return nil, errors.New("empty mempool journal path")
+	}
+	if cfg.RewriteInterval <= 0 {
+		cfg.RewriteInterval = defaultRewriteInterval
+	}
+	return &Journal{
+		log:   log,
+		cfg:   cfg,
+		chain: chain,
+		txs:   make(chan *transaction.Transaction, queueSize),
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// Start replays the journal into the mempool, compacts it and starts
+// recording newly accepted transactions.
+func (j *Journal) Start() {
+	j.log.Info("starting mempool journal", zap.String("path", j.cfg.Path))
+	loaded, dropped, err := j.load()
+	if err != nil {
+		j.log.Warn("failed to load mempool journal", zap.Error(err))
+	}
+	j.log.Info("mempool journal loaded", zap.Int("loaded", loaded), zap.Int("dropped", dropped))
+	if err := j.rotate(); err != nil {
+		j.log.Error("failed to rotate mempool journal", zap.Error(err))
+		return
+	}
+	j.chain.GetMemPool().SetAcceptCallback(j.insert)
+	j.wg.Add(1)
+	go j.run()
+}
+
+// Shutdown stops recording transactions and closes the journal.
+func (j *Journal) Shutdown() {
+	j.chain.GetMemPool().SetAcceptCallback(nil)
+	close(j.done)
+	j.wg.Wait()
+	if err := j.rotate(); err != nil {
+		j.log.Warn("failed to rotate mempool journal", zap.Error(err))
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.closeFile()
+}
+
+func (j *Journal) run() {
+	defer j.wg.Done()
+	ticker := time.NewTicker(j.cfg.RewriteInterval)
+	defer ticker.Stop()
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	for {
+		select {
+		case <-j.done:
+			j.drain()
+			return
+		case tx := <-j.txs:
+			j.write(tx)
+		case <-flushTicker.C:
+			j.flush()
+		case <-ticker.C:
+			if err := j.rotate(); err != nil {
+				j.log.Warn("failed to rotate mempool journal", zap.Error(err))
+			}
+		}
+	}
+}
+
+// load reads transactions from the journal and adds them to the mempool,
+// transactions that are not valid anymore are dropped.
+func (j *Journal) load() (int, int, error) {
+	f, err := os.Open(j.cfg.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	var (
+		loaded  int
+		dropped int
+		r       = io.NewBinReaderFromIO(bufio.NewReader(f))
+	)
+	for {
+		b := r.ReadVarBytes(transaction.MaxTransactionSize)
+		if r.Err != nil {
+			if errors.Is(r.Err, gio.EOF) {
+				return loaded, dropped, nil
+			}
+			return loaded, dropped, r.Err
+		}
+		tx, err := transaction.NewTransactionFromBytes(b)
+		if err != nil {
+			return loaded, dropped, fmt.Errorf("invalid transaction: %w", err)
+		}
+		if err := j.chain.PoolTx(tx); err != nil {
+			j.log.Debug("dropping journaled transaction", zap.Stringer("hash", tx.Hash()), zap.Error(err))
+			dropped++
+			continue
+		}
+		loaded++
+	}
+}
+
+// insert queues transaction to be appended to the journal. It's called under
+// the mempool lock, so it never blocks; if the queue is full the transaction
+// is only saved by the next journal rewrite.
+func (j *Journal) insert(tx *transaction.Transaction) {
+	select {
+	case j.txs <- tx:
+	default:
+		j.log.Debug("mempool journal queue is full", zap.Stringer("hash", tx.Hash()))
+	}
+}
+
+// write appends transaction to the journal, flushing it every flushBatch
+// transactions.
+func (j *Journal) write(tx *transaction.Transaction) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.writer == nil {
+		return
+	}
+	if err := writeTx(j.writer, tx); err != nil {
+		j.log.Warn("failed to journal transaction", zap.Stringer("hash", tx.Hash()), zap.Error(err))
+		return
+	}
+	j.pending++
+	if j.pending >= flushBatch {
+		j.flushLocked()
+	}
+}
+
+// drain writes all queued transactions and flushes the journal.
+func (j *Journal) drain() {
+	for {
+		select {
+		case tx := <-j.txs:
+			j.write(tx)
+		default:
+			j.flush()
+			return
+		}
+	}
+}
+
+func (j *Journal) flush() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.flushLocked()
+}
+
+func (j *Journal) flushLocked() {
+	if j.writer == nil || j.pending == 0 {
+		return
+	}
+	j.pending = 0
+	if err := j.writer.Flush(); err != nil {
+		j.log.Warn("failed to flush mempool journal", zap.Error(err))
+	}
+}
+
+// rotate rewrites the journal with the current mempool contents.
+func (j *Journal) rotate() error {
+	mp := j.chain.GetMemPool()
+	txes := append(mp.GetVerifiedTransactions(), mp.GetQueuedTransactions()...)
+	// Lower nonces first so that every transaction can be added to the pool
+	// without being queued on replay.
+	sort.SliceStable(txes, func(i, k int) bool { return txes[i].Nonce() < txes[k].Nonce() })
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if err := os.MkdirAll(filepath.Dir(j.cfg.Path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := j.cfg.Path + ".new"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, tx := range txes {
+		if err := writeTx(w, tx); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	j.closeFile()
+	if err := os.Rename(tmp, j.cfg.Path); err != nil {
+		return err
+	}
+	f, err = os.OpenFile(j.cfg.Path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	j.file = f
+	j.writer = bufio.NewWriter(f)
+	return nil
+}
+
+func (j *Journal) closeFile() {
+	if j.file == nil {
+		return
+	}
+	if err := j.writer.Flush(); err != nil {
+		j.log.Warn("failed to flush mempool journal", zap.Error(err))
+	}
+	if err := j.file.Close(); err != nil {
+		j.log.Warn("failed to close mempool journal", zap.Error(err))
+	}
+	j.file = nil
+	j.writer = nil
+	j.pending = 0
+}
+
+func writeTx(w gio.Writer, tx *transaction.Transaction) error {
+	b, err := tx.Bytes()
+	if err != nil {
+		return err
+	}
+	bw := io.NewBinWriterFromIO(w)
+	bw.WriteVarBytes(b)
+	return bw.Err
+}
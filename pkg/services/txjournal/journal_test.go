@@ -0,0 +1,164 @@
+package txjournal
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/config"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/mempool"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/core/transaction"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/crypto/keys"
+	"github.com/DigitalLabs-web3/neo-go-evm/pkg/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testChainID = 53
+
+type testLedger struct {
+	pool    *mempool.Pool
+	invalid map[common.Hash]bool
+}
+
+func newTestLedger() *testLedger {
+	return &testLedger{
+		pool:    mempool.New(10, 0, false),
+		invalid: make(map[common.Hash]bool),
+	}
+}
+
+func (l *testLedger) GetMemPool() *mempool.Pool { return l.pool }
+
+func (l *testLedger) PoolTx(t *transaction.Transaction, _ ...*mempool.Pool) error {
+	if err := t.Verify(testChainID); err != nil {
+		return err
+	}
+	if l.invalid[t.Hash()] {
+		return errors.New("invalid")
+	}
+	return l.pool.Add(t, l)
+}
+
+func (l *testLedger) FeePerByte() uint64                             { return 0 }
+func (l *testLedger) GetGasPrice() *big.Int                          { return big.NewInt(1) }
+func (l *testLedger) BlockHeight() uint32                            { return 0 }
+func (l *testLedger) GetUtilityTokenBalance(common.Address) *big.Int { return big.NewInt(1000000000) }
+
+func newTestTx(t *testing.T, acc *wallet.Account, nonce uint64) *transaction.Transaction {
+	tx := transaction.NewTx(&transaction.EthTx{
+		Transaction: *types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(1),
+			Gas:      21000,
+			To:       &common.Address{},
+			Value:    big.NewInt(0),
+		}),
+	})
+	require.NoError(t, acc.SignTx(testChainID, tx))
+	require.NoError(t, tx.Verify(testChainID))
+	return tx
+}
+
+func TestJournal(t *testing.T) {
+	cfg := config.MempoolJournal{
+		Enabled:         true,
+		Path:            filepath.Join(t.TempDir(), "journal"),
+		RewriteInterval: time.Hour,
+	}
+	pk, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	acc := wallet.NewAccountFromPrivateKey(pk)
+
+	l := newTestLedger()
+	j, err := New(cfg, l, zap.NewNop())
+	require.NoError(t, err)
+	j.Start()
+	tx0, tx1, tx2 := newTestTx(t, acc, 0), newTestTx(t, acc, 1), newTestTx(t, acc, 2)
+	require.NoError(t, l.PoolTx(tx0))
+	require.NoError(t, l.PoolTx(tx1))
+	require.NoError(t, l.PoolTx(tx2))
+	require.Equal(t, 3, l.pool.Count())
+
+	// accepted transactions are appended to the journal in background
+	require.Eventually(t, func() bool {
+		jl, err := New(cfg, newTestLedger(), zap.NewNop())
+		require.NoError(t, err)
+		loaded, dropped, err := jl.load()
+		require.NoError(t, err)
+		require.Equal(t, 0, dropped)
+		return loaded == 3
+	}, 2*flushInterval+time.Second, 100*time.Millisecond)
+	j.Shutdown()
+
+	// node restarts, one of transactions is not valid anymore
+	l = newTestLedger()
+	l.invalid[tx2.Hash()] = true
+	j, err = New(cfg, l, zap.NewNop())
+	require.NoError(t, err)
+	loaded, dropped, err := j.load()
+	require.NoError(t, err)
+	require.Equal(t, 2, loaded)
+	require.Equal(t, 1, dropped)
+	require.True(t, l.pool.ContainsKey(tx0.Hash()))
+	require.True(t, l.pool.ContainsKey(tx1.Hash()))
+
+	// journal is compacted to the current pool contents
+	require.NoError(t, j.rotate())
+	l = newTestLedger()
+	j, err = New(cfg, l, zap.NewNop())
+	require.NoError(t, err)
+	loaded, dropped, err = j.load()
+	require.NoError(t, err)
+	require.Equal(t, 2, loaded)
+	require.Equal(t, 0, dropped)
+}
+
+func TestJournalQueue(t *testing.T) {
+	cfg := config.MempoolJournal{
+		Enabled:         true,
+		Path:            filepath.Join(t.TempDir(), "journal"),
+		RewriteInterval: time.Hour,
+	}
+	pk, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	acc := wallet.NewAccountFromPrivateKey(pk)
+
+	l := newTestLedger()
+	j, err := New(cfg, l, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, j.rotate())
+
+	// insert doesn't block when the journal goroutine is not running
+	tx := newTestTx(t, acc, 0)
+	for i := 0; i < queueSize+1; i++ {
+		j.insert(tx)
+	}
+	require.Equal(t, queueSize, len(j.txs))
+
+	// queued transactions are written and flushed on drain
+	j.drain()
+	require.Equal(t, 0, len(j.txs))
+	jl, err := New(cfg, newTestLedger(), zap.NewNop())
+	require.NoError(t, err)
+	loaded, dropped, err := jl.load()
+	require.NoError(t, err)
+	require.Equal(t, 1, loaded)
+	require.Equal(t, queueSize-1, dropped)
+	j.lock.Lock()
+	j.closeFile()
+	j.lock.Unlock()
+}
+
+func TestJournalDisabled(t *testing.T) {
+	j, err := New(config.MempoolJournal{}, newTestLedger(), zap.NewNop())
+	require.NoError(t, err)
+	require.Nil(t, j)
+
+	_, err = New(config.MempoolJournal{Enabled: true}, newTestLedger(), zap.NewNop())
+	require.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels is a set of logging levels that can be changed at runtime. Every
+// module (named logger, see zap.Logger.Named) can have its own level, loggers
+// without it use the level of the closest parent module or the default one.
+type Levels struct {
+	def zap.AtomicLevel
+
+	lock    sync.RWMutex
+	modules map[string]zap.AtomicLevel
+}
+
+// levelCore is a zapcore.Core filtering entries according to Levels.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+// NewLevels returns new Levels with the given default level.
+func NewLevels(def zapcore.Level) *Levels {
+	return &Levels{
+		def:     zap.NewAtomicLevelAt(def),
+		modules: make(map[string]zap.AtomicLevel),
+	}
+}
+
+// WrapCore wraps the given core to filter entries according to Levels, it's
+// intended to be used with zap.WrapCore option.
+func (l *Levels) WrapCore(c zapcore.Core) zapcore.Core {
+	return &levelCore{Core: c, levels: l}
+}
+
+// SetLevel sets the level for the given module, empty module sets the
+// default level.
+func (l *Levels) SetLevel(module string, lvl zapcore.Level) {
+	if module == "" {
+		l.def.SetLevel(lvl)
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if al, ok := l.modules[module]; ok {
+		al.SetLevel(lvl)
+		return
+	}
+	l.modules[module] = zap.NewAtomicLevelAt(lvl)
+}
+
+// Reset removes the level of the given module, it then uses the level of the
+// parent module or the default one.
+func (l *Levels) Reset(module string) {
+	l.lock.Lock()
+	delete(l.modules, module)
+	l.lock.Unlock()
+}
+
+// List returns the default level (with an empty module name) and the levels
+// of all modules that have it set.
+func (l *Levels) List() map[string]zapcore.Level {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	res := make(map[string]zapcore.Level, len(l.modules)+1)
+	res[""] = l.def.Level()
+	for m, al := range l.modules {
+		res[m] = al.Level()
+	}
+	return res
+}
+
+// Level returns the effective level of the given module.
+func (l *Levels) Level(module string) zapcore.Level {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	for module != "" {
+		if al, ok := l.modules[module]; ok {
+			return al.Level()
+		}
+		i := strings.LastIndexByte(module, '.')
+		if i < 0 {
+			break
+		}
+		module = module[:i]
+	}
+	return l.def.Level()
+}
+
+// Enabled implements zapcore.LevelEnabler, it returns true if the level is
+// enabled for any module.
+func (l *Levels) Enabled(lvl zapcore.Level) bool {
+	if l.def.Enabled(lvl) {
+		return true
+	}
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	for _, al := range l.modules {
+		if al.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled implements zapcore.Core interface.
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.Enabled(lvl)
+}
+
+// With implements zapcore.Core interface.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+// Check implements zapcore.Core interface.
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.Level(ent.LoggerName) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevels(t *testing.T) {
+	levels := NewLevels(zapcore.InfoLevel)
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(core, zap.WrapCore(levels.WrapCore))
+	netLog := log.Named("network")
+	rpcLog := log.Named("rpc")
+
+	netLog.Debug("net1")
+	rpcLog.Debug("rpc1")
+	require.Equal(t, 0, logs.Len())
+
+	levels.SetLevel("network", zapcore.DebugLevel)
+	netLog.Debug("net2")
+	netLog.Named("peer").Debug("net3")
+	rpcLog.Debug("rpc2")
+	require.Equal(t, 2, logs.Len())
+	require.Equal(t, zapcore.DebugLevel, levels.Level("network.peer"))
+	require.Equal(t, zapcore.InfoLevel, levels.Level("rpc"))
+
+	levels.SetLevel("", zapcore.ErrorLevel)
+	rpcLog.Warn("rpc3")
+	log.Warn("root")
+	require.Equal(t, 2, logs.Len())
+	require.Equal(t, map[string]zapcore.Level{
+		"":        zapcore.ErrorLevel,
+		"network": zapcore.DebugLevel,
+	}, levels.List())
+
+	levels.Reset("network")
+	netLog.Warn("net4")
+	netLog.Error("net5")
+	require.Equal(t, 3, logs.Len())
+	require.Equal(t, "net5", logs.All()[2].Message)
+}
@@ -39,6 +39,12 @@ type (
 	// GetHashFunc returns the n'th block hash in the blockchain
 	// and is used by the BLOCKHASH EVM op code.
 	GetHashFunc func(uint64) common.Hash
+	// DeployFunc is the signature of a contract deployment hook, it gets
+	// the deployer and the address of the new contract
+	DeployFunc func(StateDB, common.Address, common.Address) error
+	// CreatedFunc is the signature of a contract creation hook, it gets the
+	// deployer and the address of the created contract
+	CreatedFunc func(StateDB, common.Address, common.Address) error
 )
 
 func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
@@ -65,6 +71,16 @@ type BlockContext struct {
 	CanTransfer CanTransferFunc
 	// Transfer transfers ether from one account to the other
 	Transfer TransferFunc
+	// OnDeploy is called before contract creation, the creation is aborted
+	// if it returns an error, it's optional
+	OnDeploy DeployFunc
+	// OnCreated is called after successful contract creation, its state
+	// changes are reverted along with the creation, the creation is aborted
+	// if it returns an error, it's optional
+	OnCreated CreatedFunc
+	// MaxCodeSize is the maximum size of contract code, params.MaxCodeSize
+	// is used if it's 0
+	MaxCodeSize int
 	// GetHash returns the hash corresponding to n
 	GetHash GetHashFunc
 
@@ -426,6 +442,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
+	if evm.Context.OnDeploy != nil {
+		if err := evm.Context.OnDeploy(evm.StateDB, caller.Address(), address); err != nil {
+			return nil, common.Address{}, gas, err
+		}
+	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	if nonce+1 < nonce {
 		return nil, common.Address{}, gas, ErrNonceUintOverflow
@@ -467,7 +488,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	ret, err := evm.interpreter.Run(contract, nil, false)
 
 	// Check whether the max code size has been exceeded, assign err if the case.
-	if err == nil && evm.chainRules.IsEIP158 && len(ret) > params.MaxCodeSize {
+	maxCodeSize := params.MaxCodeSize
+	if evm.Context.MaxCodeSize != 0 {
+		maxCodeSize = evm.Context.MaxCodeSize
+	}
+	if err == nil && evm.chainRules.IsEIP158 && len(ret) > maxCodeSize {
 		err = ErrMaxCodeSizeExceeded
 	}
 
@@ -484,6 +509,9 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		createDataGas := uint64(len(ret)) * params.CreateDataGas
 		if contract.UseGas(createDataGas) {
 			evm.StateDB.SetCode(address, ret)
+			if evm.Context.OnCreated != nil {
+				err = evm.Context.OnCreated(evm.StateDB, caller.Address(), address)
+			}
 		} else {
 			err = ErrCodeStoreOutOfGas
 		}
@@ -0,0 +1,105 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnDeployContractCreate(t *testing.T) {
+	var (
+		sender  = common.BytesToAddress([]byte("sender"))
+		factory = common.BytesToAddress([]byte("factory"))
+		errDeny = errors.New("deployment is not allowed")
+	)
+	for name, code := range map[string]string{
+		// create(0, 0, 0), store the result and return it.
+		"CREATE": "600060006000f060005260206000f3",
+		// create2(0, 0, 0, 0), store the result and return it.
+		"CREATE2": "6000600060006000f560005260206000f3",
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, allowed := range []bool{false, true} {
+				statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+				statedb.CreateAccount(factory)
+				statedb.SetCode(factory, common.Hex2Bytes(code))
+				statedb.Finalise(true)
+
+				var deployers []common.Address
+				vmctx := BlockContext{
+					CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+					Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+					OnDeploy: func(_ StateDB, deployer common.Address, _ common.Address) error {
+						deployers = append(deployers, deployer)
+						if !allowed {
+							return errDeny
+						}
+						return nil
+					},
+					BlockNumber: big.NewInt(0),
+				}
+				evm := NewEVM(vmctx, TxContext{}, statedb, params.AllEthashProtocolChanges, Config{}, nil)
+				ret, _, err := evm.Call(AccountRef(sender), factory, nil, math.MaxUint64/2, new(big.Int))
+				require.NoError(t, err)
+				require.Equal(t, []common.Address{factory}, deployers)
+				created := common.BytesToAddress(ret)
+				if allowed {
+					require.NotEqual(t, common.Address{}, created)
+				} else {
+					require.Equal(t, common.Address{}, created)
+					require.Equal(t, uint64(0), statedb.GetNonce(factory))
+				}
+			}
+		})
+	}
+}
+
+func TestOnCreated(t *testing.T) {
+	sender := common.BytesToAddress([]byte("sender"))
+	for name, tc := range map[string]struct {
+		code    string
+		err     error
+		created bool
+	}{
+		// return(0, 0)
+		"success": {code: "60006000f3", created: true},
+		// revert(0, 0)
+		"revert": {code: "60006000fd"},
+		// Creation is aborted by the hook.
+		"hook error": {code: "60006000f3", err: errors.New("not allowed")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			var created []common.Address
+			vmctx := BlockContext{
+				CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+				Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+				OnCreated: func(_ StateDB, deployer common.Address, address common.Address) error {
+					require.Equal(t, sender, deployer)
+					created = append(created, address)
+					return tc.err
+				},
+				BlockNumber: big.NewInt(0),
+			}
+			evm := NewEVM(vmctx, TxContext{}, statedb, params.AllEthashProtocolChanges, Config{}, nil)
+			_, addr, _, err := evm.Create(AccountRef(sender), common.Hex2Bytes(tc.code), 100000, new(big.Int))
+			if tc.created {
+				require.NoError(t, err)
+				require.Equal(t, []common.Address{addr}, created)
+			} else if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Empty(t, statedb.GetCode(addr))
+			} else {
+				require.Error(t, err)
+				require.Empty(t, created)
+			}
+		})
+	}
+}